@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"innerworld-backend/internal/admin"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/graph"
+	"innerworld-backend/internal/secrets"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// defaultAdminAddr is used when ADMIN_ADDR isn't set, for the standalone
+// local-dev binary.
+const defaultAdminAddr = ":8081"
+
+func main() {
+	ctx := context.Background()
+
+	cfgManager, err := config.NewManager(ctx, secrets.NewEnvProvider())
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	cfgManager.WatchSIGHUP(ctx)
+	cfg := cfgManager.Current()
+
+	var s3Client graph.S3Client
+	if cfg.IsProduction() {
+		s3Client, err = graph.NewAWSS3Client(ctx, cfg.S3)
+		if err != nil {
+			log.Fatalf("Failed to initialize S3 client: %v", err)
+		}
+	} else {
+		s3Client = graph.NewMockS3Client()
+	}
+
+	mux := admin.NewMux(cfgManager, s3Client)
+
+	// In the Lambda execution environment, serve API Gateway's /admin/*
+	// route through the same mux; otherwise run a plain local HTTP server,
+	// so the two deployment modes share one implementation.
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return admin.ServeLambda(mux, request)
+		})
+		return
+	}
+
+	addr := os.Getenv("ADMIN_ADDR")
+	if addr == "" {
+		addr = defaultAdminAddr
+	}
+	log.Printf("Admin debug server listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
@@ -3,13 +3,20 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"innerworld-backend/internal/config"
-	// TODO: Uncomment when GraphRAG is implemented in Phase 2
-	// "innerworld-backend/internal/embeddings"
-	// "innerworld-backend/internal/graph"
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/graph"
 	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/personas"
+	"innerworld-backend/internal/retrieval"
+	"innerworld-backend/internal/storage"
+	"innerworld-backend/internal/types"
+	"innerworld-backend/internal/workflow"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -21,23 +28,41 @@ import (
 type ConversationRequest struct {
 	Message string `json:"message"`
 	UserID  string `json:"userId"`
+	Persona string `json:"persona"`
+	Stream  bool   `json:"stream"`
 }
 
-// ConversationResponse represents a basic conversation response
-type ConversationResponse struct {
-	MessageID string    `json:"messageId"`
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
-}
+// perUserCallsPerHour and perUserTokensPerHour bound how much LLM spend a
+// single user's conversations can rack up in an hour, so a retry storm or
+// compromised client can't run up an unbounded third-party bill.
+const (
+	perUserCallsPerHour  = 20
+	perUserTokensPerHour = 50000
+)
 
 // Global variables for connection reuse across invocations
 var (
 	cfg              *config.Config
 	openRouterClient *llm.OpenRouterClient
-	// TODO: Implement openAIClient for embeddings when GraphRAG is added
-	// openAIClient     *embeddings.OpenAIEmbeddingsClient
-	// TODO: Implement neptuneClient for graph operations when GraphRAG is added
-	// neptuneClient    graph.NeptuneClient
+	personaLoader    *personas.PersonaLoader
+	dynamoDB         storage.DynamoDBClient
+
+	// conversationChain reuses workflow.ConversationChain's prompt-starter
+	// generation (safety-checked, deduplicated, cached) rather than
+	// duplicating that logic here for GET /prompt-starters.
+	conversationChain *workflow.ConversationChain
+
+	// retrievalStrategy is nil unless both an embeddings provider and a
+	// Neptune client are configured, in which case handleConversationRequest
+	// injects GraphRAG context into the OpenRouter prompt instead of calling
+	// the persona's system prompt with no context at all.
+	retrievalStrategy retrieval.Strategy
+
+	// perPersonaRetrieval overrides retrievalStrategy for specific persona
+	// names, so retrieval tactics can vary per persona (e.g. a cheaper
+	// vector-only strategy for one persona, HybridRetriever's fuller
+	// vector+BM25+graph expansion for another).
+	perPersonaRetrieval map[string]retrieval.Strategy
 )
 
 // init runs once when Lambda container starts
@@ -58,17 +83,95 @@ func init() {
 		log.Println("OpenRouter API key not provided - will use mock responses")
 	}
 
-	// TODO: Initialize OpenAI client when GraphRAG is implemented
-	// if cfg.OpenAI.APIKey != "" && cfg.OpenAI.APIKey != "your-openai-api-key-here" {
-	//	openAIClient = embeddings.NewOpenAIEmbeddingsClient(cfg.OpenAI.APIKey)
-	//	log.Println("Initialized OpenAI embeddings client")
-	// } else {
-	//	log.Println("OpenAI API key not provided - embeddings disabled")
-	// }
+	personaLoader = personas.NewPersonaLoader()
+
+	if cfg.IsProduction() {
+		dynamoDB, err = storage.NewDynamoDBClient(context.Background(), cfg.DynamoDB)
+		if err != nil {
+			log.Fatalf("Failed to initialize DynamoDB client: %v", err)
+		}
+		log.Println("Initialized AWS DynamoDB client for production")
+	} else {
+		dynamoDB = storage.NewMockDynamoDBClient()
+		log.Println("Initialized Mock DynamoDB client for development")
+	}
+
+	conversationChain = workflow.NewConversationChain(personaLoader, openRouterClient, dynamoDB)
+	conversationChain.SetSafetyFilter(llm.NewSafetyFilter(llm.NewSpendBudget(perUserCallsPerHour, perUserTokensPerHour, time.Hour)))
+
+	perPersonaRetrieval = make(map[string]retrieval.Strategy)
+
+	if cfg.OpenAI.APIKey != "" && cfg.OpenAI.APIKey != "your-openai-api-key-here" {
+		embeddingsProvider, err := embeddings.NewProviderFromConfig(cfg.Embeddings, cfg.OpenAI.APIKey)
+		if err != nil {
+			log.Printf("Failed to initialize embeddings provider, GraphRAG retrieval disabled: %v", err)
+		} else {
+			neptuneClient := graph.NewMockNeptuneClient()
+			retrievalStrategy = retrieval.NewHybridRetriever(embeddingsProvider, neptuneClient)
+			log.Println("Initialized hybrid GraphRAG retrieval (vector + BM25 + graph expansion)")
+		}
+	} else {
+		log.Println("OpenAI API key not provided - GraphRAG retrieval disabled")
+	}
+}
+
+// retrieveContext runs persona's configured retrieval.Strategy (falling
+// back to the package-wide default) and returns the resulting context
+// snippets, or nil if no retrieval is configured or it fails - a failed
+// retrieval shouldn't block the conversation, just drop its context.
+func retrieveContext(ctx context.Context, persona, userID, message string) []string {
+	strategy := retrievalStrategy
+	if override, ok := perPersonaRetrieval[persona]; ok {
+		strategy = override
+	}
+	if strategy == nil {
+		return nil
+	}
 
-	// TODO: Initialize Neptune client when GraphRAG is implemented
-	// neptuneClient = graph.NewMockNeptuneClient()
-	log.Println("GraphRAG components (OpenAI/Neptune) disabled in Phase 1")
+	snippets, err := strategy.Retrieve(ctx, userID, message)
+	if err != nil {
+		log.Printf("GraphRAG retrieval failed, continuing without context: %v", err)
+		return nil
+	}
+	return snippets
+}
+
+// validationErrorResponse turns a *types.ValidationError into a 400 with a
+// JSON {code, field, message} body instead of an empty 400, so clients know
+// which field to fix.
+func validationErrorResponse(err error) events.APIGatewayProxyResponse {
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		return events.APIGatewayProxyResponse{StatusCode: 400}
+	}
+
+	body, marshalErr := json.Marshal(validationErr)
+	if marshalErr != nil {
+		log.Printf("Failed to marshal validation error: %v", marshalErr)
+		return events.APIGatewayProxyResponse{StatusCode: 400}
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 400,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}
+}
+
+// acceptsEventStream reports whether request's Accept header asks for SSE.
+// API Gateway doesn't guarantee header name casing (REST APIs preserve the
+// caller's casing; HTTP APIs lowercase it), so this checks both of the
+// casings actually seen in this handler's header maps elsewhere
+// (cmd/health-check, internal/admin/lambda.go) rather than assuming one.
+func acceptsEventStream(headers map[string]string) bool {
+	for _, key := range []string{"Accept", "accept"} {
+		if strings.Contains(headers[key], "text/event-stream") {
+			return true
+		}
+	}
+	return false
 }
 
 // handleConversationRequest processes basic conversation requests
@@ -80,31 +183,53 @@ func handleConversationRequest(ctx context.Context, request events.APIGatewayPro
 		return events.APIGatewayProxyResponse{StatusCode: 400}, nil
 	}
 
+	if err := (types.ConversationRequest{Message: conversationReq.Message, UserID: conversationReq.UserID}).Validate(); err != nil {
+		return validationErrorResponse(err), nil
+	}
+
 	log.Printf("Processing message from user %s: %s", conversationReq.UserID, conversationReq.Message)
 
-	// Generate response
-	var responseContent string
-	if openRouterClient != nil {
-		// Use OpenRouter to generate response
-		llmResponse, err := openRouterClient.GenerateResponse(ctx, conversationReq.Message)
-		if err != nil {
-			log.Printf("OpenRouter request failed: %v", err)
-			responseContent = "I'm sorry, I'm having trouble generating a response right now."
-		} else if len(llmResponse.Choices) > 0 {
-			responseContent = llmResponse.Choices[0].Message.Content
-		} else {
-			responseContent = "I didn't get a proper response. Could you try again?"
-		}
-	} else {
-		// Mock response when OpenRouter is not configured
-		responseContent = "Hello! I'm here to support you. (This is a mock response - OpenRouter not configured)"
+	persona := conversationReq.Persona
+	if persona == "" {
+		persona = "default"
+	}
+
+	contextSnippets := retrieveContext(ctx, persona, conversationReq.UserID, conversationReq.Message)
+
+	var userContext map[string]interface{}
+	if cached, err := dynamoDB.GetUserContext(ctx, conversationReq.UserID); err != nil {
+		log.Printf("Failed to load user context, continuing without it: %v", err)
+	} else if cached != nil {
+		userContext = cached.ContextData
+	}
+
+	conversationInput := &workflow.ConversationInput{
+		UserMessage:      conversationReq.Message,
+		Persona:          persona,
+		SessionID:        "http_" + uuid.New().String()[:8],
+		UserID:           conversationReq.UserID,
+		UserContext:      userContext,
+		SessionStart:     time.Now(),
+		RetrievedContext: contextSnippets,
+	}
+
+	if conversationReq.Stream || acceptsEventStream(request.Headers) {
+		return handleStreamingConversationRequest(ctx, conversationInput)
+	}
+
+	result, err := conversationChain.ProcessConversation(ctx, conversationInput)
+	if err != nil {
+		log.Printf("LangChain conversation processing failed: %v", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, nil
 	}
 
 	// Create response
-	response := ConversationResponse{
-		MessageID: uuid.New().String(),
-		Content:   responseContent,
-		Timestamp: time.Now(),
+	response := types.ConversationResponse{
+		MessageID: result.MessageID,
+		Content:   result.LLMResponse,
+		Timestamp: result.ProcessedAt,
+		Timings:   result.Timings.ToWire(),
+		LLMUsage:  result.LLMUsage.ToWire(),
 	}
 
 	// Return response
@@ -119,6 +244,59 @@ func handleConversationRequest(ctx context.Context, request events.APIGatewayPro
 	}, nil
 }
 
+// handleStreamingConversationRequest serves the chunked counterpart of
+// handleConversationRequest's buffered path, encoding each
+// types.ConversationChunk as an SSE "data: " event.
+//
+// This cmd's Lambda proxy integration returns one buffered
+// APIGatewayProxyResponse per invocation - the aws-lambda-go version this
+// repo currently vendors has no response-streaming invoke mode (the kind
+// that flushes a body incrementally to the client), so the tokens below are
+// collected from workflow.ConversationChain.StreamConversation's real
+// token-by-token stream and concatenated into a single SSE-formatted body
+// rather than flushed as they arrive. cmd/websocket-handler, backed by the
+// same StreamConversation call and API Gateway's WebSocket
+// PostToConnection, is this app's actually incremental delivery path today;
+// this endpoint trades that off for clients that only speak plain HTTP.
+func handleStreamingConversationRequest(ctx context.Context, input *workflow.ConversationInput) (events.APIGatewayProxyResponse, error) {
+	tokens := make(chan workflow.Token)
+	go func() {
+		if err := conversationChain.StreamConversation(ctx, input, tokens); err != nil {
+			log.Printf("LangChain streaming conversation processing failed: %v", err)
+		}
+	}()
+
+	var body strings.Builder
+	for token := range tokens {
+		writeSSEChunk(&body, types.ConversationChunk{
+			MessageID: token.MessageID,
+			Delta:     token.Content,
+			Done:      token.Done,
+			Timestamp: time.Now(),
+		})
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers: map[string]string{
+			"Content-Type": "text/event-stream",
+		},
+		Body: body.String(),
+	}, nil
+}
+
+// writeSSEChunk appends chunk to body as one SSE "data: " event.
+func writeSSEChunk(body *strings.Builder, chunk types.ConversationChunk) {
+	encoded, err := json.Marshal(chunk)
+	if err != nil {
+		log.Printf("Failed to marshal conversation chunk: %v", err)
+		return
+	}
+	body.WriteString("data: ")
+	body.Write(encoded)
+	body.WriteString("\n\n")
+}
+
 // handleDirectInvocation handles direct Lambda invocations for testing
 func handleDirectInvocation(ctx context.Context, payload json.RawMessage) (interface{}, error) {
 	var req ConversationRequest
@@ -136,7 +314,7 @@ func handleDirectInvocation(ctx context.Context, payload json.RawMessage) (inter
 		return nil, err
 	}
 
-	var result ConversationResponse
+	var result types.ConversationResponse
 	if err := json.Unmarshal([]byte(response.Body), &result); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
@@ -144,12 +322,74 @@ func handleDirectInvocation(ctx context.Context, payload json.RawMessage) (inter
 	return result, nil
 }
 
+// handlePromptStartersRequest serves GET /prompt-starters?persona=...&userId=...&limit=N,
+// returning N persona-aware opener suggestions for the requesting user so
+// the mobile client can render a "what should I say?" chip row when they
+// open the chat screen.
+func handlePromptStartersRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.QueryStringParameters["userId"]
+	if userID == "" {
+		return events.APIGatewayProxyResponse{StatusCode: 400, Body: `{"error":"userId is required"}`}, nil
+	}
+
+	persona := request.QueryStringParameters["persona"]
+	if persona == "" {
+		persona = "default"
+	}
+
+	limit := 0
+	if raw := request.QueryStringParameters["limit"]; raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return events.APIGatewayProxyResponse{StatusCode: 400, Body: `{"error":"limit must be an integer"}`}, nil
+		}
+		limit = parsed
+	}
+
+	userContext := make(map[string]interface{})
+	if cached, err := dynamoDB.GetUserContext(ctx, userID); err != nil {
+		log.Printf("Failed to load user context for prompt starters, continuing without it: %v", err)
+	} else if cached != nil {
+		userContext = cached.ContextData
+	}
+
+	starters, err := conversationChain.GetPromptStarters(ctx, userID, persona, userContext, limit)
+	if err != nil {
+		log.Printf("Failed to generate prompt starters: %v", err)
+		return events.APIGatewayProxyResponse{StatusCode: 400, Body: fmt.Sprintf(`{"error":%q}`, err.Error())}, nil
+	}
+
+	responseBody, err := json.Marshal(struct {
+		Starters []string `json:"starters"`
+	}{Starters: starters})
+	if err != nil {
+		log.Printf("Failed to marshal prompt starters response: %v", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(responseBody),
+	}, nil
+}
+
+// isPromptStartersRequest reports whether request targets the
+// /prompt-starters endpoint, mirroring cmd/health-check's
+// strings.HasSuffix route matching within a single Lambda proxy handler.
+func isPromptStartersRequest(request events.APIGatewayProxyRequest) bool {
+	return strings.HasSuffix(request.Path, "/prompt-starters")
+}
+
 func main() {
 	lambda.Start(func(ctx context.Context, event json.RawMessage) (interface{}, error) {
 		// Try to parse as API Gateway event first
 		var apiEvent events.APIGatewayProxyRequest
 		if err := json.Unmarshal(event, &apiEvent); err == nil && apiEvent.RequestContext.RequestID != "" {
 			// API Gateway invocation
+			if isPromptStartersRequest(apiEvent) {
+				return handlePromptStartersRequest(ctx, apiEvent)
+			}
 			return handleConversationRequest(ctx, apiEvent)
 		}
 
@@ -6,77 +6,169 @@ import (
 	"fmt"
 	"innerworld-backend/internal/config"
 	"innerworld-backend/internal/graph"
+	"innerworld-backend/internal/health"
+	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/logging"
+	"innerworld-backend/internal/metrics"
+	"innerworld-backend/internal/resilience"
+	"innerworld-backend/internal/secrets"
+	"innerworld-backend/internal/storage"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 )
 
+// defaultHealthAddr is used when HEALTH_ADDR isn't set, for the standalone
+// long-running local-dev/container server.
+const defaultHealthAddr = ":8082"
+
 // HealthCheckResponse represents the health check response
 type HealthCheckResponse struct {
 	Status    string                   `json:"status"`
 	Timestamp string                   `json:"timestamp"`
 	Version   string                   `json:"version"`
+	Deep      bool                     `json:"deep,omitempty"`
 	Services  map[string]ServiceHealth `json:"services"`
 	Debug     map[string]interface{}   `json:"debug,omitempty"`
 }
 
-// ServiceHealth represents the health status of a service
+// ServiceHealth represents the health status of a service. The breaker
+// fields are only populated by a deep check (?deep=true or /health/deep);
+// a shallow check leaves them zero.
 type ServiceHealth struct {
-	Status       string `json:"status"` // "healthy" | "unhealthy" | "degraded"
-	ResponseTime string `json:"responseTime,omitempty"`
-	Error        string `json:"error,omitempty"`
+	Status       string  `json:"status"` // "healthy" | "unhealthy" | "degraded" | "skipped"
+	ResponseTime string  `json:"responseTime,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	BreakerState string  `json:"breakerState,omitempty"`
+	LastSuccess  string  `json:"lastSuccess,omitempty"`
+	FailureRate  float64 `json:"failureRate,omitempty"`
 }
 
 // Global variables for reuse across invocations
 var (
-	cfg      *config.Config
-	s3Client graph.S3Client
+	cfgManager       *config.Manager
+	baseLogger       *slog.Logger
+	s3Client         graph.S3Client
+	dynamoDBClient   storage.DynamoDBClient
+	openRouterClient *llm.OpenRouterClient
+	openAIBackend    *llm.OpenAIBackend
+	deepProbes       []health.BreakerProbe
+	registry         *health.Registry
 )
 
 // init runs once when the Lambda function is initialized
 func init() {
-	var err error
+	ctx := context.Background()
 
-	// Load configuration
-	cfg, err = config.LoadConfig()
+	// Load configuration behind an atomic pointer, so a later Reload (picked
+	// up via WatchSIGHUP in non-Lambda binaries, or called explicitly) can
+	// swap in a rotated secret without any in-flight request seeing a
+	// half-updated Config.
+	var err error
+	cfgManager, err = config.NewManager(ctx, secrets.NewEnvProvider())
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
-
-	// Initialize S3 client (mock for local development)
-	if cfg.IsDevelopment() {
+	cfg := cfgManager.Current()
+	baseLogger = logging.New(cfg)
+
+	// Initialize S3 client (mock for local development, real AWS S3 in production)
+	if cfg.IsProduction() {
+		s3Client, err = graph.NewAWSS3Client(ctx, cfg.S3)
+		if err != nil {
+			log.Fatalf("Failed to initialize S3 client: %v", err)
+		}
+		log.Println("Initialized AWS S3 client for production")
+	} else {
 		s3Client = graph.NewMockS3Client()
 		log.Println("Initialized Mock S3 client for development")
+	}
+
+	// Initialize DynamoDB client (mock for local development, real AWS
+	// DynamoDB - or LocalStack, via cfg.DynamoDB.Endpoint - otherwise)
+	if cfg.IsProduction() {
+		dynamoDBClient, err = storage.NewDynamoDBClient(ctx, cfg.DynamoDB)
+		if err != nil {
+			log.Fatalf("Failed to initialize DynamoDB client: %v", err)
+		}
+		log.Println("Initialized AWS DynamoDB client for production")
 	} else {
-		// TODO: Initialize real S3 client when infrastructure is ready
-		s3Client = graph.NewMockS3Client()
-		log.Println("Using Mock S3 client (production S3 not yet configured)")
+		dynamoDBClient = storage.NewMockDynamoDBClient()
+		log.Println("Initialized Mock DynamoDB client for development")
+	}
+
+	// Build the deep-probe list, each dependency guarded by its own circuit
+	// breaker so repeated failures stop hammering it until ResetTimeout
+	// elapses. Probes use lightweight endpoints (S3 HeadBucket, OpenRouter
+	// /models, OpenAI /v1/models) rather than real generations, so a deep
+	// check never spends completion tokens.
+	breakerConfig := resilience.BreakerConfig{
+		MaxFailures:       cfg.Health.BreakerMaxFailures,
+		FailureWindow:     cfg.Health.BreakerFailureWindow,
+		ResetTimeout:      cfg.Health.BreakerResetTimeout,
+		MaxHalfOpenProbes: 1,
+		SuccessesToClose:  cfg.Health.BreakerSuccessesToClose,
 	}
+
+	openRouterClient = llm.NewOpenRouterClient(cfg.OpenRouter.APIKey)
+	openAIBackend = llm.NewOpenAIBackend(cfg.OpenAI.APIKey)
+
+	deepProbes = []health.BreakerProbe{
+		health.NewBreakerProbe(health.NewS3Prober(s3Client), breakerConfig),
+		health.NewBreakerProbe(health.NewDynamoDBProber(dynamoDBClient), breakerConfig),
+		health.NewBreakerProbe(health.NewOpenRouterProber(openRouterClient), breakerConfig),
+		health.NewBreakerProbe(health.NewOpenAIProber(openAIBackend), breakerConfig),
+	}
+
+	// registry backs the long-running /healthz, /readyz, /health server
+	// (see main): each prober gets its own background polling goroutine, so
+	// a request never blocks on a live AWS/DynamoDB/OpenRouter/OpenAI call.
+	registry = health.NewRegistry()
+	registry.Register(ctx, health.NewS3Prober(s3Client), cfg.Health.PollInterval)
+	registry.Register(ctx, health.NewDynamoDBProber(dynamoDBClient), cfg.Health.PollInterval)
+	registry.Register(ctx, health.NewOpenRouterProber(openRouterClient), cfg.Health.PollInterval)
+	registry.Register(ctx, health.NewOpenAIProber(openAIBackend), cfg.Health.PollInterval)
+
+	cfgManager.WatchSIGHUP(ctx)
+}
+
+// applyConfigRotation pushes the Manager's current API keys into the
+// long-lived OpenRouter/OpenAI clients that back deepProbes, so a reload
+// (e.g. from WatchSIGHUP) takes effect without rebuilding the breakers -
+// and the failure/success history they've accumulated - from scratch.
+func applyConfigRotation() {
+	cfg := cfgManager.Current()
+	openRouterClient.SetAPIKey(cfg.OpenRouter.APIKey)
+	openAIBackend.SetAPIKey(cfg.OpenAI.APIKey)
 }
 
 // handleHealthCheck processes health check requests
 func handleHealthCheck(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	startTime := time.Now()
 
-	log.Printf("Health check requested from: %s", request.Headers["User-Agent"])
+	ctx = logging.WithRequestAttrs(ctx, baseLogger, request.RequestContext.RequestID, "", "", "")
+	logger := logging.FromContext(ctx)
 
-	// Check all services
-	services := make(map[string]ServiceHealth)
+	logger.InfoContext(ctx, "health check requested", "user_agent", request.Headers["User-Agent"])
 
-	// Check S3 connectivity
-	s3Health := checkS3Health(ctx)
-	services["s3"] = s3Health
+	// Pick up any API key rotation the Manager has already reloaded before
+	// this request does any probing.
+	applyConfigRotation()
+	cfg := cfgManager.Current()
 
-	// Check OpenRouter (skip in health check to avoid API costs)
-	services["openrouter"] = ServiceHealth{
-		Status: "skipped",
-	}
+	deep := isDeepHealthCheck(request)
 
-	// Check OpenAI (skip in health check to avoid API costs)
-	services["openai"] = ServiceHealth{
-		Status: "skipped",
+	var services map[string]ServiceHealth
+	if deep {
+		services = runDeepHealthCheck(ctx)
+	} else {
+		services = runShallowHealthCheck(ctx)
 	}
 
 	// Determine overall status
@@ -95,6 +187,7 @@ func handleHealthCheck(ctx context.Context, request events.APIGatewayProxyReques
 		Status:    overallStatus,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Version:   "1.0.0", // TODO: Get from build info
+		Deep:      deep,
 		Services:  services,
 	}
 
@@ -132,8 +225,7 @@ func handleHealthCheck(ctx context.Context, request events.APIGatewayProxyReques
 		}, nil
 	}
 
-	log.Printf("Health check completed: %s (took %dms)",
-		overallStatus, time.Since(startTime).Milliseconds())
+	logger.InfoContext(ctx, "health check completed", "status", overallStatus, "duration_ms", time.Since(startTime).Milliseconds())
 
 	return events.APIGatewayProxyResponse{
 		StatusCode: statusCode,
@@ -145,6 +237,67 @@ func handleHealthCheck(ctx context.Context, request events.APIGatewayProxyReques
 	}, nil
 }
 
+// isDeepHealthCheck reports whether request asked for a deep check, either
+// via ?deep=true or a dedicated /health/deep route, so a plain /health call
+// stays as cheap as it's always been.
+func isDeepHealthCheck(request events.APIGatewayProxyRequest) bool {
+	if request.QueryStringParameters["deep"] == "true" {
+		return true
+	}
+	return strings.HasSuffix(request.Path, "/health/deep")
+}
+
+// runShallowHealthCheck is the original S3-only check: cheap, and skips
+// OpenRouter/OpenAI entirely to avoid spending API calls on every request.
+func runShallowHealthCheck(ctx context.Context) map[string]ServiceHealth {
+	return map[string]ServiceHealth{
+		"s3":         checkS3Health(ctx),
+		"openrouter": {Status: "skipped"},
+		"openai":     {Status: "skipped"},
+	}
+}
+
+// runDeepHealthCheck runs every probe in deepProbes concurrently through its
+// own circuit breaker, so a dependency whose breaker is already open fails
+// its probe at once and reports "unhealthy" instead of waiting out
+// ProbeTimeout again.
+func runDeepHealthCheck(ctx context.Context) map[string]ServiceHealth {
+	results := health.RunDeep(ctx, deepProbes, cfgManager.Current().Health.ProbeTimeout)
+
+	services := make(map[string]ServiceHealth, len(results))
+	for _, result := range results {
+		services[result.Name] = serviceHealthFromProbe(result)
+	}
+	return services
+}
+
+// serviceHealthFromProbe maps a deep probe's result to a ServiceHealth,
+// treating a half-open breaker (probing recovery, not yet trusted) as
+// "degraded" even when its single probe call succeeded.
+func serviceHealthFromProbe(result health.ProbeResult) ServiceHealth {
+	status := "healthy"
+	switch {
+	case !result.Healthy:
+		status = "unhealthy"
+	case result.BreakerState != resilience.CircuitClosed.String():
+		status = "degraded"
+	}
+
+	lastSuccess := ""
+	if !result.LastSuccess.IsZero() {
+		lastSuccess = result.LastSuccess.UTC().Format(time.RFC3339)
+	}
+
+	return ServiceHealth{
+		Status:       status,
+		ResponseTime: result.Latency,
+		Error:        result.Error,
+		BreakerState: result.BreakerState,
+		LastSuccess:  lastSuccess,
+		FailureRate:  result.FailureRate,
+	}
+}
+
 // checkS3Health verifies S3 storage connectivity
 func checkS3Health(ctx context.Context) ServiceHealth {
 	start := time.Now()
@@ -157,7 +310,7 @@ func checkS3Health(ctx context.Context) ServiceHealth {
 	responseTime := time.Since(start)
 
 	if err != nil {
-		log.Printf("S3 health check failed: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "s3 health check failed", "error", err)
 		return ServiceHealth{
 			Status:       "unhealthy",
 			ResponseTime: responseTime.String(),
@@ -203,16 +356,29 @@ func handleDirectInvocation(ctx context.Context) (HealthCheckResponse, error) {
 }
 
 func main() {
-	// Handle both API Gateway and direct invocations
-	lambda.Start(func(ctx context.Context, event json.RawMessage) (interface{}, error) {
-		// Try to parse as API Gateway event first
-		var apiGatewayEvent events.APIGatewayProxyRequest
-		if err := json.Unmarshal(event, &apiGatewayEvent); err == nil && apiGatewayEvent.RequestContext.RequestID != "" {
-			// API Gateway invocation
-			return handleHealthCheck(ctx, apiGatewayEvent)
-		}
+	// In the Lambda execution environment, handle API Gateway and direct
+	// invocations exactly as before; outside it, run a long-running HTTP
+	// server backed by registry's background-polled probes, so /readyz
+	// never pays a live AWS/OpenRouter/OpenAI round trip per request.
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(func(ctx context.Context, event json.RawMessage) (interface{}, error) {
+			var apiGatewayEvent events.APIGatewayProxyRequest
+			if err := json.Unmarshal(event, &apiGatewayEvent); err == nil && apiGatewayEvent.RequestContext.RequestID != "" {
+				return handleHealthCheck(ctx, apiGatewayEvent)
+			}
+			return handleDirectInvocation(ctx)
+		})
+		return
+	}
+
+	addr := os.Getenv("HEALTH_ADDR")
+	if addr == "" {
+		addr = defaultHealthAddr
+	}
+
+	mux := health.NewMux(registry)
+	mux.Handle("/metrics", metrics.Handler())
 
-		// Direct invocation
-		return handleDirectInvocation(ctx)
-	})
+	log.Printf("Health-check server listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
 }
@@ -31,9 +31,18 @@ func init() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize DynamoDB client (mock for Phase 2)
-	dynamoDB = storage.NewMockDynamoDBClient()
-	log.Println("Initialized mock DynamoDB client")
+	// Initialize DynamoDB client (mock for local development, real AWS
+	// DynamoDB - or LocalStack, via cfg.DynamoDB.Endpoint - otherwise)
+	if cfg.IsProduction() {
+		dynamoDB, err = storage.NewDynamoDBClient(context.Background(), cfg.DynamoDB)
+		if err != nil {
+			log.Fatalf("Failed to initialize DynamoDB client: %v", err)
+		}
+		log.Println("Initialized AWS DynamoDB client")
+	} else {
+		dynamoDB = storage.NewMockDynamoDBClient()
+		log.Println("Initialized mock DynamoDB client")
+	}
 
 	// Initialize S3 client (mock for Phase 2)
 	s3Client = graph.NewMockS3Client()
@@ -74,14 +83,16 @@ func handleCognitoTrigger(ctx context.Context, event CognitoTriggerEvent) (Cogni
 		userContext = storage.GenerateMockUserContext(userID)
 	}
 
-	// Cache context in DynamoDB for fast access during conversations
-	cacheItem := &types.UserContextCacheItem{
-		UserID:      userID,
-		ContextData: userContext,
-		TTL:         time.Now().Add(1 * time.Hour).Unix(), // 1-hour cache
-	}
-
-	if err := dynamoDB.CacheUserContext(ctx, cacheItem); err != nil {
+	// Cache context in DynamoDB for fast access during conversations.
+	// GuaranteedUpdate re-reads and retries on a concurrent writer
+	// (e.g. an active session refreshing the same user's context) instead
+	// of clobbering it.
+	err = storage.GuaranteedUpdate(ctx, dynamoDB, userID, func(current *types.UserContextCacheItem) (*types.UserContextCacheItem, error) {
+		current.ContextData = userContext
+		current.TTL = time.Now().Add(1 * time.Hour).Unix() // 1-hour cache
+		return current, nil
+	})
+	if err != nil {
 		log.Printf("Failed to cache user context: %v", err)
 		// Don't fail the login process, just log the error
 	} else {
@@ -105,14 +116,15 @@ func handleDirectInvocation(ctx context.Context, req types.LoginContextRequest)
 	}
 
 	// Cache context in DynamoDB
-	cacheItem := &types.UserContextCacheItem{
-		UserID:         req.UserID,
-		LoginSessionID: req.LoginSessionID,
-		ContextData:    userContext,
-		TTL:            time.Now().Add(1 * time.Hour).Unix(),
-	}
-
-	if err := dynamoDB.CacheUserContext(ctx, cacheItem); err != nil {
+	var cacheItem *types.UserContextCacheItem
+	err = storage.GuaranteedUpdate(ctx, dynamoDB, req.UserID, func(current *types.UserContextCacheItem) (*types.UserContextCacheItem, error) {
+		current.LoginSessionID = req.LoginSessionID
+		current.ContextData = userContext
+		current.TTL = time.Now().Add(1 * time.Hour).Unix()
+		cacheItem = current
+		return current, nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to cache user context: %w", err)
 	}
 
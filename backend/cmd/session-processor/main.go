@@ -7,21 +7,42 @@ import (
 	"innerworld-backend/internal/config"
 	"innerworld-backend/internal/graph"
 	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/resilience"
 	"innerworld-backend/internal/storage"
+	"innerworld-backend/internal/stream"
 	"innerworld-backend/internal/types"
 	"log"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+)
+
+// elementExtractionModel is passed as TaskProfile.PreferredModels[0] for
+// extractConversationElements' Router.Chat call: a quality/cost tier good
+// enough for structured element extraction without the latency of a
+// top-tier model.
+const elementExtractionModel = "anthropic/claude-3.5-sonnet"
+
+// perUserCallsPerHour and perUserTokensPerHour bound how much LLM spend a
+// single user's session-end processing can rack up in an hour, so a retry
+// storm or compromised client can't run up an unbounded third-party bill.
+const (
+	perUserCallsPerHour  = 20
+	perUserTokensPerHour = 50000
 )
 
 // Global variables for connection reuse across invocations
 var (
-	cfg              *config.Config
-	dynamoDB         storage.DynamoDBClient
-	neptuneClient    graph.NeptuneClient
-	openRouterClient *llm.OpenRouterClient
+	cfg           *config.Config
+	dynamoDB      storage.DynamoDBClient
+	neptuneClient graph.NeptuneClient
+	llmRouter     *llm.Router
+	safetyFilter  *llm.SafetyFilter
 )
 
 // init runs once when Lambda container starts
@@ -34,24 +55,78 @@ func init() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize DynamoDB client (mock for Phase 2)
-	dynamoDB = storage.NewMockDynamoDBClient()
-	log.Println("Initialized mock DynamoDB client")
+	// Initialize DynamoDB client (mock for local development, real AWS
+	// DynamoDB - or LocalStack, via cfg.DynamoDB.Endpoint - otherwise)
+	if cfg.IsProduction() {
+		dynamoDB, err = storage.NewDynamoDBClient(context.Background(), cfg.DynamoDB)
+		if err != nil {
+			log.Fatalf("Failed to initialize DynamoDB client: %v", err)
+		}
+		log.Println("Initialized AWS DynamoDB client for production")
+	} else {
+		dynamoDB = storage.NewMockDynamoDBClient()
+		log.Println("Initialized Mock DynamoDB client for development")
+	}
 
 	// Initialize Neptune client (mock for Phase 2)
 	neptuneClient = graph.NewMockNeptuneClient()
 	log.Println("Initialized mock Neptune client - GraphRAG disabled in Phase 2")
 
-	// Initialize OpenRouter client for element extraction
+	safetyFilter = llm.NewSafetyFilter(llm.NewSpendBudget(perUserCallsPerHour, perUserTokensPerHour, time.Hour))
+	llmRouter = buildLLMRouter(cfg)
+}
+
+// buildLLMRouter registers every LLM provider cfg has credentials for (and,
+// in the development environment, a local Ollama daemon at
+// cfg.Ollama.BaseURL, registered without checking that it is reachable) as a
+// Router fallback candidate, in
+// priority order: OpenRouter first since it already fronts multiple model
+// providers itself, then direct Anthropic and OpenAI as fallbacks for when
+// OpenRouter itself is down, then local Ollama last so development/test
+// environments without any API key still get real completions instead of
+// immediately falling back to generateMockElements. Returns a Router with
+// zero candidates if nothing is configured, so extractConversationElements'
+// existing mock fallback keeps working unchanged.
+func buildLLMRouter(cfg *config.Config) *llm.Router {
+	router := llm.NewRouter(resilience.NewRegistry(resilience.DefaultBreakerConfig()))
+	router.Use(llm.LoggingMiddleware())
+	router.Use(safetyFilter.Middleware())
+
 	if cfg.OpenRouter.APIKey != "" && cfg.OpenRouter.APIKey != "your-openrouter-api-key-here" {
-		openRouterClient = llm.NewOpenRouterClient(cfg.OpenRouter.APIKey)
-		log.Println("Initialized OpenRouter client for conversation analysis")
-	} else {
-		log.Println("OpenRouter API key not provided - will use mock element extraction")
+		router.AddProvider(llm.NewOpenRouterClient(cfg.OpenRouter.APIKey), elementExtractionModel)
+		log.Println("Registered OpenRouter provider for conversation analysis")
+	}
+
+	if cfg.Anthropic.APIKey != "" {
+		router.AddProvider(llm.AsProvider(llm.NewAnthropicBackend(cfg.Anthropic.APIKey)), cfg.Anthropic.Model)
+		log.Println("Registered direct Anthropic provider for conversation analysis")
+	}
+
+	if cfg.OpenAI.APIKey != "" && cfg.OpenAI.APIKey != "your-openai-api-key-here" {
+		router.AddProvider(llm.AsProvider(llm.NewOpenAIBackend(cfg.OpenAI.APIKey)), "")
+		log.Println("Registered direct OpenAI provider for conversation analysis")
+	}
+
+	if cfg.IsDevelopment() {
+		router.AddProvider(llm.AsProvider(llm.NewOllamaBackend(cfg.Ollama.BaseURL)), cfg.Ollama.Model)
+		log.Println("Registered local Ollama provider for conversation analysis")
 	}
+
+	if !router.HasProviders() {
+		log.Println("No LLM provider configured - will use mock element extraction")
+	}
+
+	return router
 }
 
 // handleSessionEndProcessing processes session end requests
+// handleSessionEndProcessing runs the five-step session-end pipeline,
+// checkpointing its progress in DynamoDB after each durably-committed step
+// so a Lambda retry or SQS dead-letter re-drive resumes from where the prior
+// attempt left off instead of re-extracting elements or re-writing the
+// graph. A checkpoint is looked up by SessionID at the very start; on a
+// fresh session GetProcessingCheckpoint returns nil and every step runs as
+// before.
 func handleSessionEndProcessing(ctx context.Context, request types.SessionEndRequest) (*types.SessionProcessingResult, error) {
 	log.Printf("Processing session end for session %s (reason: %s)", request.SessionID, request.Reason)
 
@@ -63,6 +138,24 @@ func handleSessionEndProcessing(ctx context.Context, request types.SessionEndReq
 		Success:     false,
 	}
 
+	checkpoint, err := dynamoDB.GetProcessingCheckpoint(ctx, request.SessionID)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to load processing checkpoint: %v", err)
+		return result, err
+	}
+	if checkpoint == nil {
+		checkpoint = &types.ProcessingCheckpoint{SessionID: request.SessionID, UserID: request.UserID}
+	}
+
+	if checkpoint.MessagesCleanedUp {
+		log.Printf("Session %s was already fully processed by a prior attempt, nothing to resume", request.SessionID)
+		result.ElementsExtracted = checkpoint.Elements
+		result.GraphNodesCreated = checkpoint.GraphNodesCreated
+		result.GraphEdgesCreated = checkpoint.GraphEdgesCreated
+		result.Success = true
+		return result, nil
+	}
+
 	// Step 1: Retrieve all messages for the session from DynamoDB
 	messages, err := dynamoDB.GetSessionMessages(ctx, request.SessionID)
 	if err != nil {
@@ -70,7 +163,7 @@ func handleSessionEndProcessing(ctx context.Context, request types.SessionEndReq
 		return result, err
 	}
 
-	if len(messages) == 0 {
+	if len(messages) == 0 && !checkpoint.ElementsExtracted {
 		log.Printf("No messages found for session %s", request.SessionID)
 		result.Success = true // Not an error, just empty session
 		return result, nil
@@ -78,41 +171,80 @@ func handleSessionEndProcessing(ctx context.Context, request types.SessionEndReq
 
 	log.Printf("Retrieved %d messages for session %s", len(messages), request.SessionID)
 
-	// Step 2: Extract conversation elements (Events, Feelings, Values, etc.)
-	elements, err := extractConversationElements(ctx, messages)
-	if err != nil {
-		result.Error = fmt.Sprintf("failed to extract conversation elements: %v", err)
-		return result, err
-	}
+	// Step 2: Extract conversation elements (Events, Feelings, Values, etc.).
+	// A resumed attempt reuses the prior attempt's extraction instead of
+	// re-running the LLM call.
+	var elements []types.ConversationElement
+	if checkpoint.ElementsExtracted {
+		elements = checkpoint.Elements
+		log.Printf("Resuming session %s with %d previously extracted elements", request.SessionID, len(elements))
+	} else {
+		elements, err = extractConversationElements(ctx, messages, request.SessionID, request.UserID)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to extract conversation elements: %v", err)
+			return result, err
+		}
+		log.Printf("Extracted %d elements from conversation", len(elements))
 
+		checkpoint.ElementsExtracted = true
+		checkpoint.Elements = elements
+		if err := dynamoDB.SaveProcessingCheckpoint(ctx, checkpoint); err != nil {
+			result.Error = fmt.Sprintf("failed to save processing checkpoint: %v", err)
+			return result, err
+		}
+	}
 	result.ElementsExtracted = elements
-	log.Printf("Extracted %d elements from conversation", len(elements))
 
-	// Step 3: Update Neptune graph with extracted elements
-	nodesCreated, edgesCreated, err := updateNeptuneGraph(ctx, request.UserID, elements)
-	if err != nil {
-		result.Error = fmt.Sprintf("failed to update Neptune graph: %v", err)
-		return result, err
-	}
+	// Step 3: Update Neptune graph with extracted elements. Skipped on
+	// resume once a prior attempt already committed it - UpsertConversationElements'
+	// deterministic merge keys (graph.ElementDedupKey) make re-running it
+	// harmless, but skipping avoids the redundant round trip.
+	if checkpoint.GraphCommitted {
+		result.GraphNodesCreated = checkpoint.GraphNodesCreated
+		result.GraphEdgesCreated = checkpoint.GraphEdgesCreated
+		log.Printf("Graph already committed for session %s (%d nodes, %d edges), skipping", request.SessionID, result.GraphNodesCreated, result.GraphEdgesCreated)
+	} else {
+		nodesCreated, edgesCreated, err := updateNeptuneGraph(ctx, request.UserID, elements)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to update Neptune graph: %v", err)
+			return result, err
+		}
 
-	result.GraphNodesCreated = nodesCreated
-	result.GraphEdgesCreated = edgesCreated
-	log.Printf("Created %d nodes and %d edges in Neptune", nodesCreated, edgesCreated)
+		result.GraphNodesCreated = nodesCreated
+		result.GraphEdgesCreated = edgesCreated
+		log.Printf("Created %d nodes and %d edges in Neptune", nodesCreated, edgesCreated)
+
+		checkpoint.GraphCommitted = true
+		checkpoint.GraphNodesCreated = nodesCreated
+		checkpoint.GraphEdgesCreated = edgesCreated
+		if err := dynamoDB.SaveProcessingCheckpoint(ctx, checkpoint); err != nil {
+			result.Error = fmt.Sprintf("failed to save processing checkpoint: %v", err)
+			return result, err
+		}
+	}
 
 	// Step 4: Refresh cached context with new graph data
-	if err := refreshUserContext(ctx, request.UserID); err != nil {
+	if err := refreshUserContext(ctx, request.SessionID, request.UserID, elements); err != nil {
 		// Log error but don't fail the entire process
 		log.Printf("Failed to refresh user context cache: %v", err)
 	} else {
 		log.Printf("Refreshed context cache for user %s", request.UserID)
 	}
 
-	// Step 5: Clean up DynamoDB conversation data
-	if err := dynamoDB.DeleteSessionMessages(ctx, request.SessionID); err != nil {
-		// Log error but don't fail the entire process
-		log.Printf("Failed to cleanup session messages: %v", err)
-	} else {
-		log.Printf("Cleaned up session messages for %s", request.SessionID)
+	// Step 5: Clean up DynamoDB conversation data, gated on the graph write
+	// being durably committed so a crash between steps 3 and 5 retries step
+	// 3 instead of losing the session's messages with nothing written.
+	if checkpoint.GraphCommitted {
+		if err := dynamoDB.DeleteSessionMessages(ctx, request.SessionID); err != nil {
+			// Log error but don't fail the entire process
+			log.Printf("Failed to cleanup session messages: %v", err)
+		} else {
+			checkpoint.MessagesCleanedUp = true
+			if err := dynamoDB.SaveProcessingCheckpoint(ctx, checkpoint); err != nil {
+				log.Printf("Failed to save final processing checkpoint: %v", err)
+			}
+			log.Printf("Cleaned up session messages for %s", request.SessionID)
+		}
 	}
 
 	result.Success = true
@@ -121,8 +253,35 @@ func handleSessionEndProcessing(ctx context.Context, request types.SessionEndReq
 	return result, nil
 }
 
+// extractedElementSchema is the JSON Schema StructuredExtract validates the
+// model's response against; its property names match extractedElement's
+// json tags.
+const extractedElementSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "array",
+  "maxItems": 8,
+  "items": {
+    "type": "object",
+    "properties": {
+      "type": {"type": "string", "enum": ["Event", "Feeling", "Value", "Goal", "Habit", "Person", "Place", "CopingStrategy"]},
+      "content": {"type": "string", "minLength": 1},
+      "confidence": {"type": "number", "minimum": 0, "maximum": 1}
+    },
+    "required": ["type", "content", "confidence"],
+    "additionalProperties": false
+  }
+}`
+
+// extractedElement mirrors extractedElementSchema's item shape for
+// json.Unmarshal in llm.StructuredExtract.
+type extractedElement struct {
+	Type       string  `json:"type"`
+	Content    string  `json:"content"`
+	Confidence float64 `json:"confidence"`
+}
+
 // extractConversationElements analyzes the conversation to extract meaningful elements
-func extractConversationElements(ctx context.Context, messages []types.LiveConversationItem) ([]types.ConversationElement, error) {
+func extractConversationElements(ctx context.Context, messages []types.LiveConversationItem, sessionID, userID string) ([]types.ConversationElement, error) {
 	log.Printf("Extracting elements from %d messages", len(messages))
 
 	// Build conversation text for analysis
@@ -136,26 +295,35 @@ func extractConversationElements(ctx context.Context, messages []types.LiveConve
 		conversation = conversation[:4000] + "..."
 	}
 
-	if openRouterClient == nil {
+	if !llmRouter.HasProviders() {
 		// Mock element extraction for Phase 2
 		return generateMockElements(messages), nil
 	}
 
-	// Use LLM to analyze conversation and extract elements
-	analysisPrompt := buildExtractionPrompt(conversation)
-
-	response, err := openRouterClient.GenerateResponse(ctx, analysisPrompt)
+	fencedConversation, err := safetyFilter.FenceUserContent(conversation)
 	if err != nil {
-		log.Printf("LLM element extraction failed, using mock: %v", err)
-		return generateMockElements(messages), nil
+		return nil, fmt.Errorf("conversation rejected by safety filter: %w", err)
 	}
 
-	if len(response.Choices) == 0 {
+	prompt := buildExtractionPrompt(fencedConversation)
+	extractor := llm.NewExtractor(llmRouter, llm.TaskProfile{PreferredModels: []string{elementExtractionModel}})
+
+	raw, err := llm.StructuredExtract[[]extractedElement](ctx, extractor, sessionID, userID, prompt, extractedElementSchema)
+	if err != nil {
+		log.Printf("LLM element extraction failed on every provider/repair attempt, using mock: %v", err)
 		return generateMockElements(messages), nil
 	}
 
-	// Parse LLM response into structured elements
-	elements := parseExtractedElements(response.Choices[0].Message.Content, messages)
+	elements := make([]types.ConversationElement, len(raw))
+	for i, element := range raw {
+		elements[i] = types.ConversationElement{
+			Type:       element.Type,
+			Content:    element.Content,
+			Confidence: element.Confidence,
+			Timestamp:  time.Now(),
+			Metadata:   make(map[string]interface{}),
+		}
+	}
 
 	log.Printf("LLM extracted %d elements", len(elements))
 	return elements, nil
@@ -163,7 +331,7 @@ func extractConversationElements(ctx context.Context, messages []types.LiveConve
 
 // buildExtractionPrompt creates the prompt for element extraction
 func buildExtractionPrompt(conversation string) string {
-	return fmt.Sprintf(`Analyze this conversation between a teen and an AI companion. Extract meaningful elements in JSON format:
+	return fmt.Sprintf(`Analyze this conversation between a teen and an AI companion. Extract meaningful elements.
 
 Types to extract:
 - Event: Specific things that happened or are happening
@@ -171,66 +339,14 @@ Types to extract:
 - Value: What matters to the user or guides their decisions
 - Goal: Things the user wants to achieve or work toward
 - Habit: Patterns of behavior, positive or negative
-
-For each element, provide:
-- type: one of the above types
-- content: brief description 
-- confidence: 0.0-1.0 confidence score
-
-Example output:
-[
-  {"type": "Event", "content": "presentation at school tomorrow", "confidence": 0.9},
-  {"type": "Feeling", "content": "nervous about public speaking", "confidence": 0.8},
-  {"type": "Value", "content": "wants to do well academically", "confidence": 0.7}
-]
+- Person: People the user mentions (by relationship, e.g. "mom", not a full name)
+- Place: Places the user mentions (school, home, a specific location)
+- CopingStrategy: Techniques the user tried or was offered (breathing exercises, journaling, talking to someone)
 
 Conversation:
 %s
 
-Extract 3-8 most meaningful elements as JSON array:`, conversation)
-}
-
-// parseExtractedElements converts LLM response to structured elements
-func parseExtractedElements(llmResponse string, messages []types.LiveConversationItem) []types.ConversationElement {
-	// Try to parse JSON from LLM response
-	var elements []types.ConversationElement
-
-	// Look for JSON array in the response
-	start := strings.Index(llmResponse, "[")
-	end := strings.LastIndex(llmResponse, "]")
-
-	if start != -1 && end != -1 && end > start {
-		jsonStr := llmResponse[start : end+1]
-
-		var rawElements []map[string]interface{}
-		if err := json.Unmarshal([]byte(jsonStr), &rawElements); err == nil {
-			for _, raw := range rawElements {
-				element := types.ConversationElement{
-					Timestamp: time.Now(),
-					Metadata:  make(map[string]interface{}),
-				}
-
-				if typ, ok := raw["type"].(string); ok {
-					element.Type = typ
-				}
-				if content, ok := raw["content"].(string); ok {
-					element.Content = content
-				}
-				if conf, ok := raw["confidence"].(float64); ok {
-					element.Confidence = conf
-				}
-
-				elements = append(elements, element)
-			}
-		}
-	}
-
-	// Fallback to mock if parsing failed
-	if len(elements) == 0 {
-		elements = generateMockElements(messages)
-	}
-
-	return elements
+Extract 3-8 most meaningful elements.`, conversation)
 }
 
 // generateMockElements creates mock elements for Phase 2 testing
@@ -278,41 +394,42 @@ func generateMockElements(messages []types.LiveConversationItem) []types.Convers
 	return elements
 }
 
-// updateNeptuneGraph creates nodes and edges in the Neptune graph database
+// updateNeptuneGraph merges the session's extracted elements into the
+// Neptune graph as a single batched upsert, so repeated Feelings/Values
+// across sessions dedupe into one node (with firstSeen/lastSeen/count kept
+// current) instead of piling up a new node every time. The upsert is
+// wrapped in a retry so a transient Gremlin connection drop doesn't throw
+// away a whole session's extraction and force a checkpoint replay.
 func updateNeptuneGraph(ctx context.Context, userID string, elements []types.ConversationElement) (int, int, error) {
 	log.Printf("Updating Neptune graph for user %s with %d elements", userID, len(elements))
 
-	// Phase 2: Mock Neptune operations
-	// Phase 3+: Real Gremlin queries to create nodes and relationships
-
-	nodesCreated := 0
-	edgesCreated := 0
-
-	for _, element := range elements {
-		// Mock node creation
-		nodeID := fmt.Sprintf("%s_%s_%d", element.Type, userID, time.Now().Unix())
-
-		// Create node in Neptune (mock)
-		if err := neptuneClient.CreateNode(userID, element.Type, element.Content); err != nil {
-			log.Printf("Failed to create node %s: %v", nodeID, err)
-			continue
-		}
-		nodesCreated++
+	type upsertResult struct {
+		nodesCreated, edgesCreated int
+	}
 
-		// Create temporal edge (mock)
-		if err := neptuneClient.CreateEdge(userID, nodeID, "temporal", element.Timestamp.Format(time.RFC3339)); err != nil {
-			log.Printf("Failed to create temporal edge for %s: %v", nodeID, err)
-		} else {
-			edgesCreated++
-		}
+	result, err := resilience.RetryWithBackoff(ctx, resilience.DefaultRetryConfig(), resilience.NeptuneRetryableErrors,
+		func(ctx context.Context, _ int) (upsertResult, error) {
+			nodes, edges, err := neptuneClient.UpsertConversationElements(ctx, userID, elements)
+			return upsertResult{nodes, edges}, err
+		})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert conversation elements: %w", err)
 	}
 
-	log.Printf("Neptune graph update completed: %d nodes, %d edges", nodesCreated, edgesCreated)
-	return nodesCreated, edgesCreated, nil
+	log.Printf("Neptune graph update completed: %d nodes, %d edges", result.nodesCreated, result.edgesCreated)
+	return result.nodesCreated, result.edgesCreated, nil
 }
 
-// refreshUserContext updates the cached user context with new Neptune data
-func refreshUserContext(ctx context.Context, userID string) error {
+// refreshUserContext updates the cached user context with new Neptune data,
+// merging this session's extracted Event/Feeling/Value elements in as
+// extracted_themes. It first asks the LLM to roll this session's elements
+// into GraphContext.Summary and persists that via UpdateUserGraph, so the
+// cache picks up the fresh summary rather than whatever GetUserContext
+// returned before this session's elements landed. It goes through
+// storage.GuaranteedUpdate's optimistic-concurrency loop rather than
+// RefreshUserContext's unconditional overwrite, since a login refresh or
+// another session's end could be updating the same cache entry concurrently.
+func refreshUserContext(ctx context.Context, sessionID, userID string, elements []types.ConversationElement) error {
 	log.Printf("Refreshing context cache for user %s", userID)
 
 	// Retrieve updated context from Neptune
@@ -321,26 +438,40 @@ func refreshUserContext(ctx context.Context, userID string) error {
 		return fmt.Errorf("failed to retrieve updated context: %w", err)
 	}
 
-	// Convert GraphContext to map format for caching
-	contextData := map[string]interface{}{
-		"user_id":      userID,
-		"summary":      graphContext.Summary,
-		"last_updated": graphContext.LastUpdated.Format(time.RFC3339),
-		"refreshed_at": time.Now().Format(time.RFC3339),
-	}
+	themes := extractedThemes(elements)
 
-	// Add additional mock context data
-	mockData := storage.GenerateMockUserContext(userID)
-	for key, value := range mockData {
-		contextData[key] = value
+	newSummary := generateRollingSummary(ctx, sessionID, userID, graphContext.Summary, elements)
+	if newSummary != graphContext.Summary {
+		if _, err := resilience.RetryWithBackoff(ctx, resilience.DefaultRetryConfig(), resilience.NeptuneRetryableErrors,
+			func(ctx context.Context, _ int) (struct{}, error) {
+				return struct{}{}, neptuneClient.UpdateUserGraph(ctx, userID, map[string]interface{}{"summary": newSummary})
+			}); err != nil {
+			log.Printf("Failed to persist rolling summary for user %s (continuing with prior summary): %v", userID, err)
+		} else {
+			graphContext.Summary = newSummary
+		}
 	}
 
-	// Add refresh timestamp
-	contextData["last_refreshed"] = time.Now().Format(time.RFC3339)
-	contextData["refresh_source"] = "session_processing"
+	err = storage.GuaranteedUpdate(ctx, dynamoDB, userID, func(current *types.UserContextCacheItem) (*types.UserContextCacheItem, error) {
+		contextData := make(map[string]interface{})
+		for key, value := range storage.GenerateMockUserContext(userID) {
+			contextData[key] = value
+		}
 
-	// Update cache
-	if err := dynamoDB.RefreshUserContext(ctx, userID, contextData); err != nil {
+		contextData["user_id"] = userID
+		contextData["summary"] = graphContext.Summary
+		contextData["last_updated"] = graphContext.LastUpdated.Format(time.RFC3339)
+		contextData["extracted_themes"] = themes
+		contextData["last_refreshed"] = time.Now().Format(time.RFC3339)
+		contextData["refresh_source"] = "session_processing"
+
+		// Mutate current rather than returning a fresh literal, so fields
+		// this refresh doesn't touch (LoginSessionID, TTL) survive instead
+		// of reverting to their zero value on every session-end refresh.
+		current.ContextData = contextData
+		return current, nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update context cache: %w", err)
 	}
 
@@ -348,17 +479,208 @@ func refreshUserContext(ctx context.Context, userID string) error {
 	return nil
 }
 
-func main() {
-	lambda.Start(func(ctx context.Context, event json.RawMessage) (interface{}, error) {
-		var request types.SessionEndRequest
-		if err := json.Unmarshal(event, &request); err != nil {
-			return nil, fmt.Errorf("failed to parse session end request: %w", err)
+// generateRollingSummary asks the LLM to fold this session's elements into
+// priorSummary, producing a short rolling summary of the user's history for
+// GraphContext.Summary. It's a plain free-text Router.Chat call rather than
+// llm.StructuredExtract, since a prose summary has no schema to validate
+// against. Falls back to a deterministic summary built from
+// extractedThemes when no provider is configured or the call fails, mirroring
+// extractConversationElements' generateMockElements fallback.
+func generateRollingSummary(ctx context.Context, sessionID, userID, priorSummary string, elements []types.ConversationElement) string {
+	if !llmRouter.HasProviders() {
+		return mockRollingSummary(priorSummary, elements)
+	}
+
+	prompt := buildRollingSummaryPrompt(priorSummary, elements)
+	req := llm.ChatRequest{
+		Messages: []llm.ChatMessage{
+			{Role: "system", Content: "You maintain a short rolling summary of a teen's conversations with an AI companion, for use as context in future sessions. Respond with only the updated summary, 2-4 sentences, no preamble."},
+			{Role: "user", Content: prompt},
+		},
+	}
+
+	response, err := llmRouter.Chat(ctx, req, llm.TaskProfile{PreferredModels: []string{elementExtractionModel}}, sessionID, userID)
+	if err != nil || len(response.Choices) == 0 {
+		log.Printf("Rolling summary generation failed, falling back to mock summary: %v", err)
+		return mockRollingSummary(priorSummary, elements)
+	}
+
+	summary := strings.TrimSpace(response.Choices[0].Message.Content)
+	if summary == "" {
+		return mockRollingSummary(priorSummary, elements)
+	}
+	return summary
+}
+
+// buildRollingSummaryPrompt creates the prompt for folding this session's
+// elements into the prior rolling summary.
+func buildRollingSummaryPrompt(priorSummary string, elements []types.ConversationElement) string {
+	var extracted strings.Builder
+	for _, el := range elements {
+		fmt.Fprintf(&extracted, "- %s: %s\n", el.Type, el.Content)
+	}
+
+	return fmt.Sprintf(`Previous summary:
+%s
+
+New elements from this session:
+%s
+
+Update the summary to fold in anything new and still relevant, dropping anything this session shows is no longer current.`, priorSummary, extracted.String())
+}
+
+// mockRollingSummary deterministically folds elements into priorSummary
+// without an LLM call, for Phase 2 testing and as a fallback when every
+// provider fails.
+func mockRollingSummary(priorSummary string, elements []types.ConversationElement) string {
+	themes := extractedThemes(elements)
+	if len(themes) == 0 {
+		return priorSummary
+	}
+	return fmt.Sprintf("%s Recent themes: %s.", priorSummary, strings.Join(themes, ", "))
+}
+
+// extractedThemes reduces elements down to the short theme strings a
+// persona prompt cares about: one per Event/Feeling/Value element, in
+// extraction order.
+func extractedThemes(elements []types.ConversationElement) []string {
+	themes := make([]string, 0, len(elements))
+	for _, el := range elements {
+		switch el.Type {
+		case "Event", "Feeling", "Value":
+			themes = append(themes, el.Content)
 		}
+	}
+	return themes
+}
+
+// handleLambdaEvent dispatches an incoming event to the right processing
+// path. A DynamoDB Streams trigger delivers a batch of Records; anything
+// else is treated as a direct SessionEndRequest invocation, kept for
+// test-phase2/test-e2e-conversation and manual ops-console invocation.
+func handleLambdaEvent(ctx context.Context, event json.RawMessage) (interface{}, error) {
+	var streamEvent events.DynamoDBEvent
+	if err := json.Unmarshal(event, &streamEvent); err == nil && len(streamEvent.Records) > 0 {
+		return nil, processStreamRecords(ctx, toStreamRecords(streamEvent.Records))
+	}
 
-		if request.SessionID == "" || request.UserID == "" {
-			return nil, fmt.Errorf("missing required fields: sessionId and userId")
+	var request types.SessionEndRequest
+	if err := json.Unmarshal(event, &request); err != nil {
+		return nil, fmt.Errorf("failed to parse session end request: %w", err)
+	}
+	if request.SessionID == "" || request.UserID == "" {
+		return nil, fmt.Errorf("missing required fields: sessionId and userId")
+	}
+
+	return handleSessionEndProcessing(ctx, request)
+}
+
+// toStreamRecords flattens a DynamoDB Streams Lambda event's records into
+// stream.Record, the same shape internal/stream's LocalStack poller
+// produces, so processStreamRecords handles both identically.
+func toStreamRecords(records []events.DynamoDBEventRecord) []stream.Record {
+	out := make([]stream.Record, 0, len(records))
+	for _, r := range records {
+		rec := stream.Record{
+			EventName: r.EventName,
+			Keys:      make(map[string]string, len(r.Change.Keys)),
+			NewImage:  make(map[string]string, len(r.Change.NewImage)),
 		}
+		for k, v := range r.Change.Keys {
+			if v.DataType() == events.DataTypeString {
+				rec.Keys[k] = v.String()
+			}
+		}
+		for k, v := range r.Change.NewImage {
+			if v.DataType() == events.DataTypeString {
+				rec.NewImage[k] = v.String()
+			}
+		}
+		out = append(out, rec)
+	}
+	return out
+}
 
-		return handleSessionEndProcessing(ctx, request)
-	})
+// processStreamRecords filters records down to session_end messages and
+// runs each one through handleSessionEndProcessing. Streams' at-least-once
+// delivery means the same session_end record can arrive more than once
+// (a retried batch, or both the real trigger and a manual re-drive); that's
+// guarded by handleSessionEndProcessing's own processing checkpoint, keyed
+// on SessionID, which short-circuits once MessagesCleanedUp is set rather
+// than re-running extraction or re-writing the graph - so no separate
+// dedupe table is needed here.
+func processStreamRecords(ctx context.Context, records []stream.Record) error {
+	for _, rec := range records {
+		if rec.EventName != "INSERT" {
+			continue
+		}
+		if rec.NewImage["message_type"] != "session_end" {
+			continue
+		}
+
+		sessionID := rec.NewImage["session_id"]
+		userID := rec.NewImage["user_id"]
+		if sessionID == "" || userID == "" {
+			log.Printf("Skipping session_end record missing session_id/user_id: %+v", rec.Keys)
+			continue
+		}
+
+		request := types.SessionEndRequest{SessionID: sessionID, UserID: userID, Reason: "disconnect"}
+		result, err := handleSessionEndProcessing(ctx, request)
+		if err != nil {
+			return fmt.Errorf("failed to process session_end for session %s: %w", sessionID, err)
+		}
+		log.Printf("Processed session_end for session %s: success=%v", sessionID, result.Success)
+	}
+	return nil
+}
+
+// runLocalStreamPoller drives processStreamRecords from internal/stream's
+// LocalStack poller instead of a real Lambda trigger, for the local dev
+// loop where no event-source mapping exists. It loads its own AWS clients
+// pointed at cfg.DynamoDB.Endpoint rather than reusing the package's
+// dynamoDB DynamoDBClient, since Streams has no equivalent of that
+// interface's mock. It blocks until ctx is cancelled.
+func runLocalStreamPoller(ctx context.Context) error {
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if cfg.DynamoDB.Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.DynamoDB.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	endpointOpt := func(o *dynamodb.Options) {
+		if cfg.DynamoDB.Endpoint != "" {
+			o.BaseEndpoint = &cfg.DynamoDB.Endpoint
+		}
+	}
+	streamsEndpointOpt := func(o *dynamodbstreams.Options) {
+		if cfg.DynamoDB.Endpoint != "" {
+			o.BaseEndpoint = &cfg.DynamoDB.Endpoint
+		}
+	}
+
+	tableClient := dynamodb.NewFromConfig(awsCfg, endpointOpt)
+	streamArn, err := stream.ResolveStreamARN(ctx, tableClient, cfg.DynamoDB.LiveConversationsTable)
+	if err != nil {
+		return fmt.Errorf("failed to resolve LiveConversations stream ARN: %w", err)
+	}
+
+	reader := stream.NewReader(dynamodbstreams.NewFromConfig(awsCfg, streamsEndpointOpt), streamArn)
+	log.Printf("Polling LiveConversations stream %s for session_end records", streamArn)
+
+	return reader.Run(ctx, 2*time.Second, processStreamRecords)
+}
+
+func main() {
+	if cfg.DynamoDB.Endpoint != "" {
+		if err := runLocalStreamPoller(context.Background()); err != nil {
+			log.Fatalf("Stream poller stopped: %v", err)
+		}
+		return
+	}
+
+	lambda.Start(handleLambdaEvent)
 }
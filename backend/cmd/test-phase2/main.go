@@ -3,26 +3,54 @@ package main
 import (
 	"context"
 	"fmt"
+	"innerworld-backend/internal/config"
 	"innerworld-backend/internal/personas"
 	"innerworld-backend/internal/storage"
 	"innerworld-backend/internal/types"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// newTestDynamoDBClient picks the DynamoDBClient every test function below
+// runs its assertions against: a mock in development (the default, and what
+// CI runs without AWS credentials configured), or the real aws-sdk-go-v2
+// client - pointed at LocalStack via cfg.DynamoDB.Endpoint, or real AWS
+// DynamoDB in production - otherwise. Same test functions, same assertions,
+// either backend.
+func newTestDynamoDBClient(ctx context.Context, cfg *config.Config) storage.DynamoDBClient {
+	if cfg.IsDevelopment() {
+		fmt.Println("   (using mock DynamoDB client)")
+		return storage.NewMockDynamoDBClient()
+	}
+
+	client, err := storage.NewDynamoDBClient(ctx, cfg.DynamoDB)
+	if err != nil {
+		log.Fatalf("Failed to initialize DynamoDB client: %v", err)
+	}
+	fmt.Println("   (using real AWS DynamoDB client)")
+	return client
+}
+
 func main() {
 	fmt.Println("=== InnerWorld Phase 2 Backend Test ===")
 
 	ctx := context.Background()
 
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	dynamoDB := newTestDynamoDBClient(ctx, cfg)
+
 	// Test 1: Persona Loading System
 	fmt.Println("\n1. Testing Persona Loading System...")
 	testPersonaLoader()
 
-	// Test 2: DynamoDB Mock Operations
-	fmt.Println("\n2. Testing DynamoDB Mock Operations...")
-	testDynamoDBOperations(ctx)
+	// Test 2: DynamoDB Operations
+	fmt.Println("\n2. Testing DynamoDB Operations...")
+	testDynamoDBOperations(ctx, dynamoDB)
 
 	// Test 3: Persona System Integration
 	fmt.Println("\n3. Testing Persona System Integration...")
@@ -30,11 +58,11 @@ func main() {
 
 	// Test 4: Login Context Caching
 	fmt.Println("\n4. Testing Login Context Caching...")
-	testLoginContextCaching(ctx)
+	testLoginContextCaching(ctx, dynamoDB)
 
 	// Test 5: Session End Processing
 	fmt.Println("\n5. Testing Session End Processing...")
-	testSessionEndProcessing(ctx)
+	testSessionEndProcessing(ctx, dynamoDB)
 
 	fmt.Println("\n=== Phase 2 Backend Test Complete ===")
 }
@@ -73,9 +101,7 @@ func testPersonaLoader() {
 	fmt.Printf("✅ Available personas: %v\n", available)
 }
 
-func testDynamoDBOperations(ctx context.Context) {
-	dynamoDB := storage.NewMockDynamoDBClient()
-
+func testDynamoDBOperations(ctx context.Context, dynamoDB storage.DynamoDBClient) {
 	// Test conversation storage
 	sessionID := "test_session_" + uuid.New().String()[:8]
 	userID := "test_user_123"
@@ -104,7 +130,7 @@ func testDynamoDBOperations(ctx context.Context) {
 		conversationID := storage.CreateConversationID(sessionID)
 		msg.ConversationID = conversationID
 
-		if err := dynamoDB.StoreMessage(ctx, &msg); err != nil {
+		if err := dynamoDB.StoreMessage(ctx, &msg, 0); err != nil {
 			fmt.Printf("❌ Error storing message: %v\n", err)
 			return
 		}
@@ -127,7 +153,7 @@ func testDynamoDBOperations(ctx context.Context) {
 		ContextData: storage.GenerateMockUserContext(userID),
 	}
 
-	if err := dynamoDB.CacheUserContext(ctx, contextItem); err != nil {
+	if err := dynamoDB.CacheUserContext(ctx, contextItem, 0); err != nil {
 		fmt.Printf("❌ Error caching context: %v\n", err)
 		return
 	}
@@ -178,9 +204,7 @@ func testPersonaIntegration(ctx context.Context) {
 	}
 }
 
-func testLoginContextCaching(ctx context.Context) {
-	dynamoDB := storage.NewMockDynamoDBClient()
-
+func testLoginContextCaching(ctx context.Context, dynamoDB storage.DynamoDBClient) {
 	// Simulate login context caching
 	userID := "test_login_user"
 	contextData := storage.GenerateMockUserContext(userID)
@@ -197,7 +221,7 @@ func testLoginContextCaching(ctx context.Context) {
 		TTL:            time.Now().Add(1 * time.Hour).Unix(),
 	}
 
-	if err := dynamoDB.CacheUserContext(ctx, cacheItem); err != nil {
+	if err := dynamoDB.CacheUserContext(ctx, cacheItem, 0); err != nil {
 		fmt.Printf("❌ Failed to cache login context: %v\n", err)
 		return
 	}
@@ -214,9 +238,7 @@ func testLoginContextCaching(ctx context.Context) {
 		len(retrieved.ContextData), retrieved.TTL-time.Now().Unix())
 }
 
-func testSessionEndProcessing(ctx context.Context) {
-	dynamoDB := storage.NewMockDynamoDBClient()
-
+func testSessionEndProcessing(ctx context.Context, dynamoDB storage.DynamoDBClient) {
 	// Create a test session with messages
 	sessionID := "session_end_test_" + uuid.New().String()[:8]
 	userID := "test_session_user"
@@ -251,7 +273,7 @@ func testSessionEndProcessing(ctx context.Context) {
 
 	for _, msg := range testMessages {
 		msg.ConversationID = storage.CreateConversationID(sessionID)
-		if err := dynamoDB.StoreMessage(ctx, &msg); err != nil {
+		if err := dynamoDB.StoreMessage(ctx, &msg, 0); err != nil {
 			fmt.Printf("❌ Failed to store test message: %v\n", err)
 			return
 		}
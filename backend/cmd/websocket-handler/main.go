@@ -3,29 +3,47 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"innerworld-backend/internal/config"
 	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/logging"
 	"innerworld-backend/internal/personas"
+	"innerworld-backend/internal/resilience"
 	"innerworld-backend/internal/storage"
 	"innerworld-backend/internal/types"
+	"innerworld-backend/internal/websocket"
 	"innerworld-backend/internal/workflow"
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/google/uuid"
 )
 
+// perUserCallsPerHour and perUserTokensPerHour bound how much LLM spend a
+// single user's conversations can rack up in an hour, so a retry storm or
+// compromised client can't run up an unbounded third-party bill.
+const (
+	perUserCallsPerHour  = 20
+	perUserTokensPerHour = 50000
+)
+
 // Global variables for connection reuse across invocations
 var (
 	cfg               *config.Config
+	baseLogger        *slog.Logger
 	dynamoDB          storage.DynamoDBClient
 	openRouterClient  *llm.OpenRouterClient
 	personaLoader     *personas.PersonaLoader
 	conversationChain *workflow.ConversationChain
-	connectionStore   map[string]string // connectionID -> userID mapping (mock)
+	connectionStore   websocket.ConnectionStore
+	breakerRegistry   *resilience.Registry
+	bulkheadRegistry  *resilience.BulkheadRegistry
 )
 
 // init runs once when Lambda container starts
@@ -37,14 +55,23 @@ func init() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	baseLogger = logging.New(cfg)
 
 	// Initialize DynamoDB client (mock for Phase 2)
 	dynamoDB = storage.NewMockDynamoDBClient()
 	log.Println("Initialized mock DynamoDB client")
 
+	// Initialize per-endpoint circuit breakers and bulkheads shared by the
+	// OpenRouter client and the downstream calls this handler makes
+	// directly, so one slow/failing dependency can't starve the others.
+	breakerRegistry = resilience.NewRegistry(resilience.DefaultBreakerConfig())
+	bulkheadRegistry = resilience.NewBulkheadRegistry(resilience.DefaultBulkheadConfig())
+
 	// Initialize OpenRouter client if API key is available
 	if cfg.OpenRouter.APIKey != "" && cfg.OpenRouter.APIKey != "your-openrouter-api-key-here" {
 		openRouterClient = llm.NewOpenRouterClient(cfg.OpenRouter.APIKey)
+		openRouterClient.SetBreakerRegistry(breakerRegistry)
+		openRouterClient.SetBulkheadRegistry(bulkheadRegistry)
 		log.Println("Initialized OpenRouter client")
 	} else {
 		log.Println("OpenRouter API key not provided - will use mock responses")
@@ -56,10 +83,17 @@ func init() {
 
 	// Initialize LangChain conversation chain
 	conversationChain = workflow.NewConversationChain(personaLoader, openRouterClient, dynamoDB)
+	conversationChain.SetSafetyFilter(llm.NewSafetyFilter(llm.NewSpendBudget(perUserCallsPerHour, perUserTokensPerHour, time.Hour)))
 	log.Println("Initialized LangChain conversation chain")
 
-	// Initialize connection store (mock for Phase 2)
-	connectionStore = make(map[string]string)
+	// Initialize connection store against the real WebSocket connections
+	// table, so any container (including a cold-started one) can see which
+	// user/session a connectionID belongs to.
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load AWS config: %v", err)
+	}
+	connectionStore = websocket.NewDynamoDBConnectionStore(dynamodb.NewFromConfig(awsCfg), cfg.WebSocket.ConnectionsTable)
 }
 
 // handleWebSocketEvent processes WebSocket API Gateway events
@@ -92,8 +126,15 @@ func handleConnect(ctx context.Context, request events.APIGatewayWebsocketProxyR
 		return events.APIGatewayProxyResponse{StatusCode: 400}, nil
 	}
 
-	// Store connection mapping (Phase 2: in-memory, Phase 3+: DynamoDB table)
-	connectionStore[connectionID] = userID
+	// Persist connection state so any container can look it up later.
+	if err := connectionStore.SaveConnection(ctx, &websocket.Connection{
+		ConnectionID: connectionID,
+		UserID:       userID,
+		ConnectedAt:  time.Now(),
+	}); err != nil {
+		log.Printf("Failed to save connection %s: %v", connectionID, err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, nil
+	}
 
 	log.Printf("WebSocket connected: %s -> %s", connectionID, userID)
 
@@ -105,8 +146,9 @@ func handleConnect(ctx context.Context, request events.APIGatewayWebsocketProxyR
 		MessageType: "system",
 	}
 
-	// Phase 2: Mock sending message (Phase 3+: actual WebSocket API Gateway call)
-	log.Printf("Would send welcome message to connection %s: %s", connectionID, welcomeMsg.Content)
+	if _, err := sendWebSocketResponse(ctx, request, connectionID, welcomeMsg); err != nil {
+		log.Printf("Failed to send welcome message to connection %s: %v", connectionID, err)
+	}
 
 	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
@@ -115,22 +157,42 @@ func handleConnect(ctx context.Context, request events.APIGatewayWebsocketProxyR
 func handleDisconnect(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	connectionID := request.RequestContext.ConnectionID
 
-	// Get user ID from connection mapping
-	userID, exists := connectionStore[connectionID]
-	if !exists {
+	// Look up the connection before deleting it, so we know which user/session
+	// to trigger session end processing for.
+	conn, err := connectionStore.GetConnection(ctx, connectionID)
+	if err != nil {
+		log.Printf("Failed to look up connection %s on disconnect: %v", connectionID, err)
+	}
+	if conn == nil {
 		log.Printf("Disconnect for unknown connection: %s", connectionID)
 		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 	}
 
-	log.Printf("WebSocket disconnected: %s (user: %s)", connectionID, userID)
+	log.Printf("WebSocket disconnected: %s (user: %s)", connectionID, conn.UserID)
 
-	// Clean up connection mapping
-	delete(connectionStore, connectionID)
+	if err := connectionStore.DeleteConnection(ctx, connectionID); err != nil {
+		log.Printf("Failed to delete connection %s: %v", connectionID, err)
+	}
 
-	// Trigger session end processing for any active sessions
-	// Phase 2: Mock session end processing
-	// Phase 3+: Invoke Session End Processor Lambda
-	log.Printf("Would trigger session end processing for user %s", userID)
+	// Mark the session ended by writing a sentinel message to
+	// LiveConversations. session-processor subscribes to that table's
+	// DynamoDB Stream and picks up session_end messages from there -
+	// we don't invoke it directly, so a slow or unavailable processor
+	// never blocks this handler returning to API Gateway.
+	sessionEndMsg := types.LiveConversationItem{
+		ConversationID: storage.CreateConversationID(conn.SessionID),
+		SessionID:      conn.SessionID,
+		UserID:         conn.UserID,
+		Persona:        conn.Persona,
+		MessageType:    "session_end",
+		Content:        "disconnect",
+		SessionStart:   conn.ConnectedAt,
+	}
+	if err := dynamoDB.StoreMessage(ctx, &sessionEndMsg, 0); err != nil {
+		log.Printf("Failed to record session end for session %s: %v", conn.SessionID, err)
+	} else {
+		log.Printf("Recorded session end for session %s (user %s)", conn.SessionID, conn.UserID)
+	}
 
 	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
@@ -139,12 +201,17 @@ func handleDisconnect(ctx context.Context, request events.APIGatewayWebsocketPro
 func handleSendMessage(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	connectionID := request.RequestContext.ConnectionID
 
-	// Get user ID from connection mapping
-	userID, exists := connectionStore[connectionID]
-	if !exists {
+	// Get connection state persisted at $connect
+	conn, err := connectionStore.GetConnection(ctx, connectionID)
+	if err != nil {
+		log.Printf("Failed to look up connection %s: %v", connectionID, err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, nil
+	}
+	if conn == nil {
 		log.Printf("Message from unknown connection: %s", connectionID)
 		return events.APIGatewayProxyResponse{StatusCode: 400}, nil
 	}
+	userID := conn.UserID
 
 	// Parse incoming message
 	var wsMessage types.WebSocketMessage
@@ -162,13 +229,30 @@ func handleSendMessage(ctx context.Context, request events.APIGatewayWebsocketPr
 		wsMessage.Persona = "default"
 	}
 
-	log.Printf("Processing message from user %s (session %s, persona %s): %s",
-		userID, wsMessage.SessionID, wsMessage.Persona, wsMessage.Message[:min(50, len(wsMessage.Message))])
+	// Seed the context with correlation fields pulled from the API Gateway
+	// request and the parsed WebSocketMessage, so every downstream log line
+	// for this message - including inside streamConversationToConnection -
+	// carries them automatically.
+	ctx = logging.WithRequestAttrs(ctx, baseLogger, request.RequestContext.RequestID, userID, wsMessage.Persona, wsMessage.SessionID)
+	logger := logging.FromContext(ctx)
 
-	// Retrieve cached user context
-	userContext, err := dynamoDB.GetUserContext(ctx, userID)
-	if err != nil {
-		log.Printf("Failed to retrieve user context (using empty): %v", err)
+	if err := connectionStore.UpdateSession(ctx, connectionID, wsMessage.SessionID, wsMessage.Persona); err != nil {
+		logger.ErrorContext(ctx, "failed to update session", "connection_id", connectionID, "error", err)
+	}
+
+	logger.InfoContext(ctx, "processing message", "connection_id", connectionID,
+		"message_preview", wsMessage.Message[:min(50, len(wsMessage.Message))])
+
+	// Retrieve cached user context, through its own breaker so a struggling
+	// DynamoDB table can't also trip the OpenRouter breaker's call budget
+	var userContext *types.UserContextCacheItem
+	breakerErr := breakerRegistry.Execute(ctx, "dynamodb:UserContext", func() error {
+		var err error
+		userContext, err = dynamoDB.GetUserContext(ctx, userID)
+		return err
+	})
+	if breakerErr != nil {
+		logger.ErrorContext(ctx, "failed to retrieve user context, using empty", "error", breakerErr)
 		// Continue with empty context rather than failing
 	}
 
@@ -187,34 +271,62 @@ func handleSendMessage(ctx context.Context, request events.APIGatewayWebsocketPr
 		SessionStart: time.Now(), // Phase 3+: Track actual session start time
 	}
 
-	conversationResult, err := conversationChain.ProcessConversation(ctx, conversationInput)
+	return streamConversationToConnection(ctx, request, connectionID, wsMessage, conversationInput)
+}
+
+// streamConversationToConnection runs the LangChain conversation through
+// workflow.ConversationChain.StreamConversation, fanning each token to
+// PostToConnection as "assistant_delta" frames and finally an
+// "assistant_done" frame carrying the message ID, rather than waiting for
+// the full completion. If the connection reports 410 Gone partway through,
+// it stops generating, evicts the connection, and returns 410.
+func streamConversationToConnection(ctx context.Context, request events.APIGatewayWebsocketProxyRequest, connectionID string, wsMessage types.WebSocketMessage, input *workflow.ConversationInput) (events.APIGatewayProxyResponse, error) {
+	publisher, err := websocket.NewAPIGatewayPublisher(ctx, request.RequestContext.DomainName, request.RequestContext.Stage)
 	if err != nil {
-		log.Printf("LangChain conversation processing failed: %v", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, fmt.Errorf("failed to create websocket publisher: %w", err)
+	}
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+
+	logger := logging.FromContext(ctx)
+
+	tokens := make(chan workflow.Token)
+	go func() {
+		if err := conversationChain.StreamConversation(streamCtx, input, tokens); err != nil {
+			logger.ErrorContext(streamCtx, "langchain streaming conversation processing failed", "error", err)
+		}
+	}()
 
-		// Send error response
-		errorResponse := types.WebSocketResponse{
-			MessageID:   "error_" + uuid.New().String()[:8],
-			Content:     "I'm sorry, I'm having trouble processing your message right now. Please try again.",
+	for token := range tokens {
+		frame := types.WebSocketResponse{
+			Content:     token.Content,
 			Persona:     wsMessage.Persona,
 			Timestamp:   time.Now(),
 			SessionID:   wsMessage.SessionID,
-			MessageType: "assistant",
+			MessageType: "assistant_delta",
+		}
+		if token.Done {
+			frame.MessageID = token.MessageID
+			frame.MessageType = "assistant_done"
 		}
 
-		return sendWebSocketResponse(ctx, connectionID, errorResponse)
-	}
-
-	// Send AI response back to client
-	response := types.WebSocketResponse{
-		MessageID:   conversationResult.MessageID,
-		Content:     conversationResult.LLMResponse,
-		Persona:     wsMessage.Persona,
-		Timestamp:   conversationResult.ProcessedAt,
-		SessionID:   wsMessage.SessionID,
-		MessageType: "assistant",
+		if err := publisher.Send(ctx, connectionID, frame); err != nil {
+			if errors.Is(err, websocket.ErrConnectionGone) {
+				logger.InfoContext(ctx, "connection is gone mid-stream, evicting", "connection_id", connectionID)
+				if delErr := connectionStore.DeleteConnection(ctx, connectionID); delErr != nil {
+					logger.ErrorContext(ctx, "failed to evict gone connection", "connection_id", connectionID, "error", delErr)
+				}
+				cancelStream()
+				return events.APIGatewayProxyResponse{StatusCode: 410}, nil
+			}
+			logger.ErrorContext(ctx, "failed to send streamed token", "connection_id", connectionID, "error", err)
+			cancelStream()
+			return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		}
 	}
 
-	return sendWebSocketResponse(ctx, connectionID, response)
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
 
 // handleDefault handles unknown WebSocket routes
@@ -223,15 +335,29 @@ func handleDefault(ctx context.Context, request events.APIGatewayWebsocketProxyR
 	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
 
-// sendWebSocketResponse sends a response message back through the WebSocket
-func sendWebSocketResponse(ctx context.Context, connectionID string, response types.WebSocketResponse) (events.APIGatewayProxyResponse, error) {
-	// Phase 2: Mock WebSocket response (log the message)
-	// Phase 3+: Use API Gateway WebSocket API to send actual message
+// sendWebSocketResponse delivers response to connectionID through the API
+// Gateway Management API for the WebSocket API that invoked this handler. If
+// the connection is gone, it evicts the row from connectionStore instead of
+// treating it as a failure.
+func sendWebSocketResponse(ctx context.Context, request events.APIGatewayWebsocketProxyRequest, connectionID string, response types.WebSocketResponse) (events.APIGatewayProxyResponse, error) {
+	publisher, err := websocket.NewAPIGatewayPublisher(ctx, request.RequestContext.DomainName, request.RequestContext.Stage)
+	if err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: 500}, fmt.Errorf("failed to create websocket publisher: %w", err)
+	}
 
-	responseJSON, _ := json.Marshal(response)
-	log.Printf("Sending WebSocket response to connection %s: %s", connectionID, string(responseJSON))
+	if err := publisher.Send(ctx, connectionID, response); err != nil {
+		if errors.Is(err, websocket.ErrConnectionGone) {
+			log.Printf("Connection %s is gone, evicting", connectionID)
+			if delErr := connectionStore.DeleteConnection(ctx, connectionID); delErr != nil {
+				log.Printf("Failed to evict gone connection %s: %v", connectionID, delErr)
+			}
+			return events.APIGatewayProxyResponse{StatusCode: 410}, nil
+		}
+		log.Printf("Failed to send WebSocket response to connection %s: %v", connectionID, err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+	}
 
-	// Mock successful response
+	responseJSON, _ := json.Marshal(response)
 	return events.APIGatewayProxyResponse{
 		StatusCode: 200,
 		Body:       string(responseJSON),
@@ -278,6 +404,8 @@ func handleDirectInvocation(ctx context.Context, wsMessage types.WebSocketMessag
 		Timestamp:   conversationResult.ProcessedAt,
 		SessionID:   wsMessage.SessionID,
 		MessageType: "assistant",
+		Timings:     conversationResult.Timings.ToWire(),
+		LLMUsage:    conversationResult.LLMUsage.ToWire(),
 	}
 
 	return response, nil
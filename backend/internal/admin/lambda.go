@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ServeLambda drives mux with an API Gateway proxy request and translates
+// the result back into a proxy response, so the same *http.ServeMux built
+// by NewMux backs both the standalone cmd/admin binary and the /admin/*
+// Lambda handler path - there's only one admin implementation to keep in
+// sync, not two.
+func ServeLambda(mux http.Handler, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	httpRequest, err := toHTTPRequest(request)
+	if err != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest, Body: err.Error()}, nil
+	}
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httpRequest)
+	result := recorder.Result()
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	headers := make(map[string]string, len(result.Header))
+	for key := range result.Header {
+		headers[key] = result.Header.Get(key)
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: result.StatusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}, nil
+}
+
+// toHTTPRequest reconstructs an *http.Request from an API Gateway proxy
+// request, including query string parameters dropped from request.Path.
+func toHTTPRequest(request events.APIGatewayProxyRequest) (*http.Request, error) {
+	values := url.Values{}
+	for key, value := range request.QueryStringParameters {
+		values.Set(key, value)
+	}
+
+	target := request.Path
+	if encoded := values.Encode(); encoded != "" {
+		target += "?" + encoded
+	}
+
+	httpRequest, err := http.NewRequest(request.HTTPMethod, target, strings.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range request.Headers {
+		httpRequest.Header.Set(key, value)
+	}
+
+	return httpRequest, nil
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestServeLambda_RoutesToMux(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"DEBUG":       "true",
+		"ADMIN_TOKEN": "shh",
+	})
+	mux := NewMux(manager, nil)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/debug/version",
+		Headers:    map[string]string{adminTokenHeader: "shh"},
+	}
+
+	response, err := ServeLambda(mux, request)
+	if err != nil {
+		t.Fatalf("ServeLambda() error = %v", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d (body: %s)", response.StatusCode, http.StatusOK, response.Body)
+	}
+}
+
+func TestServeLambda_ForbiddenWithoutToken(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"DEBUG": "true",
+	})
+	mux := NewMux(manager, nil)
+
+	request := events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/debug/version",
+	}
+
+	response, err := ServeLambda(mux, request)
+	if err != nil {
+		t.Fatalf("ServeLambda() error = %v", err)
+	}
+	if response.StatusCode != http.StatusForbidden {
+		t.Errorf("StatusCode = %d, want %d", response.StatusCode, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,113 @@
+// Package admin exposes a debug/triage HTTP surface: Go runtime profiles,
+// a redacted dump of the running config, the mock/real S3 client's view of
+// a user's GraphContext, and build info. It's gated behind cfg.Debug ||
+// !cfg.IsProduction() plus a shared-secret X-Admin-Token header, and is
+// meant to be mounted both by cmd/admin (a standalone local binary) and as
+// an alternate Lambda handler path (see lambda.go).
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/graph"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+	"strings"
+)
+
+// adminTokenHeader is the shared-secret header every /debug/* request must
+// present, compared in constant time against cfg.Admin.Token.
+const adminTokenHeader = "X-Admin-Token"
+
+// NewMux builds the admin debug mux. cfgManager is consulted on every
+// request (rather than once at startup) so a token rotated via
+// config.Manager.Reload takes effect immediately, and s3Client backs the
+// /debug/s3/users/{userID} dump.
+func NewMux(cfgManager *config.Manager, s3Client graph.S3Client) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", requireAdmin(cfgManager, pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", requireAdmin(cfgManager, pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", requireAdmin(cfgManager, pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", requireAdmin(cfgManager, pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", requireAdmin(cfgManager, pprof.Trace))
+
+	mux.HandleFunc("/debug/config", requireAdmin(cfgManager, handleConfigDump(cfgManager)))
+	mux.HandleFunc("/debug/s3/users/", requireAdmin(cfgManager, handleS3UserDump(s3Client)))
+	mux.HandleFunc("/debug/version", requireAdmin(cfgManager, handleVersion))
+
+	return mux
+}
+
+// requireAdmin gates next behind "debug mode or non-production" plus a
+// constant-time comparison of X-Admin-Token against cfg.Admin.Token, so a
+// misconfigured or blank token can never match via a timing side-channel -
+// and, since an empty Token never equals an empty header, never matches
+// when no token has been configured either.
+func requireAdmin(cfgManager *config.Manager, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := cfgManager.Current()
+
+		if !(cfg.Debug || !cfg.IsProduction()) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		token := cfg.Admin.Token
+		presented := r.Header.Get(adminTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(presented)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// handleConfigDump writes a JSON dump of the running config with every
+// known secret field masked, so it's safe to curl from staging without
+// leaking OPENROUTER_API_KEY/OPENAI_API_KEY/the admin token itself.
+func handleConfigDump(cfgManager *config.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, redact(cfgManager.Current()))
+	}
+}
+
+// handleS3UserDump dumps the GraphContext GetUserContext returns for the
+// userID path segment after /debug/s3/users/.
+func handleS3UserDump(s3Client graph.S3Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimPrefix(r.URL.Path, "/debug/s3/users/")
+		if userID == "" {
+			http.Error(w, "missing userID", http.StatusBadRequest)
+			return
+		}
+
+		graphContext, err := s3Client.GetUserContext(r.Context(), userID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, graphContext)
+	}
+}
+
+// handleVersion writes Go runtime build info, for confirming which commit
+// is actually deployed.
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "build info unavailable", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
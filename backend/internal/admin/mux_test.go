@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/graph"
+	"innerworld-backend/internal/secrets"
+)
+
+func newTestManager(t *testing.T, env map[string]string) *config.Manager {
+	t.Helper()
+	for key, value := range env {
+		os.Setenv(key, value)
+		t.Cleanup(func(key string) func() { return func() { os.Unsetenv(key) } }(key))
+	}
+
+	manager, err := config.NewManager(context.Background(), secrets.NewEnvProvider())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return manager
+}
+
+func TestDebugConfig_RedactsSecrets(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"DEBUG":              "true",
+		"ADMIN_TOKEN":        "shh",
+		"OPENROUTER_API_KEY": "or-secret",
+		"OPENAI_API_KEY":     "oa-secret",
+	})
+
+	mux := NewMux(manager, graph.NewMockS3Client())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	req.Header.Set(adminTokenHeader, "shh")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	body := recorder.Body.String()
+	for _, leaked := range []string{"or-secret", "oa-secret", "shh"} {
+		if strings.Contains(body, leaked) {
+			t.Errorf("response leaked secret %q: %s", leaked, body)
+		}
+	}
+
+	var dump map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &dump); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	openRouter, ok := dump["openrouter"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("response missing openrouter object: %v", dump)
+	}
+	if openRouter["api_key"] != redactedSecret {
+		t.Errorf("openrouter.api_key = %v, want %q", openRouter["api_key"], redactedSecret)
+	}
+}
+
+func TestRequireAdmin_RejectsWrongToken(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"DEBUG":       "true",
+		"ADMIN_TOKEN": "correct-token",
+	})
+	mux := NewMux(manager, graph.NewMockS3Client())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	req.Header.Set(adminTokenHeader, "wrong-token")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+	}
+}
+
+func TestRequireAdmin_RejectsInProductionWithoutDebug(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"ENVIRONMENT":        "production",
+		"DEBUG":              "false",
+		"ADMIN_TOKEN":        "correct-token",
+		"OPENROUTER_API_KEY": "present",
+		"OPENAI_API_KEY":     "present",
+	})
+	mux := NewMux(manager, graph.NewMockS3Client())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	req.Header.Set(adminTokenHeader, "correct-token")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestDebugS3UserDump(t *testing.T) {
+	manager := newTestManager(t, map[string]string{
+		"DEBUG":       "true",
+		"ADMIN_TOKEN": "shh",
+	})
+	mux := NewMux(manager, graph.NewMockS3Client())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/s3/users/user-123", nil)
+	req.Header.Set(adminTokenHeader, "shh")
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var dump map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &dump); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if dump["userId"] != "user-123" {
+		t.Errorf("userId = %v, want %q", dump["userId"], "user-123")
+	}
+}
@@ -0,0 +1,30 @@
+package admin
+
+import "innerworld-backend/internal/config"
+
+// redactedSecret replaces any credential field in the /debug/config dump, so
+// the JSON it produces is safe to paste into a ticket or curl from staging.
+const redactedSecret = "***REDACTED***"
+
+// redact returns a copy of cfg with every known credential field masked.
+// It's built as a plain struct copy - not a generic JSON walk - so adding a
+// new secret to Config means adding one line here rather than relying on a
+// naming convention to catch it.
+func redact(cfg *config.Config) *config.Config {
+	redacted := *cfg
+
+	if redacted.OpenRouter.APIKey != "" {
+		redacted.OpenRouter.APIKey = redactedSecret
+	}
+	if redacted.OpenAI.APIKey != "" {
+		redacted.OpenAI.APIKey = redactedSecret
+	}
+	if redacted.Anthropic.APIKey != "" {
+		redacted.Anthropic.APIKey = redactedSecret
+	}
+	if redacted.Admin.Token != "" {
+		redacted.Admin.Token = redactedSecret
+	}
+
+	return &redacted
+}
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"innerworld-backend/internal/secrets"
 )
 
 // Config holds basic application configuration for Phase 1
@@ -16,9 +21,26 @@ type Config struct {
 	// External APIs
 	OpenRouter OpenRouterConfig `json:"openrouter"`
 	OpenAI     OpenAIConfig     `json:"openai"`
+	Anthropic  AnthropicConfig  `json:"anthropic"`
+	Ollama     OllamaConfig     `json:"ollama"`
+	Embeddings EmbeddingsConfig `json:"embeddings"`
 
 	// Storage
-	S3 S3Config `json:"s3"`
+	S3       S3Config       `json:"s3"`
+	DynamoDB DynamoDBConfig `json:"dynamodb"`
+	Neptune  NeptuneConfig  `json:"neptune"`
+
+	// WebSocket connection persistence
+	WebSocket WebSocketConfig `json:"websocket"`
+
+	// Deep health-check probing
+	Health HealthConfig `json:"health"`
+
+	// Admin/debug HTTP surface
+	Admin AdminConfig `json:"admin"`
+
+	// Structured logging
+	Logging LoggingConfig `json:"logging"`
 }
 
 // OpenRouterConfig holds OpenRouter API configuration
@@ -34,32 +56,214 @@ type OpenAIConfig struct {
 	Model  string `json:"model"`
 }
 
-// S3Config holds basic S3 configuration
+// AnthropicConfig holds direct-to-Anthropic API configuration, used as an
+// llm.Router fallback candidate when OpenRouter itself is unavailable.
+type AnthropicConfig struct {
+	APIKey string `json:"api_key"`
+	Model  string `json:"model"`
+}
+
+// OllamaConfig points at a local Ollama daemon, used as an llm.Router
+// candidate for local development and integration tests so they don't
+// depend on a paid provider's API key. There's no secret to resolve here -
+// a local daemon needs no auth.
+type OllamaConfig struct {
+	BaseURL string `json:"base_url"`
+	Model   string `json:"model"`
+}
+
+// EmbeddingsConfig selects which embeddings.Provider backs GraphRAG
+// retrieval and safety moderation, so operators can swap to a local/offline
+// backend (no OpenAI API key, no per-call cost) without any call site
+// knowing which one is active.
+type EmbeddingsConfig struct {
+	// Provider is "openai" (the default) or "local".
+	Provider string `json:"provider"`
+	// Model is passed to whichever provider is selected; its meaning is
+	// provider-specific (an OpenAI model name, or a local Ollama model tag).
+	Model string `json:"model"`
+	// LocalBaseURL is the local provider's Ollama-compatible endpoint,
+	// ignored when Provider is "openai".
+	LocalBaseURL string `json:"local_base_url"`
+}
+
+// S3Config holds S3 configuration for graph context/node/edge storage
 type S3Config struct {
 	Bucket string `json:"bucket"`
 	Region string `json:"region"`
+
+	// Endpoint overrides the default AWS S3 endpoint, for local dev against
+	// e.g. a gofakes3/MinIO server. Empty uses the real AWS endpoint.
+	Endpoint string `json:"endpoint"`
+	// Profile selects a named profile from the shared AWS config/credentials
+	// files. Empty uses the default credential chain.
+	Profile string `json:"profile"`
+	// RoleARN, if set, is assumed via STS on top of Profile/the default
+	// credential chain.
+	RoleARN string `json:"role_arn"`
+	// SSEAlgorithm is the server-side encryption algorithm applied to every
+	// PutObject (e.g. "AES256" or "aws:kms"). Empty disables SSE headers.
+	SSEAlgorithm string `json:"sse_algorithm"`
+	// KeyPrefix is prepended to every object key, so multiple environments
+	// can share one bucket without colliding.
+	KeyPrefix string `json:"key_prefix"`
 }
 
-// LoadConfig loads configuration from environment variables
+// DynamoDBConfig holds storage.AWSDynamoDBClient's connection configuration.
+type DynamoDBConfig struct {
+	Region string `json:"region"`
+	// Endpoint overrides the default AWS DynamoDB endpoint, for local dev
+	// against LocalStack. Empty uses the real AWS endpoint.
+	Endpoint string `json:"endpoint"`
+
+	LiveConversationsTable     string `json:"live_conversations_table"`
+	UserContextCacheTable      string `json:"user_context_cache_table"`
+	ProcessingCheckpointsTable string `json:"processing_checkpoints_table"`
+	EmbeddingsCacheTable       string `json:"embeddings_cache_table"`
+	PromptStarterCacheTable    string `json:"prompt_starter_cache_table"`
+}
+
+// NeptuneConfig holds graph.GremlinNeptuneClient's connection configuration.
+type NeptuneConfig struct {
+	Endpoint string `json:"endpoint"`
+	Port     int    `json:"port"`
+	Region   string `json:"region"`
+	// IAMAuth signs the Gremlin websocket handshake with SigV4 (Neptune's
+	// IAM database authentication). Disable for a local
+	// tinkerpop/gremlin-server, which has no IAM concept.
+	IAMAuth bool `json:"iam_auth"`
+}
+
+// WebSocketConfig holds configuration for persisting WebSocket connection state
+type WebSocketConfig struct {
+	ConnectionsTable string `json:"connections_table"`
+}
+
+// HealthConfig controls the deep health-check's per-probe timeout and the
+// circuit breaker wrapping each dependency probe.
+type HealthConfig struct {
+	ProbeTimeout            time.Duration `json:"probe_timeout"`
+	BreakerMaxFailures      int           `json:"breaker_max_failures"`
+	BreakerFailureWindow    time.Duration `json:"breaker_failure_window"`
+	BreakerResetTimeout     time.Duration `json:"breaker_reset_timeout"`
+	BreakerSuccessesToClose int           `json:"breaker_successes_to_close"`
+
+	// PollInterval is the steady-state cadence health.Registry's background
+	// goroutines re-probe a healthy dependency at, before any failure
+	// backoff kicks in.
+	PollInterval time.Duration `json:"poll_interval"`
+}
+
+// LoggingConfig controls internal/logging's structured logger.
+type LoggingConfig struct {
+	// DebugSampleEvery keeps 1 of every N Debug-level log records, so a
+	// hot path logging at Debug per-iteration doesn't flood CloudWatch. 1
+	// (the default) disables sampling - every Debug record is kept.
+	DebugSampleEvery int `json:"debug_sample_every"`
+}
+
+// AdminConfig controls access to the internal/admin debug HTTP surface.
+type AdminConfig struct {
+	// Token is compared against the X-Admin-Token request header before any
+	// /debug/* route is served. Empty means no token can ever match, so the
+	// surface is effectively disabled until one is configured.
+	Token string `json:"-"`
+}
+
+// LoadConfig loads configuration from environment variables. API keys are
+// read as literal values; use LoadConfigWithResolver to resolve them through
+// a secrets.Provider instead (e.g. Secrets Manager or SSM references).
 func LoadConfig() (*Config, error) {
+	return LoadConfigWithResolver(context.Background(), secrets.NewEnvProvider())
+}
+
+// LoadConfigWithResolver loads configuration from environment variables,
+// resolving OPENROUTER_API_KEY, OPENAI_API_KEY, ANTHROPIC_API_KEY and
+// ADMIN_TOKEN through resolver so their env values may be literal secrets,
+// Secrets Manager ARNs, or SSM paths.
+func LoadConfigWithResolver(ctx context.Context, resolver secrets.Provider) (*Config, error) {
+	openRouterAPIKey := resolveEnvSecret(ctx, resolver, "OPENROUTER_API_KEY", "")
+	openAIAPIKey := resolveEnvSecret(ctx, resolver, "OPENAI_API_KEY", "")
+	anthropicAPIKey := resolveEnvSecret(ctx, resolver, "ANTHROPIC_API_KEY", "")
+	adminToken := resolveEnvSecret(ctx, resolver, "ADMIN_TOKEN", "")
+
 	config := &Config{
 		Environment: getEnvOrDefault("ENVIRONMENT", "development"),
 		Debug:       getEnvAsBool("DEBUG", false),
 
 		OpenRouter: OpenRouterConfig{
-			APIKey:  getEnvOrDefault("OPENROUTER_API_KEY", ""),
+			APIKey:  openRouterAPIKey,
 			BaseURL: getEnvOrDefault("OPENROUTER_BASE_URL", "https://openrouter.ai/api/v1"),
 			Model:   getEnvOrDefault("OPENROUTER_MODEL", "openai/gpt-4o-mini"),
 		},
 
 		OpenAI: OpenAIConfig{
-			APIKey: getEnvOrDefault("OPENAI_API_KEY", ""),
+			APIKey: openAIAPIKey,
 			Model:  getEnvOrDefault("OPENAI_MODEL", "text-embedding-3-small"),
 		},
 
+		Anthropic: AnthropicConfig{
+			APIKey: anthropicAPIKey,
+			Model:  getEnvOrDefault("ANTHROPIC_MODEL", "claude-3-5-sonnet-20241022"),
+		},
+
+		Ollama: OllamaConfig{
+			BaseURL: getEnvOrDefault("OLLAMA_BASE_URL", "http://localhost:11434"),
+			Model:   getEnvOrDefault("OLLAMA_MODEL", "llama3.2"),
+		},
+
+		Embeddings: EmbeddingsConfig{
+			Provider:     getEnvOrDefault("EMBEDDINGS_PROVIDER", "openai"),
+			Model:        getEnvOrDefault("EMBEDDINGS_MODEL", ""),
+			LocalBaseURL: getEnvOrDefault("EMBEDDINGS_LOCAL_BASE_URL", ""),
+		},
+
 		S3: S3Config{
-			Bucket: getEnvOrDefault("S3_BUCKET", "innerworld-dev-bucket"),
-			Region: getEnvOrDefault("S3_REGION", "us-west-2"),
+			Bucket:       getEnvOrDefault("S3_BUCKET", "innerworld-dev-bucket"),
+			Region:       getEnvOrDefault("S3_REGION", "us-west-2"),
+			Endpoint:     getEnvOrDefault("S3_ENDPOINT", ""),
+			Profile:      getEnvOrDefault("S3_PROFILE", ""),
+			RoleARN:      getEnvOrDefault("S3_ROLE_ARN", ""),
+			SSEAlgorithm: getEnvOrDefault("S3_SSE_ALGORITHM", ""),
+			KeyPrefix:    getEnvOrDefault("S3_KEY_PREFIX", ""),
+		},
+
+		DynamoDB: DynamoDBConfig{
+			Region:                     getEnvOrDefault("DYNAMODB_REGION", "us-west-2"),
+			Endpoint:                   getEnvOrDefault("DYNAMODB_ENDPOINT", ""),
+			LiveConversationsTable:     getEnvOrDefault("DYNAMODB_LIVE_CONVERSATIONS_TABLE", "innerworld-dev-live-conversations"),
+			UserContextCacheTable:      getEnvOrDefault("DYNAMODB_USER_CONTEXT_CACHE_TABLE", "innerworld-dev-user-context-cache"),
+			ProcessingCheckpointsTable: getEnvOrDefault("DYNAMODB_PROCESSING_CHECKPOINTS_TABLE", "innerworld-dev-processing-checkpoints"),
+			EmbeddingsCacheTable:       getEnvOrDefault("DYNAMODB_EMBEDDINGS_CACHE_TABLE", "innerworld-dev-embeddings-cache"),
+			PromptStarterCacheTable:    getEnvOrDefault("DYNAMODB_PROMPT_STARTER_CACHE_TABLE", "innerworld-dev-prompt-starter-cache"),
+		},
+
+		Neptune: NeptuneConfig{
+			Endpoint: getEnvOrDefault("NEPTUNE_ENDPOINT", ""),
+			Port:     getEnvAsInt("NEPTUNE_PORT", 8182),
+			Region:   getEnvOrDefault("NEPTUNE_REGION", "us-west-2"),
+			IAMAuth:  getEnvAsBool("NEPTUNE_IAM_AUTH", true),
+		},
+
+		WebSocket: WebSocketConfig{
+			ConnectionsTable: getEnvOrDefault("WEBSOCKET_CONNECTIONS_TABLE", "innerworld-dev-websocket-connections"),
+		},
+
+		Health: HealthConfig{
+			ProbeTimeout:            getEnvAsDuration("HEALTH_PROBE_TIMEOUT", 5*time.Second),
+			BreakerMaxFailures:      getEnvAsInt("HEALTH_BREAKER_MAX_FAILURES", 3),
+			BreakerFailureWindow:    getEnvAsDuration("HEALTH_BREAKER_FAILURE_WINDOW", 1*time.Minute),
+			BreakerResetTimeout:     getEnvAsDuration("HEALTH_BREAKER_RESET_TIMEOUT", 30*time.Second),
+			BreakerSuccessesToClose: getEnvAsInt("HEALTH_BREAKER_SUCCESSES_TO_CLOSE", 2),
+			PollInterval:            getEnvAsDuration("HEALTH_POLL_INTERVAL", 15*time.Second),
+		},
+
+		Admin: AdminConfig{
+			Token: adminToken,
+		},
+
+		Logging: LoggingConfig{
+			DebugSampleEvery: getEnvAsInt("LOG_DEBUG_SAMPLE_EVERY", 1),
 		},
 	}
 
@@ -93,6 +297,10 @@ func validateConfig(config *Config) error {
 		errors = append(errors, "S3_REGION is required")
 	}
 
+	if config.Neptune.Port <= 0 {
+		errors = append(errors, "NEPTUNE_PORT must be a positive port number")
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration errors:\n%s", strings.Join(errors, "\n"))
 	}
@@ -128,6 +336,33 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
+
+// resolveEnvSecret reads key's raw value (falling back to defaultValue) and
+// resolves it through resolver. A resolve error is logged and the raw value
+// is used as-is, so a misconfigured secret reference degrades to "treat it
+// as a literal" rather than failing config load outright.
+func resolveEnvSecret(ctx context.Context, resolver secrets.Provider, key, defaultValue string) string {
+	ref := getEnvOrDefault(key, defaultValue)
+	if ref == "" {
+		return ref
+	}
+
+	value, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		log.Printf("config: failed to resolve %s, using raw value: %v", key, err)
+		return ref
+	}
+	return value
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
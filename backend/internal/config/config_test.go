@@ -96,6 +96,10 @@ func TestValidateConfigDevelopment(t *testing.T) {
 		OpenAI: OpenAIConfig{
 			APIKey: "",
 		},
+		S3: S3Config{
+			Bucket: "innerworld-dev-bucket",
+			Region: "us-west-2",
+		},
 		Neptune: NeptuneConfig{
 			Port: 8182,
 		},
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"innerworld-backend/internal/secrets"
+)
+
+// Manager holds a live Config behind an atomic pointer, so long-running
+// processes can pick up a rotated secret (or any other env change) via
+// Reload without in-flight requests ever observing a half-updated Config.
+type Manager struct {
+	resolver secrets.Provider
+	current  atomic.Pointer[Config]
+}
+
+// NewManager builds a Manager and performs an initial Reload. It returns an
+// error if that first load fails, mirroring LoadConfigWithResolver.
+func NewManager(ctx context.Context, resolver secrets.Provider) (*Manager, error) {
+	m := &Manager{resolver: resolver}
+	if err := m.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Current returns the most recently loaded Config. Safe for concurrent use.
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// Reload re-reads environment variables (re-resolving secret references via
+// the Manager's resolver) and, if the result validates, atomically swaps it
+// in. A failed reload leaves the previously loaded Config in place.
+func (m *Manager) Reload(ctx context.Context) error {
+	cfg, err := LoadConfigWithResolver(ctx, m.resolver)
+	if err != nil {
+		return fmt.Errorf("config: reload failed: %w", err)
+	}
+	m.current.Store(cfg)
+	return nil
+}
+
+// WatchSIGHUP reloads the Manager's Config on every SIGHUP, until ctx is
+// done. It's intended for long-lived, non-Lambda binaries; Lambda functions
+// don't receive signals and should rely on cold starts or an explicit Reload
+// instead. Reload failures are logged rather than propagated, so a transient
+// secret-store outage doesn't take down an otherwise-healthy process.
+func (m *Manager) WatchSIGHUP(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if err := m.Reload(ctx); err != nil {
+					log.Printf("config: SIGHUP reload failed: %v", err)
+				} else {
+					log.Printf("config: reloaded on SIGHUP")
+				}
+			}
+		}
+	}()
+}
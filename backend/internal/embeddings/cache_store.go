@@ -0,0 +1,138 @@
+package embeddings
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"innerworld-backend/internal/storage"
+	"innerworld-backend/internal/types"
+)
+
+// ErrCacheMiss is returned by CacheStore.Get when key isn't cached yet -
+// the normal state for text CachingEmbeddingsClient hasn't seen before, not
+// a failure.
+var ErrCacheMiss = errors.New("embeddings: cache miss")
+
+// CacheStore is a pluggable backing store for CachingEmbeddingsClient. Get
+// returns ErrCacheMiss (wrapped or bare, checked via errors.Is) rather than
+// a zero value when key isn't present.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*EmbeddingResult, error)
+	Set(ctx context.Context, key string, result *EmbeddingResult) error
+}
+
+// CacheKey hashes model and text into the key CachingEmbeddingsClient looks
+// its cache up by - SHA-256 so two different texts never collide into the
+// same cache entry, and so the key has a fixed, short length regardless of
+// how long text is.
+func CacheKey(model, text string) string {
+	sum := sha256.Sum256([]byte(model + "||" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCacheStore is an in-process CacheStore bounded to maxEntries via
+// least-recently-used eviction, for a single Lambda container's lifetime.
+// It's the default CachingEmbeddingsClient store when no longer-lived
+// DynamoDBCacheStore is configured.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // front = most recently used
+}
+
+type memoryCacheEntry struct {
+	key    string
+	result *EmbeddingResult
+}
+
+// NewMemoryCacheStore creates an LRU store holding at most maxEntries
+// embeddings. maxEntries <= 0 falls back to a default of 10,000.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = 10_000
+	}
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns ErrCacheMiss if key hasn't been cached yet.
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) (*EmbeddingResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).result, nil
+}
+
+// Set stores result under key, evicting the least-recently-used entry if
+// this would push the store past maxEntries.
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, result *EmbeddingResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).result = result
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	elem := s.order.PushFront(&memoryCacheEntry{key: key, result: result})
+	s.entries[key] = elem
+
+	if s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+// DynamoDBCacheStore persists embeddings via a storage.DynamoDBClient, so a
+// cache entry survives a Lambda container recycling (unlike
+// MemoryCacheStore) at the cost of a network round-trip per lookup.
+type DynamoDBCacheStore struct {
+	client storage.DynamoDBClient
+}
+
+// NewDynamoDBCacheStore creates a store backed by client.
+func NewDynamoDBCacheStore(client storage.DynamoDBClient) *DynamoDBCacheStore {
+	return &DynamoDBCacheStore{client: client}
+}
+
+// Get returns ErrCacheMiss if key hasn't been cached yet.
+func (s *DynamoDBCacheStore) Get(ctx context.Context, key string) (*EmbeddingResult, error) {
+	item, err := s.client.GetEmbeddingCache(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrCacheMiss
+	}
+	return &EmbeddingResult{
+		Embedding: item.Embedding,
+		Model:     item.Model,
+		Tokens:    item.Tokens,
+	}, nil
+}
+
+// Set stores result under key, overwriting any previous entry.
+func (s *DynamoDBCacheStore) Set(ctx context.Context, key string, result *EmbeddingResult) error {
+	return s.client.SaveEmbeddingCache(ctx, &types.EmbeddingCacheItem{
+		CacheKey:  key,
+		Model:     result.Model,
+		Embedding: result.Embedding,
+		Tokens:    result.Tokens,
+	})
+}
@@ -0,0 +1,246 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultBatchMaxSize bounds how many pending GenerateEmbedding calls a
+// single flush packs into one GenerateBatchEmbeddings request to the
+// wrapped Provider.
+const defaultBatchMaxSize = 100
+
+// defaultBatchMaxWait is how long a pending GenerateEmbedding call waits
+// for more callers to join its batch before flushing anyway.
+const defaultBatchMaxWait = 20 * time.Millisecond
+
+// CachingEmbeddingsClient wraps a Provider with a cache (in-memory LRU by
+// default, or a longer-lived DynamoDBCacheStore), in-flight request
+// coalescing, and automatic batching of single-text calls - so GraphRAG
+// retrieval and safety moderation, which both call GenerateEmbedding one
+// text at a time, stop paying for (and waiting on) the wrapped Provider on
+// every repeated or concurrent call.
+type CachingEmbeddingsClient struct {
+	provider Provider
+	store    CacheStore
+	group    singleflight.Group
+
+	batchMaxSize int
+	batchMaxWait time.Duration
+
+	mu      sync.Mutex
+	pending []pendingEmbedding
+	timer   *time.Timer
+}
+
+// pendingEmbedding is one caller's still-unflushed GenerateEmbedding call,
+// waiting to be folded into the next batch.
+type pendingEmbedding struct {
+	text   string
+	result chan pendingEmbeddingResult
+}
+
+type pendingEmbeddingResult struct {
+	embedding *EmbeddingResult
+	err       error
+}
+
+// NewCachingEmbeddingsClient wraps provider with store. A nil store
+// defaults to an in-memory LRU (NewMemoryCacheStore with its default
+// capacity).
+func NewCachingEmbeddingsClient(provider Provider, store CacheStore) *CachingEmbeddingsClient {
+	if store == nil {
+		store = NewMemoryCacheStore(0)
+	}
+	return &CachingEmbeddingsClient{
+		provider:     provider,
+		store:        store,
+		batchMaxSize: defaultBatchMaxSize,
+		batchMaxWait: defaultBatchMaxWait,
+	}
+}
+
+// SetBatchWindow overrides the batch size/latency window single
+// GenerateEmbedding calls are packed under before flushing to the wrapped
+// Provider. maxSize <= 0 or maxWait <= 0 leave the corresponding default in
+// place.
+func (c *CachingEmbeddingsClient) SetBatchWindow(maxSize int, maxWait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxSize > 0 {
+		c.batchMaxSize = maxSize
+	}
+	if maxWait > 0 {
+		c.batchMaxWait = maxWait
+	}
+}
+
+// Dimension delegates to the wrapped Provider.
+func (c *CachingEmbeddingsClient) Dimension() int {
+	return c.provider.Dimension()
+}
+
+// Model delegates to the wrapped Provider.
+func (c *CachingEmbeddingsClient) Model() string {
+	return c.provider.Model()
+}
+
+// GenerateEmbedding returns text's embedding from cache if present;
+// otherwise it's folded into the next pending batch (see flush), and
+// concurrent callers requesting the same uncached text share a single
+// underlying batch slot via singleflight rather than each enqueuing their
+// own.
+func (c *CachingEmbeddingsClient) GenerateEmbedding(ctx context.Context, text string) (*EmbeddingResult, error) {
+	key := CacheKey(c.provider.Model(), text)
+
+	if cached, err := c.store.Get(ctx, key); err == nil {
+		result := *cached
+		result.Text = text
+		return &result, nil
+	} else if !errors.Is(err, ErrCacheMiss) {
+		// A cache read failure (e.g. DynamoDB unreachable) degrades to a
+		// live call rather than failing the request outright.
+		return c.generateLive(ctx, key, text)
+	}
+
+	resultAny, err, _ := c.group.Do(key, func() (interface{}, error) {
+		return c.enqueue(ctx, text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := *(resultAny.(*EmbeddingResult))
+	result.Text = text
+	if err := c.store.Set(ctx, key, &result); err != nil {
+		return nil, fmt.Errorf("failed to cache embedding: %w", err)
+	}
+	return &result, nil
+}
+
+// generateLive calls the wrapped Provider directly and best-effort
+// refreshes the cache, used when a cache read itself failed rather than
+// simply missed.
+func (c *CachingEmbeddingsClient) generateLive(ctx context.Context, key, text string) (*EmbeddingResult, error) {
+	result, err := c.provider.GenerateEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.store.Set(ctx, key, result)
+	return result, nil
+}
+
+// enqueue adds text to the pending batch, starting the flush timer if it's
+// the first pending item, and blocks until that batch flushes.
+func (c *CachingEmbeddingsClient) enqueue(ctx context.Context, text string) (*EmbeddingResult, error) {
+	pending := pendingEmbedding{text: text, result: make(chan pendingEmbeddingResult, 1)}
+
+	c.mu.Lock()
+	c.pending = append(c.pending, pending)
+	shouldFlushNow := len(c.pending) >= c.batchMaxSize
+	if shouldFlushNow {
+		if c.timer != nil {
+			c.timer.Stop()
+			c.timer = nil
+		}
+	} else if c.timer == nil {
+		c.timer = time.AfterFunc(c.batchMaxWait, func() { c.flush(context.Background()) })
+	}
+	c.mu.Unlock()
+
+	if shouldFlushNow {
+		c.flush(ctx)
+	}
+
+	select {
+	case res := <-pending.result:
+		return res.embedding, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush takes every currently-pending call, sends it to the wrapped
+// Provider as one GenerateBatchEmbeddings request, and delivers each
+// caller its result. A no-op if another goroutine already flushed (e.g.
+// the size trigger raced the timer).
+func (c *CachingEmbeddingsClient) flush(ctx context.Context) {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.timer = nil
+	c.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	texts := make([]string, len(batch))
+	for i, p := range batch {
+		texts[i] = p.text
+	}
+
+	results, err := c.provider.GenerateBatchEmbeddings(ctx, texts)
+	if err != nil {
+		for _, p := range batch {
+			p.result <- pendingEmbeddingResult{err: err}
+		}
+		return
+	}
+
+	for i, p := range batch {
+		p.result <- pendingEmbeddingResult{embedding: results[i]}
+	}
+}
+
+// GenerateBatchEmbeddings looks each text up in cache, then sends whatever
+// misses remain to the wrapped Provider as a single batch (bypassing the
+// pending-call batcher, since the caller has already done the batching
+// itself).
+func (c *CachingEmbeddingsClient) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*EmbeddingResult, error) {
+	if len(texts) == 0 {
+		return []*EmbeddingResult{}, nil
+	}
+
+	results := make([]*EmbeddingResult, len(texts))
+	var missTexts []string
+	var missIndexes []int
+
+	model := c.provider.Model()
+	for i, text := range texts {
+		key := CacheKey(model, text)
+		cached, err := c.store.Get(ctx, key)
+		if err != nil {
+			missTexts = append(missTexts, text)
+			missIndexes = append(missIndexes, i)
+			continue
+		}
+		result := *cached
+		result.Text = text
+		results[i] = &result
+	}
+
+	if len(missTexts) == 0 {
+		return results, nil
+	}
+
+	fresh, err := c.provider.GenerateBatchEmbeddings(ctx, missTexts)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, idx := range missIndexes {
+		results[idx] = fresh[j]
+		key := CacheKey(model, texts[idx])
+		if err := c.store.Set(ctx, key, fresh[j]); err != nil {
+			return nil, fmt.Errorf("failed to cache embedding: %w", err)
+		}
+	}
+
+	return results, nil
+}
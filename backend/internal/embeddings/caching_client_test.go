@@ -0,0 +1,179 @@
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProvider is a minimal Provider used to assert CachingEmbeddingsClient's
+// caching/coalescing/batching behavior without a real OpenAI/local backend.
+type fakeProvider struct {
+	mu         sync.Mutex
+	batchCalls int32
+	batchSizes []int
+	embedding  []float32
+	err        error
+}
+
+func (p *fakeProvider) GenerateEmbedding(ctx context.Context, text string) (*EmbeddingResult, error) {
+	results, err := p.GenerateBatchEmbeddings(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (p *fakeProvider) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*EmbeddingResult, error) {
+	atomic.AddInt32(&p.batchCalls, 1)
+	p.mu.Lock()
+	p.batchSizes = append(p.batchSizes, len(texts))
+	p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	results := make([]*EmbeddingResult, len(texts))
+	for i, text := range texts {
+		results[i] = &EmbeddingResult{Text: text, Embedding: p.embedding, Model: p.Model(), Tokens: 1}
+	}
+	return results, nil
+}
+
+func (p *fakeProvider) Dimension() int { return len(p.embedding) }
+func (p *fakeProvider) Model() string  { return "fake-model" }
+
+func TestCachingEmbeddingsClientCachesResults(t *testing.T) {
+	provider := &fakeProvider{embedding: []float32{1, 2, 3}}
+	client := NewCachingEmbeddingsClient(provider, nil)
+
+	ctx := context.Background()
+	if _, err := client.GenerateEmbedding(ctx, "hello"); err != nil {
+		t.Fatalf("GenerateEmbedding failed: %v", err)
+	}
+	if _, err := client.GenerateEmbedding(ctx, "hello"); err != nil {
+		t.Fatalf("GenerateEmbedding failed: %v", err)
+	}
+
+	if calls := atomic.LoadInt32(&provider.batchCalls); calls != 1 {
+		t.Errorf("Expected the wrapped provider to be called once for a repeated text, got %d calls", calls)
+	}
+}
+
+func TestCachingEmbeddingsClientCoalescesConcurrentCalls(t *testing.T) {
+	provider := &fakeProvider{embedding: []float32{1, 2, 3}}
+	client := NewCachingEmbeddingsClient(provider, nil)
+	client.SetBatchWindow(100, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := client.GenerateEmbedding(context.Background(), "same text")
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("call %d failed: %v", i, err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&provider.batchCalls); calls != 1 {
+		t.Errorf("Expected concurrent calls for the same text to coalesce into 1 provider call, got %d", calls)
+	}
+}
+
+func TestCachingEmbeddingsClientBatchesBySize(t *testing.T) {
+	provider := &fakeProvider{embedding: []float32{1, 2, 3}}
+	client := NewCachingEmbeddingsClient(provider, nil)
+	client.SetBatchWindow(3, time.Second) // long wait so only the size trigger can flush
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client.GenerateEmbedding(context.Background(), fmt.Sprintf("text-%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&provider.batchCalls); calls != 1 {
+		t.Errorf("Expected 3 distinct texts hitting batchMaxSize to flush as 1 provider call, got %d", calls)
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.batchSizes) != 1 || provider.batchSizes[0] != 3 {
+		t.Errorf("Expected a single batch of size 3, got %v", provider.batchSizes)
+	}
+}
+
+func TestCachingEmbeddingsClientGenerateBatchEmbeddingsUsesCache(t *testing.T) {
+	provider := &fakeProvider{embedding: []float32{1, 2, 3}}
+	client := NewCachingEmbeddingsClient(provider, nil)
+
+	ctx := context.Background()
+	if _, err := client.GenerateEmbedding(ctx, "cached"); err != nil {
+		t.Fatalf("GenerateEmbedding failed: %v", err)
+	}
+	initialCalls := atomic.LoadInt32(&provider.batchCalls)
+
+	results, err := client.GenerateBatchEmbeddings(ctx, []string{"cached", "fresh"})
+	if err != nil {
+		t.Fatalf("GenerateBatchEmbeddings failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Text != "cached" || results[1].Text != "fresh" {
+		t.Errorf("Expected results in input order, got %+v", results)
+	}
+
+	provider.mu.Lock()
+	lastBatch := provider.batchSizes[len(provider.batchSizes)-1]
+	provider.mu.Unlock()
+	if lastBatch != 1 {
+		t.Errorf("Expected only the uncached text to reach the provider, got a batch of size %d", lastBatch)
+	}
+	if atomic.LoadInt32(&provider.batchCalls) != initialCalls+1 {
+		t.Errorf("Expected exactly one additional provider call for the cache miss")
+	}
+}
+
+func TestMemoryCacheStoreEviction(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	ctx := context.Background()
+
+	store.Set(ctx, "a", &EmbeddingResult{Text: "a"})
+	store.Set(ctx, "b", &EmbeddingResult{Text: "b"})
+	store.Set(ctx, "c", &EmbeddingResult{Text: "c"}) // evicts "a", the least recently used
+
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected 'a' to be evicted, got err=%v", err)
+	}
+	if _, err := store.Get(ctx, "b"); err != nil {
+		t.Errorf("Expected 'b' to still be cached, got err=%v", err)
+	}
+	if _, err := store.Get(ctx, "c"); err != nil {
+		t.Errorf("Expected 'c' to be cached, got err=%v", err)
+	}
+}
+
+func TestCacheKeyDiffersByModelAndText(t *testing.T) {
+	if CacheKey("model-a", "text") == CacheKey("model-b", "text") {
+		t.Error("Expected different models to produce different cache keys for the same text")
+	}
+	if CacheKey("model-a", "text-1") == CacheKey("model-a", "text-2") {
+		t.Error("Expected different texts to produce different cache keys for the same model")
+	}
+}
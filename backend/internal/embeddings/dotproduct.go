@@ -0,0 +1,35 @@
+package embeddings
+
+// dotProductImpl is the dot-product kernel VectorIndex.TopK scores every
+// row with. It's a package variable rather than a direct call so an
+// architecture-specific build (an AVX2/NEON assembly kernel, gated behind
+// its own build-tagged file) can override it from an init() without
+// VectorIndex itself needing to know which implementation is active. No
+// such file exists in this tree yet - dotProductImpl is always
+// dotProductPortable - so this is the seam for one, not a claim that SIMD
+// is already wired up.
+var dotProductImpl = dotProductPortable
+
+// dotProduct scores a and b, both length idx.dim, via whichever kernel is
+// currently installed.
+func dotProduct(a, b []float32) float32 {
+	return dotProductImpl(a, b)
+}
+
+// dotProductPortable is a loop-unrolled (x8) pure-Go dot product. Go's
+// compiler auto-vectorizes this shape reasonably well on amd64/arm64
+// without needing hand-written assembly, and it's correct on every
+// architecture Go supports.
+func dotProductPortable(a, b []float32) float32 {
+	var sum float32
+	n := len(a)
+	i := 0
+	for ; i+8 <= n; i += 8 {
+		sum += a[i]*b[i] + a[i+1]*b[i+1] + a[i+2]*b[i+2] + a[i+3]*b[i+3] +
+			a[i+4]*b[i+4] + a[i+5]*b[i+5] + a[i+6]*b[i+6] + a[i+7]*b[i+7]
+	}
+	for ; i < n; i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,145 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// defaultLocalBaseURL points at a local Ollama daemon's default port,
+// mirroring llm.defaultOllamaBaseURL.
+const defaultLocalBaseURL = "http://localhost:11434"
+
+// defaultLocalModel is a small embedding model available on most local
+// Ollama installs.
+const defaultLocalModel = "nomic-embed-text"
+
+// defaultLocalDimension is nomic-embed-text's output dimension. Callers
+// serving a different local model should set SetDimension accordingly.
+const defaultLocalDimension = 768
+
+// LocalEmbeddingsClient calls a local Ollama daemon's embeddings API,
+// used in place of OpenAI's hosted API for local development and
+// self-hosted deployments so neither needs an OpenAI API key or incurs
+// per-call cost.
+type LocalEmbeddingsClient struct {
+	baseURL    string
+	model      string
+	dimension  int
+	httpClient *http.Client
+}
+
+// NewLocalEmbeddingsClient creates a client against baseURL using model. An
+// empty baseURL/model fall back to defaultLocalBaseURL/defaultLocalModel.
+func NewLocalEmbeddingsClient(baseURL, model string) *LocalEmbeddingsClient {
+	if baseURL == "" {
+		baseURL = defaultLocalBaseURL
+	}
+	if model == "" {
+		model = defaultLocalModel
+	}
+	return &LocalEmbeddingsClient{
+		baseURL:   baseURL,
+		model:     model,
+		dimension: defaultLocalDimension,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetDimension overrides the dimension Dimension() reports, for a local
+// model other than defaultLocalModel whose output size this client has no
+// other way to know ahead of the first call.
+func (e *LocalEmbeddingsClient) SetDimension(dimension int) {
+	e.dimension = dimension
+}
+
+type localEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+type localEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// GenerateEmbedding creates an embedding for a single text via Ollama's
+// /api/embeddings, which - unlike its /api/chat - only accepts one prompt
+// per request.
+func (e *LocalEmbeddingsClient) GenerateEmbedding(ctx context.Context, text string) (*EmbeddingResult, error) {
+	log.Printf("[EMBEDDINGS] Generating embedding for text via local endpoint: %s...", text[:min(50, len(text))])
+
+	payload, err := json.Marshal(localEmbeddingRequest{Model: e.model, Prompt: text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal local embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.baseURL+"/api/embeddings", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create local embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("local embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("local embeddings API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result localEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode local embeddings response: %w", err)
+	}
+
+	embedding := &EmbeddingResult{
+		Text:      text,
+		Embedding: result.Embedding,
+		Model:     e.model,
+	}
+
+	log.Printf("[EMBEDDINGS] Generated %d-dimensional local embedding", len(embedding.Embedding))
+
+	return embedding, nil
+}
+
+// GenerateBatchEmbeddings creates embeddings for multiple texts, one
+// request per text - Ollama's /api/embeddings has no batch form.
+func (e *LocalEmbeddingsClient) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*EmbeddingResult, error) {
+	if len(texts) == 0 {
+		return []*EmbeddingResult{}, nil
+	}
+
+	log.Printf("[EMBEDDINGS] Generating %d local embeddings", len(texts))
+
+	results := make([]*EmbeddingResult, len(texts))
+	for i, text := range texts {
+		result, err := e.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embedding %d/%d: %w", i+1, len(texts), err)
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// Dimension returns the length of vectors this client's model produces.
+func (e *LocalEmbeddingsClient) Dimension() int {
+	return e.dimension
+}
+
+// Model returns the local model tag currently in use.
+func (e *LocalEmbeddingsClient) Model() string {
+	return e.model
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -85,13 +86,15 @@ func (e *OpenAIEmbeddingsClient) GenerateBatchEmbeddings(ctx context.Context, te
 		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
 	}
 
+	perItemTokens := estimateTokensPerInput(texts, resp.Usage.TotalTokens)
+
 	results := make([]*EmbeddingResult, len(texts))
 	for i, data := range resp.Data {
 		results[i] = &EmbeddingResult{
 			Text:      texts[i],
 			Embedding: data.Embedding,
-			Model:     e.model,                // Use our internal model string
-			Tokens:    resp.Usage.TotalTokens, // Note: this is total for batch
+			Model:     e.model, // Use our internal model string
+			Tokens:    perItemTokens[i],
 		}
 	}
 
@@ -119,6 +122,21 @@ func (e *OpenAIEmbeddingsClient) EmbedUserQuery(ctx context.Context, query strin
 
 // CalculateCosineSimilarity calculates similarity between two embeddings
 func (e *OpenAIEmbeddingsClient) CalculateCosineSimilarity(embedding1, embedding2 []float32) float32 {
+	return CalculateCosineSimilarity(embedding1, embedding2)
+}
+
+// FindMostSimilar finds the most similar embedding from a set
+func (e *OpenAIEmbeddingsClient) FindMostSimilar(queryEmbedding []float32, candidates []*EmbeddingResult) (*EmbeddingResult, float32) {
+	return FindMostSimilar(queryEmbedding, candidates)
+}
+
+// CalculateCosineSimilarity calculates cosine similarity between two
+// embedding vectors. It's a package-level function, not a Provider method,
+// since comparing vectors is independent of whichever Provider produced
+// them (a caller may compare an OpenAI query embedding against local
+// candidate embeddings, though in practice candidates should come from the
+// same provider a query did).
+func CalculateCosineSimilarity(embedding1, embedding2 []float32) float32 {
 	if len(embedding1) != len(embedding2) {
 		return 0.0
 	}
@@ -138,8 +156,9 @@ func (e *OpenAIEmbeddingsClient) CalculateCosineSimilarity(embedding1, embedding
 	return dotProduct / (sqrt32(norm1) * sqrt32(norm2))
 }
 
-// FindMostSimilar finds the most similar embedding from a set
-func (e *OpenAIEmbeddingsClient) FindMostSimilar(queryEmbedding []float32, candidates []*EmbeddingResult) (*EmbeddingResult, float32) {
+// FindMostSimilar finds the candidate with the highest cosine similarity to
+// queryEmbedding.
+func FindMostSimilar(queryEmbedding []float32, candidates []*EmbeddingResult) (*EmbeddingResult, float32) {
 	if len(candidates) == 0 {
 		return nil, 0.0
 	}
@@ -148,7 +167,7 @@ func (e *OpenAIEmbeddingsClient) FindMostSimilar(queryEmbedding []float32, candi
 	var bestScore float32 = -1.0
 
 	for _, candidate := range candidates {
-		similarity := e.CalculateCosineSimilarity(queryEmbedding, candidate.Embedding)
+		similarity := CalculateCosineSimilarity(queryEmbedding, candidate.Embedding)
 		if similarity > bestScore {
 			bestScore = similarity
 			bestMatch = candidate
@@ -175,16 +194,63 @@ func (e *OpenAIEmbeddingsClient) SetModel(model string) {
 	log.Printf("[EMBEDDINGS] Changed model to: %s", model)
 }
 
-// Helper function for square root of float32
-func sqrt32(x float32) float32 {
-	// Simple Newton-Raphson method for square root
-	if x == 0 {
-		return 0
+// Dimension returns the length of vectors this client's model produces,
+// satisfying Provider alongside the pre-existing GetEmbeddingDimension.
+func (e *OpenAIEmbeddingsClient) Dimension() int {
+	return e.GetEmbeddingDimension()
+}
+
+// Model returns the OpenAI model currently in use, satisfying Provider
+// alongside the pre-existing GetModel.
+func (e *OpenAIEmbeddingsClient) Model() string {
+	return e.GetModel()
+}
+
+// estimateTokensPerInput splits totalTokens (the batch's combined usage,
+// all the OpenAI API reports) across texts proportional to each input's
+// length, so a caller billing per-item isn't attributed the full batch's
+// token count for every item in it. It's an estimate, not an exact count -
+// OpenAI's tokenizer doesn't split proportionally to character count - but
+// it's far closer than repeating the batch total per item, and the
+// estimates still sum to totalTokens.
+func estimateTokensPerInput(texts []string, totalTokens int) []int {
+	tokens := make([]int, len(texts))
+	if len(texts) == 0 || totalTokens == 0 {
+		return tokens
+	}
+
+	totalChars := 0
+	for _, text := range texts {
+		totalChars += len(text)
+	}
+	if totalChars == 0 {
+		return tokens
 	}
 
-	guess := x / 2
-	for i := 0; i < 10; i++ { // 10 iterations should be enough for float32
-		guess = (guess + x/guess) / 2
+	assigned := 0
+	for i, text := range texts {
+		tokens[i] = totalTokens * len(text) / totalChars
+		assigned += tokens[i]
 	}
-	return guess
+	// Integer division leaves a remainder; attribute it to the largest
+	// input so the estimates still sum to totalTokens exactly.
+	if remainder := totalTokens - assigned; remainder != 0 {
+		largest := 0
+		for i := range texts {
+			if len(texts[i]) > len(texts[largest]) {
+				largest = i
+			}
+		}
+		tokens[largest] += remainder
+	}
+
+	return tokens
+}
+
+// sqrt32 returns the square root of x. It used to be a hand-rolled
+// Newton-Raphson loop; math.Sqrt's hardware-backed implementation is both
+// faster and exact to float32 precision, so it's used instead via a
+// float64 round-trip.
+func sqrt32(x float32) float32 {
+	return float32(math.Sqrt(float64(x)))
 }
@@ -0,0 +1,49 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+
+	"innerworld-backend/internal/config"
+)
+
+// Provider is satisfied by any embeddings backend - OpenAI's hosted API or
+// a local/offline one - so callers that only need vector embeddings
+// (GraphRAG retrieval, safety moderation) can depend on this interface
+// instead of a concrete client, with the active backend picked by config
+// rather than hardcoded at each call site.
+type Provider interface {
+	GenerateEmbedding(ctx context.Context, text string) (*EmbeddingResult, error)
+	GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*EmbeddingResult, error)
+
+	// Dimension returns the length of vectors this provider produces.
+	Dimension() int
+	// Model returns the model name/tag this provider is currently using.
+	Model() string
+}
+
+var (
+	_ Provider = (*OpenAIEmbeddingsClient)(nil)
+	_ Provider = (*LocalEmbeddingsClient)(nil)
+	_ Provider = (*CachingEmbeddingsClient)(nil)
+)
+
+// NewProviderFromConfig builds the Provider cfg.Provider selects: "openai"
+// (the default, talking to OpenAI's hosted API with apiKey) or "local" (a
+// self-hosted Ollama daemon's /api/embeddings endpoint, needing no API
+// key). Operators switch backends per environment by setting
+// EMBEDDINGS_PROVIDER, without any call site knowing which one is active.
+func NewProviderFromConfig(cfg config.EmbeddingsConfig, apiKey string) (Provider, error) {
+	switch cfg.Provider {
+	case "", "openai":
+		client := NewOpenAIEmbeddingsClient(apiKey)
+		if cfg.Model != "" {
+			client.SetModel(cfg.Model)
+		}
+		return client, nil
+	case "local":
+		return NewLocalEmbeddingsClient(cfg.LocalBaseURL, cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("embeddings: unknown provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,94 @@
+package embeddings
+
+import (
+	"testing"
+
+	"innerworld-backend/internal/config"
+)
+
+func TestNewLocalEmbeddingsClientDefaults(t *testing.T) {
+	client := NewLocalEmbeddingsClient("", "")
+
+	if client.baseURL != defaultLocalBaseURL {
+		t.Errorf("Expected baseURL '%s', got '%s'", defaultLocalBaseURL, client.baseURL)
+	}
+	if client.Model() != defaultLocalModel {
+		t.Errorf("Expected model '%s', got '%s'", defaultLocalModel, client.Model())
+	}
+	if client.Dimension() != defaultLocalDimension {
+		t.Errorf("Expected dimension %d, got %d", defaultLocalDimension, client.Dimension())
+	}
+}
+
+func TestLocalEmbeddingsClientSetDimension(t *testing.T) {
+	client := NewLocalEmbeddingsClient("http://localhost:1234", "custom-model")
+	client.SetDimension(384)
+
+	if client.baseURL != "http://localhost:1234" {
+		t.Errorf("Expected baseURL 'http://localhost:1234', got '%s'", client.baseURL)
+	}
+	if client.Dimension() != 384 {
+		t.Errorf("Expected dimension 384, got %d", client.Dimension())
+	}
+}
+
+func TestNewProviderFromConfig(t *testing.T) {
+	openAI, err := NewProviderFromConfig(config.EmbeddingsConfig{Provider: "openai"}, "test-api-key")
+	if err != nil {
+		t.Fatalf("Expected no error for openai provider, got %v", err)
+	}
+	if _, ok := openAI.(*OpenAIEmbeddingsClient); !ok {
+		t.Errorf("Expected *OpenAIEmbeddingsClient, got %T", openAI)
+	}
+
+	local, err := NewProviderFromConfig(config.EmbeddingsConfig{Provider: "local", Model: "custom-model"}, "")
+	if err != nil {
+		t.Fatalf("Expected no error for local provider, got %v", err)
+	}
+	if _, ok := local.(*LocalEmbeddingsClient); !ok {
+		t.Errorf("Expected *LocalEmbeddingsClient, got %T", local)
+	}
+	if local.Model() != "custom-model" {
+		t.Errorf("Expected model 'custom-model', got '%s'", local.Model())
+	}
+
+	if _, err := NewProviderFromConfig(config.EmbeddingsConfig{Provider: "unknown"}, ""); err == nil {
+		t.Error("Expected an error for an unknown provider, got nil")
+	}
+}
+
+func TestEstimateTokensPerInput(t *testing.T) {
+	tokens := estimateTokensPerInput([]string{"hi", "a much longer piece of text"}, 100)
+	if len(tokens) != 2 {
+		t.Fatalf("Expected 2 token counts, got %d", len(tokens))
+	}
+	if tokens[0] >= tokens[1] {
+		t.Errorf("Expected the longer text to be attributed more tokens, got %v", tokens)
+	}
+
+	sum := tokens[0] + tokens[1]
+	if sum != 100 {
+		t.Errorf("Expected per-item estimates to sum to totalTokens (100), got %d", sum)
+	}
+}
+
+func TestEstimateTokensPerInputEmpty(t *testing.T) {
+	if tokens := estimateTokensPerInput(nil, 100); len(tokens) != 0 {
+		t.Errorf("Expected no token counts for no texts, got %v", tokens)
+	}
+	if tokens := estimateTokensPerInput([]string{"a", "b"}, 0); tokens[0] != 0 || tokens[1] != 0 {
+		t.Errorf("Expected zero token counts when totalTokens is 0, got %v", tokens)
+	}
+}
+
+func TestCalculateCosineSimilarityFunc(t *testing.T) {
+	similarity := CalculateCosineSimilarity([]float32{1, 0}, []float32{1, 0})
+	if similarity != 1.0 {
+		t.Errorf("Expected similarity 1.0 for identical vectors, got %f", similarity)
+	}
+
+	similarity = CalculateCosineSimilarity([]float32{1, 0}, []float32{0, 1})
+	if similarity != 0.0 {
+		t.Errorf("Expected similarity 0.0 for orthogonal vectors, got %f", similarity)
+	}
+}
@@ -0,0 +1,128 @@
+package embeddings
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// VectorIndex stores embeddings L2-normalized in a single contiguous
+// []float32 (rows x dim), so TopK's similarity scan reduces to a dot
+// product per row instead of CalculateCosineSimilarity's per-candidate
+// norm computation - the layout GraphRAG retrieval needs once a user's
+// graph context grows past a few hundred nodes, where FindMostSimilar's
+// one-allocation-per-candidate scan becomes the dominant cost.
+type VectorIndex struct {
+	dim  int
+	ids  []string
+	data []float32 // len(ids)*dim, row i at data[i*dim:(i+1)*dim]
+}
+
+// NewVectorIndex creates an empty index for dim-dimensional embeddings.
+func NewVectorIndex(dim int) *VectorIndex {
+	return &VectorIndex{dim: dim}
+}
+
+// Add L2-normalizes embedding and appends it to the index under id.
+// Returns an error if embedding's length doesn't match the index's
+// dimension.
+func (idx *VectorIndex) Add(id string, embedding []float32) error {
+	if len(embedding) != idx.dim {
+		return fmt.Errorf("embeddings: vector has dimension %d, index expects %d", len(embedding), idx.dim)
+	}
+
+	normalized := make([]float32, idx.dim)
+	copy(normalized, embedding)
+	normalize(normalized)
+
+	idx.ids = append(idx.ids, id)
+	idx.data = append(idx.data, normalized...)
+	return nil
+}
+
+// Len returns the number of vectors in the index.
+func (idx *VectorIndex) Len() int {
+	return len(idx.ids)
+}
+
+// Match is one TopK result: the id Add registered the vector under, and its
+// cosine similarity to the query.
+type Match struct {
+	ID    string
+	Score float32
+}
+
+// TopK returns the k vectors in the index most similar to query (cosine
+// similarity, computed as a dot product since both sides are
+// L2-normalized), highest score first. Fewer than k are returned if the
+// index holds fewer than k vectors. A min-heap of size k bounds TopK's
+// memory to O(k) regardless of index size, since every vector is scored
+// but only the running top k are ever retained.
+func (idx *VectorIndex) TopK(query []float32, k int) []Match {
+	if k <= 0 || idx.Len() == 0 {
+		return nil
+	}
+	if len(query) != idx.dim {
+		return nil
+	}
+
+	normalizedQuery := make([]float32, idx.dim)
+	copy(normalizedQuery, query)
+	normalize(normalizedQuery)
+
+	h := make(matchHeap, 0, k)
+	for i, id := range idx.ids {
+		row := idx.data[i*idx.dim : (i+1)*idx.dim]
+		score := dotProduct(normalizedQuery, row)
+
+		if len(h) < k {
+			heap.Push(&h, Match{ID: id, Score: score})
+			continue
+		}
+		if score > h[0].Score {
+			h[0] = Match{ID: id, Score: score}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	// h is a min-heap (lowest score at the root); sort.Sort would reorder
+	// it ascending, so pop it out instead to get descending order directly.
+	results := make([]Match, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(&h).(Match)
+	}
+	return results
+}
+
+// normalize scales v in place to unit L2 norm. A zero vector is left as-is
+// (its dot product with anything is already 0, the correct cosine
+// similarity for an undefined direction).
+func normalize(v []float32) {
+	var sumSquares float32
+	for _, x := range v {
+		sumSquares += x * x
+	}
+	if sumSquares == 0 {
+		return
+	}
+
+	norm := sqrt32(sumSquares)
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// matchHeap is a container/heap min-heap on Match.Score, giving TopK an
+// O(n log k) scan instead of scoring all n candidates and sorting them.
+type matchHeap []Match
+
+func (h matchHeap) Len() int            { return len(h) }
+func (h matchHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h matchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(Match)) }
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
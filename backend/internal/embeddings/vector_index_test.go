@@ -0,0 +1,156 @@
+package embeddings
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestVectorIndexAddDimensionMismatch(t *testing.T) {
+	idx := NewVectorIndex(3)
+
+	if err := idx.Add("bad", []float32{1, 2}); err == nil {
+		t.Error("Expected an error adding a vector of the wrong dimension, got nil")
+	}
+	if idx.Len() != 0 {
+		t.Errorf("Expected Len() 0 after a failed Add, got %d", idx.Len())
+	}
+}
+
+func TestVectorIndexTopK(t *testing.T) {
+	idx := NewVectorIndex(2)
+	vectors := map[string][]float32{
+		"same":       {1, 0},
+		"close":      {0.9, 0.1},
+		"orthogonal": {0, 1},
+		"opposite":   {-1, 0},
+	}
+	for id, v := range vectors {
+		if err := idx.Add(id, v); err != nil {
+			t.Fatalf("Add(%s) failed: %v", id, err)
+		}
+	}
+
+	matches := idx.TopK([]float32{1, 0}, 2)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(matches))
+	}
+	if matches[0].ID != "same" {
+		t.Errorf("Expected best match 'same', got '%s'", matches[0].ID)
+	}
+	if matches[1].ID != "close" {
+		t.Errorf("Expected second match 'close', got '%s'", matches[1].ID)
+	}
+	if matches[0].Score < matches[1].Score {
+		t.Errorf("Expected descending scores, got %f then %f", matches[0].Score, matches[1].Score)
+	}
+}
+
+func TestVectorIndexTopKFewerThanK(t *testing.T) {
+	idx := NewVectorIndex(2)
+	if err := idx.Add("only", []float32{1, 0}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	matches := idx.TopK([]float32{1, 0}, 5)
+	if len(matches) != 1 {
+		t.Errorf("Expected 1 match when index holds fewer than k vectors, got %d", len(matches))
+	}
+}
+
+func TestVectorIndexTopKEmpty(t *testing.T) {
+	idx := NewVectorIndex(2)
+	if matches := idx.TopK([]float32{1, 0}, 3); matches != nil {
+		t.Errorf("Expected nil matches from an empty index, got %v", matches)
+	}
+}
+
+func TestVectorIndexTopKDimensionMismatch(t *testing.T) {
+	idx := NewVectorIndex(2)
+	if err := idx.Add("v", []float32{1, 0}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if matches := idx.TopK([]float32{1, 0, 0}, 1); matches != nil {
+		t.Errorf("Expected nil matches for a query of the wrong dimension, got %v", matches)
+	}
+}
+
+func TestVectorIndexMatchesFindMostSimilar(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	const dim = 32
+	const n = 50
+
+	idx := NewVectorIndex(dim)
+	candidates := make([]*EmbeddingResult, n)
+	for i := 0; i < n; i++ {
+		v := randomVector(rng, dim)
+		id := fmt.Sprintf("candidate-%d", i)
+		if err := idx.Add(id, v); err != nil {
+			t.Fatalf("Add(%s) failed: %v", id, err)
+		}
+		candidates[i] = &EmbeddingResult{Text: id, Embedding: v}
+	}
+
+	query := randomVector(rng, dim)
+
+	best, _ := FindMostSimilar(query, candidates)
+	matches := idx.TopK(query, 1)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 TopK match, got %d", len(matches))
+	}
+	if matches[0].ID != best.Text {
+		t.Errorf("VectorIndex.TopK disagreed with FindMostSimilar: got '%s', want '%s'", matches[0].ID, best.Text)
+	}
+}
+
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = rng.Float32()*2 - 1
+	}
+	return v
+}
+
+func buildBenchIndex(n, dim int) (*VectorIndex, []float32) {
+	rng := rand.New(rand.NewSource(1))
+	idx := NewVectorIndex(dim)
+	for i := 0; i < n; i++ {
+		idx.Add(fmt.Sprintf("candidate-%d", i), randomVector(rng, dim))
+	}
+	return idx, randomVector(rng, dim)
+}
+
+func buildBenchCandidates(n, dim int) ([]*EmbeddingResult, []float32) {
+	rng := rand.New(rand.NewSource(1))
+	candidates := make([]*EmbeddingResult, n)
+	for i := 0; i < n; i++ {
+		candidates[i] = &EmbeddingResult{Text: fmt.Sprintf("candidate-%d", i), Embedding: randomVector(rng, dim)}
+	}
+	return candidates, randomVector(rng, dim)
+}
+
+const benchDim = 1536 // text-embedding-3-small's dimension
+
+func BenchmarkFindMostSimilar1k(b *testing.B)   { benchmarkFindMostSimilar(b, 1_000) }
+func BenchmarkFindMostSimilar10k(b *testing.B)  { benchmarkFindMostSimilar(b, 10_000) }
+func BenchmarkFindMostSimilar100k(b *testing.B) { benchmarkFindMostSimilar(b, 100_000) }
+
+func benchmarkFindMostSimilar(b *testing.B, n int) {
+	candidates, query := buildBenchCandidates(n, benchDim)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindMostSimilar(query, candidates)
+	}
+}
+
+func BenchmarkVectorIndexTopK1k(b *testing.B)   { benchmarkVectorIndexTopK(b, 1_000) }
+func BenchmarkVectorIndexTopK10k(b *testing.B)  { benchmarkVectorIndexTopK(b, 10_000) }
+func BenchmarkVectorIndexTopK100k(b *testing.B) { benchmarkVectorIndexTopK(b, 100_000) }
+
+func benchmarkVectorIndexTopK(b *testing.B, n int) {
+	idx, query := buildBenchIndex(n, benchDim)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.TopK(query, 10)
+	}
+}
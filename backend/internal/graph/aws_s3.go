@@ -0,0 +1,425 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/logging"
+	"innerworld-backend/internal/resilience"
+	"innerworld-backend/internal/types"
+	"io"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+)
+
+// AWSS3Client is the production S3Client backed by aws-sdk-go-v2's service/s3
+// client, replacing MockS3Client once real bucket infrastructure is
+// provisioned. It lays out each user's graph context, nodes, and edges as
+// individual JSON objects under a deterministic key prefix:
+//
+//	{KeyPrefix}users/{userID}/context.json
+//	{KeyPrefix}users/{userID}/nodes/{nodeID}.json
+//	{KeyPrefix}users/{userID}/edges/{edgeID}.json
+type AWSS3Client struct {
+	client       *s3.Client
+	bucket       string
+	keyPrefix    string
+	sseAlgorithm string
+	retryConfig  resilience.RetryConfig
+}
+
+// conditionalWriteRetryConfig bounds how many times UpdateUserGraph retries a
+// conditional PutObject after losing a race to a concurrent writer, mirroring
+// the read-modify-write loop DynamoDB callers use for optimistic concurrency.
+func conditionalWriteRetryConfig() resilience.RetryConfig {
+	return resilience.RetryConfig{
+		MaxAttempts:       5,
+		InitialDelay:      25 * time.Millisecond,
+		MaxDelay:          500 * time.Millisecond,
+		BackoffMultiplier: 2.0,
+		JitterMode:        resilience.JitterFull,
+	}
+}
+
+// NewAWSS3Client creates a production S3Client from cfg, resolving AWS
+// credentials via the default credential chain (optionally scoped to
+// cfg.Profile and/or assumed into cfg.RoleARN) and pointing at cfg.Endpoint
+// when set, so the same client can target a local gofakes3/MinIO server for
+// development and tests.
+func NewAWSS3Client(ctx context.Context, cfg config.S3Config) (*AWSS3Client, error) {
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.Region))
+	}
+	if cfg.Profile != "" {
+		loadOpts = append(loadOpts, awsconfig.WithSharedConfigProfile(cfg.Profile))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	if cfg.RoleARN != "" {
+		stsClient := sts.NewFromConfig(awsCfg)
+		awsCfg.Credentials = stscreds.NewAssumeRoleProvider(stsClient, cfg.RoleARN)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = &cfg.Endpoint
+			o.UsePathStyle = true
+		}
+	})
+
+	return newAWSS3Client(client, cfg), nil
+}
+
+// newAWSS3Client builds the client around an already-constructed *s3.Client,
+// so tests can point it at an httptest.Server running gofakes3 without going
+// through AWS credential resolution.
+func newAWSS3Client(client *s3.Client, cfg config.S3Config) *AWSS3Client {
+	return &AWSS3Client{
+		client:       client,
+		bucket:       cfg.Bucket,
+		keyPrefix:    cfg.KeyPrefix,
+		sseAlgorithm: cfg.SSEAlgorithm,
+		retryConfig:  conditionalWriteRetryConfig(),
+	}
+}
+
+// s3NodeRecord is the JSON form of a single CreateNode call stored under
+// users/{userID}/nodes/{nodeID}.json.
+type s3NodeRecord struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	NodeType  string    `json:"nodeType"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// s3EdgeRecord is the JSON form of a single CreateEdge call stored under
+// users/{userID}/edges/{edgeID}.json.
+type s3EdgeRecord struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	NodeID    string    `json:"nodeId"`
+	EdgeType  string    `json:"edgeType"`
+	Target    string    `json:"target"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetUserContext reads the user's context.json, lazily seeding a default
+// context (mirroring MockS3Client's auto-create behavior) the first time a
+// user is read before CreateUser has run.
+func (c *AWSS3Client) GetUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
+	body, _, err := c.getObjectWithETag(ctx, c.contextKey(userID))
+	if isNotFound(err) {
+		return c.seedDefaultContext(ctx, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context for user %s: %w", userID, err)
+	}
+
+	var graphContext types.GraphContext
+	if err := json.Unmarshal(body, &graphContext); err != nil {
+		return nil, fmt.Errorf("failed to decode context for user %s: %w", userID, err)
+	}
+	return &graphContext, nil
+}
+
+func (c *AWSS3Client) seedDefaultContext(ctx context.Context, userID string) (*types.GraphContext, error) {
+	defaultContext := &types.GraphContext{
+		UserID:      userID,
+		LastUpdated: time.Now(),
+		Summary:     "New user - no conversation history yet",
+	}
+
+	body, err := json.Marshal(defaultContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode default context for user %s: %w", userID, err)
+	}
+
+	if err := c.putIfAbsent(ctx, c.contextKey(userID), body); err != nil && !isPreconditionFailed(err) {
+		return nil, fmt.Errorf("failed to seed default context for user %s: %w", userID, err)
+	}
+	return defaultContext, nil
+}
+
+// UpdateUserGraph merges data into the user's stored context with an
+// ETag-conditional PutObject, retrying the read-modify-write on
+// PreconditionFailed so a concurrent writer's update is never silently
+// clobbered. data is expected to be a map[string]interface{} with an
+// optional "summary" key; any other shape is logged and ignored, matching
+// MockS3Client's Phase-1 placeholder semantics.
+func (c *AWSS3Client) UpdateUserGraph(ctx context.Context, userID string, data interface{}) error {
+	updates, ok := data.(map[string]interface{})
+	if !ok {
+		logging.FromContext(ctx).InfoContext(ctx, "s3: UpdateUserGraph called with unsupported data type (ignored)",
+			"component", "aws_s3", "user_id", userID, "data_type", fmt.Sprintf("%T", data))
+		return nil
+	}
+
+	key := c.contextKey(userID)
+	_, err := resilience.RetryWithBackoff(ctx, c.retryConfig, isPreconditionFailed, func(ctx context.Context, _ int) (struct{}, error) {
+		return struct{}{}, c.applyContextUpdate(ctx, key, updates)
+	})
+	return err
+}
+
+func (c *AWSS3Client) applyContextUpdate(ctx context.Context, key string, updates map[string]interface{}) error {
+	body, etag, err := c.getObjectWithETag(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read context at %s: %w", key, err)
+	}
+
+	var graphContext types.GraphContext
+	if err := json.Unmarshal(body, &graphContext); err != nil {
+		return fmt.Errorf("failed to decode context at %s: %w", key, err)
+	}
+
+	if summary, ok := updates["summary"].(string); ok {
+		graphContext.Summary = summary
+	}
+	graphContext.LastUpdated = time.Now()
+
+	updated, err := json.Marshal(graphContext)
+	if err != nil {
+		return fmt.Errorf("failed to encode context at %s: %w", key, err)
+	}
+
+	return c.putObjectConditional(ctx, key, updated, etag)
+}
+
+// RefreshUserContext re-reads the user's context; S3 has no separate
+// "refresh" concept beyond a fresh GetObject, same as MockS3Client.
+func (c *AWSS3Client) RefreshUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
+	return c.GetUserContext(ctx, userID)
+}
+
+// HealthCheck verifies the configured bucket is reachable.
+func (c *AWSS3Client) HealthCheck(ctx context.Context) error {
+	if _, err := c.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &c.bucket}); err != nil {
+		return fmt.Errorf("S3 health check failed: %w", err)
+	}
+	return nil
+}
+
+// CreateUser initializes a new user's context.json, failing if one already
+// exists so callers don't silently overwrite existing history.
+func (c *AWSS3Client) CreateUser(ctx context.Context, userID string) error {
+	defaultContext := &types.GraphContext{
+		UserID:      userID,
+		LastUpdated: time.Now(),
+		Summary:     "New user - no conversation history yet",
+	}
+
+	body, err := json.Marshal(defaultContext)
+	if err != nil {
+		return fmt.Errorf("failed to encode default context for user %s: %w", userID, err)
+	}
+
+	err = c.putIfAbsent(ctx, c.contextKey(userID), body)
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("user %s already exists", userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// DeleteUserData removes every object under the user's key prefix: their
+// context, and every node and edge written via CreateNode/CreateEdge.
+func (c *AWSS3Client) DeleteUserData(ctx context.Context, userID string) error {
+	prefix := c.key(fmt.Sprintf("users/%s/", userID))
+
+	var continuationToken *string
+	for {
+		page, err := c.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            &c.bucket,
+			Prefix:            &prefix,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list objects for user %s: %w", userID, err)
+		}
+
+		if len(page.Contents) > 0 {
+			objects := make([]s3types.ObjectIdentifier, len(page.Contents))
+			for i, obj := range page.Contents {
+				objects[i] = s3types.ObjectIdentifier{Key: obj.Key}
+			}
+			if _, err := c.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: &c.bucket,
+				Delete: &s3types.Delete{Objects: objects},
+			}); err != nil {
+				return fmt.Errorf("failed to delete objects for user %s: %w", userID, err)
+			}
+		}
+
+		if page.IsTruncated == nil || !*page.IsTruncated {
+			return nil
+		}
+		continuationToken = page.NextContinuationToken
+	}
+}
+
+// CreateNode stores a node as its own JSON object under
+// users/{userID}/nodes/{nodeID}.json. The interface predates context
+// threading (it's shared with MockS3Client, whose equivalent method also
+// takes no ctx), so writes run with a background context.
+func (c *AWSS3Client) CreateNode(userID string, nodeType string, content string) error {
+	record := s3NodeRecord{
+		ID:        "node_" + uuid.New().String()[:8],
+		UserID:    userID,
+		NodeType:  nodeType,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode node for user %s: %w", userID, err)
+	}
+
+	key := c.key(fmt.Sprintf("users/%s/nodes/%s.json", userID, record.ID))
+	if err := c.putIfAbsent(context.Background(), key, body); err != nil {
+		return fmt.Errorf("failed to create node for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// CreateEdge stores an edge as its own JSON object under
+// users/{userID}/edges/{edgeID}.json.
+func (c *AWSS3Client) CreateEdge(userID string, nodeID string, edgeType string, target string) error {
+	record := s3EdgeRecord{
+		ID:        "edge_" + uuid.New().String()[:8],
+		UserID:    userID,
+		NodeID:    nodeID,
+		EdgeType:  edgeType,
+		Target:    target,
+		CreatedAt: time.Now(),
+	}
+
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode edge for user %s: %w", userID, err)
+	}
+
+	key := c.key(fmt.Sprintf("users/%s/edges/%s.json", userID, record.ID))
+	if err := c.putIfAbsent(context.Background(), key, body); err != nil {
+		return fmt.Errorf("failed to create edge for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// key prepends keyPrefix to suffix, so multiple environments can share one
+// bucket without colliding.
+func (c *AWSS3Client) key(suffix string) string {
+	return c.keyPrefix + suffix
+}
+
+func (c *AWSS3Client) contextKey(userID string) string {
+	return c.key(fmt.Sprintf("users/%s/context.json", userID))
+}
+
+func (c *AWSS3Client) getObjectWithETag(ctx context.Context, key string) ([]byte, string, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &key})
+	if err != nil {
+		return nil, "", err
+	}
+	defer out.Body.Close()
+
+	body, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object body for key %s: %w", key, err)
+	}
+
+	etag := ""
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+	return body, etag, nil
+}
+
+// putIfAbsent writes body to key only if no object exists there yet,
+// surfacing isPreconditionFailed(err) == true when another writer won the
+// race. Used for initial user/node/edge creation, which should never
+// overwrite existing data.
+func (c *AWSS3Client) putIfAbsent(ctx context.Context, key string, body []byte) error {
+	input := &s3.PutObjectInput{
+		Bucket:      &c.bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(body),
+		IfNoneMatch: stringPtr("*"),
+	}
+	c.applySSE(input)
+	_, err := c.client.PutObject(ctx, input)
+	return err
+}
+
+// putObjectConditional writes body to key only if its current ETag still
+// matches etag, so a read-modify-write never clobbers a write that happened
+// in between.
+func (c *AWSS3Client) putObjectConditional(ctx context.Context, key string, body []byte, etag string) error {
+	input := &s3.PutObjectInput{
+		Bucket:  &c.bucket,
+		Key:     &key,
+		Body:    bytes.NewReader(body),
+		IfMatch: &etag,
+	}
+	c.applySSE(input)
+	_, err := c.client.PutObject(ctx, input)
+	return err
+}
+
+func (c *AWSS3Client) applySSE(input *s3.PutObjectInput) {
+	if c.sseAlgorithm != "" {
+		input.ServerSideEncryption = s3types.ServerSideEncryption(c.sseAlgorithm)
+	}
+}
+
+func stringPtr(s string) *string { return &s }
+
+// isNotFound reports whether err is S3's "no object at this key" error.
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		return code == "NoSuchKey" || code == "NotFound"
+	}
+	return false
+}
+
+// isPreconditionFailed reports whether err is S3's response to a failed
+// If-Match/If-None-Match condition on PutObject, used both to detect a
+// losing CreateUser (or default-context seeding) race and as the retry predicate
+// for UpdateUserGraph's conditional read-modify-write.
+func isPreconditionFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "PreconditionFailed"
+	}
+	return false
+}
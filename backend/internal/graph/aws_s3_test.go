@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"innerworld-backend/internal/config"
+	"net/http/httptest"
+	"testing"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// newTestAWSS3Client spins up an in-process gofakes3 server backed by
+// s3mem, creates the test bucket, and returns an AWSS3Client pointed at it -
+// so these tests exercise real aws-sdk-go-v2 request/response handling
+// without needing live AWS credentials or network access.
+func newTestAWSS3Client(t *testing.T) *AWSS3Client {
+	t.Helper()
+
+	faker := gofakes3.New(s3mem.New())
+	server := httptest.NewServer(faker.Server())
+	t.Cleanup(server.Close)
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion("us-east-1"),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("TEST", "TEST", "")),
+	)
+	if err != nil {
+		t.Fatalf("failed to load test AWS config: %v", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = &server.URL
+		o.UsePathStyle = true
+	})
+
+	cfg := config.S3Config{Bucket: "innerworld-test-bucket", Region: "us-east-1"}
+	if _, err := client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: &cfg.Bucket}); err != nil {
+		t.Fatalf("failed to create test bucket: %v", err)
+	}
+
+	return newAWSS3Client(client, cfg)
+}
+
+func TestAWSS3Client_CreateAndGetUserContext(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	ctx := context.Background()
+
+	if err := client.CreateUser(ctx, "user-1"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	got, err := client.GetUserContext(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUserContext failed: %v", err)
+	}
+	if got.UserID != "user-1" {
+		t.Errorf("UserID = %q, want %q", got.UserID, "user-1")
+	}
+	if got.Summary == "" {
+		t.Errorf("Summary should be populated for a freshly created user")
+	}
+}
+
+func TestAWSS3Client_CreateUser_AlreadyExists(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	ctx := context.Background()
+
+	if err := client.CreateUser(ctx, "user-1"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if err := client.CreateUser(ctx, "user-1"); err == nil {
+		t.Error("expected an error creating the same user twice, got nil")
+	}
+}
+
+func TestAWSS3Client_GetUserContext_LazyCreatesDefault(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	ctx := context.Background()
+
+	got, err := client.GetUserContext(ctx, "never-created")
+	if err != nil {
+		t.Fatalf("GetUserContext failed: %v", err)
+	}
+	if got.UserID != "never-created" {
+		t.Errorf("UserID = %q, want %q", got.UserID, "never-created")
+	}
+
+	// A second read should see the same seeded object, not error.
+	again, err := client.GetUserContext(ctx, "never-created")
+	if err != nil {
+		t.Fatalf("second GetUserContext failed: %v", err)
+	}
+	if again.UserID != got.UserID {
+		t.Errorf("second read UserID = %q, want %q", again.UserID, got.UserID)
+	}
+}
+
+func TestAWSS3Client_UpdateUserGraph(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	ctx := context.Background()
+
+	if err := client.CreateUser(ctx, "user-1"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	if err := client.UpdateUserGraph(ctx, "user-1", map[string]interface{}{"summary": "updated summary"}); err != nil {
+		t.Fatalf("UpdateUserGraph failed: %v", err)
+	}
+
+	got, err := client.GetUserContext(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUserContext failed: %v", err)
+	}
+	if got.Summary != "updated summary" {
+		t.Errorf("Summary = %q, want %q", got.Summary, "updated summary")
+	}
+}
+
+func TestAWSS3Client_CreateNode(t *testing.T) {
+	client := newTestAWSS3Client(t)
+
+	if err := client.CreateNode("user-1", "reflection", "felt proud today"); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+}
+
+func TestAWSS3Client_CreateEdge(t *testing.T) {
+	client := newTestAWSS3Client(t)
+
+	if err := client.CreateEdge("user-1", "node_abc123", "relates_to", "node_def456"); err != nil {
+		t.Fatalf("CreateEdge failed: %v", err)
+	}
+}
+
+func TestAWSS3Client_DeleteUserData(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	ctx := context.Background()
+
+	if err := client.CreateUser(ctx, "user-1"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if err := client.CreateNode("user-1", "reflection", "felt proud today"); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+
+	if err := client.DeleteUserData(ctx, "user-1"); err != nil {
+		t.Fatalf("DeleteUserData failed: %v", err)
+	}
+
+	// After deletion, GetUserContext should lazily seed a brand new default
+	// context rather than returning the deleted one.
+	got, err := client.GetUserContext(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetUserContext after delete failed: %v", err)
+	}
+	if got.Summary != "New user - no conversation history yet" {
+		t.Errorf("Summary after delete = %q, want the default new-user summary", got.Summary)
+	}
+}
+
+func TestAWSS3Client_HealthCheck(t *testing.T) {
+	client := newTestAWSS3Client(t)
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestAWSS3Client_HealthCheck_MissingBucket(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	client.bucket = "does-not-exist"
+
+	if err := client.HealthCheck(context.Background()); err == nil {
+		t.Error("expected an error for a missing bucket, got nil")
+	}
+}
+
+func TestAWSS3Client_KeyPrefix(t *testing.T) {
+	client := newTestAWSS3Client(t)
+	client.keyPrefix = "dev/"
+
+	if got, want := client.contextKey("user-1"), "dev/users/user-1/context.json"; got != want {
+		t.Errorf("contextKey() = %q, want %q", got, want)
+	}
+}
+
+func TestIsPreconditionFailed(t *testing.T) {
+	if isPreconditionFailed(nil) {
+		t.Error("isPreconditionFailed(nil) should be false")
+	}
+	if isPreconditionFailed(errors.New("boom")) {
+		t.Error("isPreconditionFailed should be false for an unrelated error")
+	}
+}
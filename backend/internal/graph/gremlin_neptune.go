@@ -0,0 +1,864 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/logging"
+	"innerworld-backend/internal/metrics"
+	"innerworld-backend/internal/types"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// gremlinMimeType is Gremlin Server's GraphSON 3.0 message mimetype, sent as
+// a length-prefixed prefix on every binary websocket frame per the Gremlin
+// Server binary protocol.
+const gremlinMimeType = "application/vnd.gremlin-v3.0+json"
+
+// defaultMaxSubgraphNodes bounds GetUserContext's subgraph query so a user
+// with years of history doesn't pull their entire graph into one response.
+const defaultMaxSubgraphNodes = 50
+
+// defaultRecencyWindow bounds GetUserContext's subgraph query to nodes
+// touched (lastSeen) within this long, so stale one-off mentions don't
+// crowd out what the user actually cares about right now.
+const defaultRecencyWindow = 180 * 24 * time.Hour
+
+// elementVertexLabel is the Gremlin vertex label every upserted
+// ConversationElement gets, regardless of its Type (stored as the
+// 'type' property instead), so a single index on (userID, type,
+// normalizedContent, dayBucket) covers every element kind.
+const elementVertexLabel = "Element"
+
+// GremlinNeptuneClient is the production NeptuneClient backed by a real
+// Gremlin Server/Neptune websocket connection, replacing MockNeptuneClient
+// once a Neptune cluster is provisioned. It speaks Gremlin Server's
+// string-script protocol (not bytecode), sending one Groovy script per call
+// with all user data passed as bound variables rather than interpolated
+// into the script text, and decodes GraphSON 3.0 responses generically.
+type GremlinNeptuneClient struct {
+	wsURL            string
+	region           string
+	useIAMAuth       bool
+	awsCfg           aws.Config
+	maxSubgraphNodes int
+	recencyWindow    time.Duration
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// NewGremlinNeptuneClient dials cfg's Neptune endpoint over a websocket
+// Gremlin connection, signing the handshake with SigV4 when cfg.IAMAuth is
+// set (Neptune's IAM database authentication). The connection is opened
+// eagerly so a misconfigured endpoint/region/credentials fails fast at
+// startup instead of on the first query.
+func NewGremlinNeptuneClient(ctx context.Context, cfg config.NeptuneConfig) (*GremlinNeptuneClient, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config for Neptune: %w", err)
+	}
+
+	client := &GremlinNeptuneClient{
+		wsURL:            fmt.Sprintf("wss://%s:%d/gremlin", cfg.Endpoint, cfg.Port),
+		region:           cfg.Region,
+		useIAMAuth:       cfg.IAMAuth,
+		awsCfg:           awsCfg,
+		maxSubgraphNodes: defaultMaxSubgraphNodes,
+		recencyWindow:    defaultRecencyWindow,
+	}
+
+	if err := client.connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to Neptune at %s: %w", client.wsURL, err)
+	}
+	return client, nil
+}
+
+// connect (re)opens the websocket connection, replacing any existing one.
+// Gremlin Server's string-script protocol is stateless per request, so a
+// single long-lived connection is reused across every call rather than
+// dialing per request.
+func (c *GremlinNeptuneClient) connect(ctx context.Context) error {
+	header, err := c.signHandshake(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sign websocket handshake: %w", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, header)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+	return nil
+}
+
+// signHandshake SigV4-signs a GET to wsURL's https-equivalent (Neptune's IAM
+// auth validates the signature as if the websocket upgrade were a plain
+// HTTPS request) and returns the resulting Authorization/X-Amz-* headers to
+// send with the real websocket upgrade request. Returns an empty header set
+// when IAM auth is disabled (e.g. a local tinkerpop/gremlin-server used in
+// integration tests, which has no IAM concept).
+func (c *GremlinNeptuneClient) signHandshake(ctx context.Context) (http.Header, error) {
+	if !c.useIAMAuth {
+		return http.Header{}, nil
+	}
+
+	creds, err := c.awsCfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	signURL := strings.Replace(c.wsURL, "wss://", "https://", 1)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	emptyPayloadHash := sha256.Sum256(nil)
+	signer := v4.NewSigner()
+	if err := signer.SignHTTP(ctx, creds, req, hex.EncodeToString(emptyPayloadHash[:]), "neptune-db", c.region, time.Now()); err != nil {
+		return nil, err
+	}
+	return req.Header, nil
+}
+
+// gremlinRequest is Gremlin Server's string-script "eval" request: the
+// script text plus its bound variables, evaluated as gremlin-groovy.
+type gremlinRequest struct {
+	RequestID string             `json:"requestId"`
+	Op        string             `json:"op"`
+	Processor string             `json:"processor"`
+	Args      gremlinRequestArgs `json:"args"`
+}
+
+type gremlinRequestArgs struct {
+	Gremlin  string                 `json:"gremlin"`
+	Bindings map[string]interface{} `json:"bindings,omitempty"`
+	Language string                 `json:"language"`
+	Accept   string                 `json:"accept"`
+}
+
+// gremlinResponse is Gremlin Server's response envelope. Status.Code 200
+// (success, full response) and 204 (success, no content) are terminal;
+// 206 (partial content) means more frames follow for the same requestId.
+type gremlinResponse struct {
+	RequestID string `json:"requestId"`
+	Status    struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+	Result struct {
+		Data json.RawMessage `json:"data"`
+	} `json:"result"`
+}
+
+// eval sends script/bindings as a single Gremlin Server request over the
+// shared connection and returns the decoded result list. Calls are
+// serialized by mu, matching Gremlin Server's one-in-flight-request-per-
+// connection expectation for the string-script protocol; concurrent
+// GremlinNeptuneClient callers queue rather than racing frames.
+func (c *GremlinNeptuneClient) eval(ctx context.Context, script string, bindings map[string]interface{}) ([]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, fmt.Errorf("neptune: no active connection")
+	}
+
+	request := gremlinRequest{
+		RequestID: uuid.New().String(),
+		Op:        "eval",
+		Processor: "",
+		Args: gremlinRequestArgs{
+			Gremlin:  script,
+			Bindings: bindings,
+			Language: "gremlin-groovy",
+			Accept:   gremlinMimeType,
+		},
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gremlin request: %w", err)
+	}
+
+	frame := make([]byte, 0, 1+len(gremlinMimeType)+len(payload))
+	frame = append(frame, byte(len(gremlinMimeType)))
+	frame = append(frame, gremlinMimeType...)
+	frame = append(frame, payload...)
+
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+		return nil, fmt.Errorf("failed to write gremlin request: %w", err)
+	}
+
+	var results []interface{}
+	for {
+		_, raw, err := c.conn.ReadMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gremlin response: %w", err)
+		}
+
+		var response gremlinResponse
+		if err := json.Unmarshal(raw, &response); err != nil {
+			return nil, fmt.Errorf("failed to decode gremlin response: %w", err)
+		}
+
+		if response.Status.Code >= 300 {
+			return nil, fmt.Errorf("neptune: gremlin request failed (%d): %s", response.Status.Code, response.Status.Message)
+		}
+
+		if len(response.Result.Data) > 0 {
+			var decoded interface{}
+			if err := json.Unmarshal(response.Result.Data, &decoded); err != nil {
+				return nil, fmt.Errorf("failed to decode gremlin result data: %w", err)
+			}
+			if list, ok := decodeGraphSON(decoded).([]interface{}); ok {
+				results = append(results, list...)
+			} else if decoded != nil {
+				results = append(results, decodeGraphSON(decoded))
+			}
+		}
+
+		if response.Status.Code != 206 {
+			return results, nil
+		}
+	}
+}
+
+// decodeGraphSON recursively unwraps GraphSON 3.0's {"@type": T, "@value":
+// V} typed-value envelopes into native Go values, since eval only needs
+// property values/counts back - not a full typed object model. Unknown
+// @type values fall through to their raw @value so a forward-compatible
+// server extension still degrades gracefully instead of erroring.
+func decodeGraphSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		typ, hasType := val["@type"].(string)
+		rawValue, hasValue := val["@value"]
+		if !hasType || !hasValue {
+			decoded := make(map[string]interface{}, len(val))
+			for k, nested := range val {
+				decoded[k] = decodeGraphSON(nested)
+			}
+			return decoded
+		}
+
+		switch typ {
+		case "g:Map":
+			return decodeGraphSONMap(rawValue)
+		case "g:List", "g:Set":
+			items, _ := rawValue.([]interface{})
+			decoded := make([]interface{}, len(items))
+			for i, item := range items {
+				decoded[i] = decodeGraphSON(item)
+			}
+			return decoded
+		case "g:Vertex", "g:Edge", "g:VertexProperty", "g:Property":
+			return decodeGraphSON(rawValue)
+		default:
+			// g:Int32, g:Int64, g:Double, g:Float, g:Date, g:UUID, etc. -
+			// the JSON-native @value (number or string) is already the
+			// right Go representation for our purposes.
+			return decodeGraphSON(rawValue)
+		}
+	case []interface{}:
+		decoded := make([]interface{}, len(val))
+		for i, item := range val {
+			decoded[i] = decodeGraphSON(item)
+		}
+		return decoded
+	default:
+		return val
+	}
+}
+
+// decodeGraphSONMap decodes a g:Map's @value, which GraphSON 3.0 encodes as
+// a flat [key1, value1, key2, value2, ...] array rather than a JSON object
+// (so non-string keys round-trip), into a map[string]interface{}.
+func decodeGraphSONMap(rawValue interface{}) map[string]interface{} {
+	items, _ := rawValue.([]interface{})
+	decoded := make(map[string]interface{}, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		key := fmt.Sprintf("%v", decodeGraphSON(items[i]))
+		decoded[key] = decodeGraphSON(items[i+1])
+	}
+	return decoded
+}
+
+// normalizeForDedup canonicalizes element content for the dedup key, so
+// "Anxious" and "anxious " merge into the same node instead of creating
+// near-duplicates every session.
+func normalizeForDedup(content string) string {
+	return strings.ToLower(strings.TrimSpace(content))
+}
+
+// dayBucket buckets a timestamp to its UTC calendar day, the dedup key's
+// temporal component: a real recurrence of "Anxious" on a later day gets its
+// own vertex (count tracked separately per day), while repeats within the
+// same day merge into one.
+func dayBucket(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// ElementDedupKey derives a deterministic identity for element from
+// hash(userID|type|normalizedContent|dayBucket). It's the merge key
+// UpsertConversationElements matches vertices on, exported so callers (e.g.
+// session-processor's checkpointing) can record which elements a batch
+// wrote without depending on an unstable, time.Now()-derived node ID - the
+// same element re-submitted on a Lambda retry always hashes to the same
+// key, so retried writes merge instead of duplicating.
+func ElementDedupKey(userID string, element types.ConversationElement) string {
+	sum := sha256.Sum256([]byte(userID + "|" + element.Type + "|" + normalizeForDedup(element.Content) + "|" + dayBucket(element.Timestamp)))
+	return hex.EncodeToString(sum[:])
+}
+
+// elementEdgeLabel maps a non-anchor element's Type to the typed edge label
+// connecting it back to the Event it was extracted alongside, since
+// ConversationElement carries no explicit relational data of its own -
+// this is a heuristic over what extractConversationElements gives us, not
+// a ground-truth relationship.
+func elementEdgeLabel(elementType string) string {
+	switch elementType {
+	case "Feeling":
+		return "FELT_DURING"
+	case "Goal", "Habit":
+		return "TRIGGERED_BY"
+	default:
+		return "RELATES_TO"
+	}
+}
+
+// UpsertConversationElements merges a whole session's extracted elements
+// into the graph in a single batched Gremlin request: every element becomes
+// an Element vertex deduped by (userID, type, normalizedContent, dayBucket)
+// via mergeV(), keyed by ElementDedupKey so a retried call with the same
+// elements merges rather than duplicating, with firstSeen/lastSeen/count
+// maintained across merges. Every non-Event element gets a typed edge
+// (FELT_DURING/TRIGGERED_BY/RELATES_TO) to the most recent preceding Event
+// element in the same batch, merged the same way via mergeE(). Returns how
+// many nodes/edges were newly created (as opposed to merged into an
+// existing one).
+func (c *GremlinNeptuneClient) UpsertConversationElements(ctx context.Context, userID string, elements []types.ConversationElement) (int, int, error) {
+	if len(elements) == 0 {
+		return 0, 0, nil
+	}
+
+	nowMillis := time.Now().UnixMilli()
+	nodeBindings := make([]map[string]interface{}, len(elements))
+	var lastEventKey string
+	edgeBindings := []map[string]interface{}{}
+
+	for i, element := range elements {
+		key := ElementDedupKey(userID, element)
+		nodeBindings[i] = map[string]interface{}{
+			"key":               key,
+			"userID":            userID,
+			"type":              element.Type,
+			"normalizedContent": normalizeForDedup(element.Content),
+			"dayBucket":         dayBucket(element.Timestamp),
+			"content":           element.Content,
+		}
+
+		if element.Type == "Event" {
+			lastEventKey = key
+			continue
+		}
+		if lastEventKey == "" {
+			continue
+		}
+		edgeBindings = append(edgeBindings, map[string]interface{}{
+			"fromKey": lastEventKey,
+			"toKey":   key,
+			"label":   elementEdgeLabel(element.Type),
+		})
+	}
+
+	bindings := map[string]interface{}{
+		"elements": nodeBindings,
+		"edges":    edgeBindings,
+		"now":      nowMillis,
+	}
+
+	results, err := c.eval(ctx, upsertConversationElementsScript, bindings)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to upsert conversation elements for user %s: %w", userID, err)
+	}
+
+	logging.FromContext(ctx).InfoContext(ctx, "neptune: upserted conversation elements",
+		"component", "gremlin_neptune", "user_id", userID, "element_count", len(elements))
+
+	return decodeUpsertCounts(results)
+}
+
+// decodeUpsertCounts reads the {nodesCreated, edgesCreated} map
+// upsertConversationElementsScript returns as its final expression.
+func decodeUpsertCounts(results []interface{}) (int, int, error) {
+	if len(results) == 0 {
+		return 0, 0, fmt.Errorf("neptune: upsert script returned no result")
+	}
+	counts, ok := results[0].(map[string]interface{})
+	if !ok {
+		return 0, 0, fmt.Errorf("neptune: unexpected upsert result shape %T", results[0])
+	}
+	return toInt(counts["nodesCreated"]), toInt(counts["edgesCreated"]), nil
+}
+
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	default:
+		return 0
+	}
+}
+
+// firstSeenOf reads the firstSeen property valueMap injects (millis since
+// epoch, as set by UpsertConversationElements/CreateEdge's "now" binding),
+// falling back to time.Now if absent so a node missing the property decays
+// as "brand new" rather than as infinitely old.
+func firstSeenOf(v interface{}) time.Time {
+	millis := toInt(firstOfRaw(v))
+	if millis == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(int64(millis))
+}
+
+// upsertConversationElementsScript is UpsertConversationElements' single
+// batched traversal: a Groovy loop over the bound `elements`/`edges` lists,
+// so one network round trip merges an entire session instead of one per
+// element. Every value referenced from bound variables (elements, edges,
+// now) rather than interpolated into this literal, so user-authored
+// conversation content can never change the script's structure.
+const upsertConversationElementsScript = `
+def nodeIdByKey = [:];
+def nodesCreated = 0;
+def edgesCreated = 0;
+for (item in elements) {
+  def existed = g.V().has('userID', item.userID).has('type', item.type).has('normalizedContent', item.normalizedContent).has('dayBucket', item.dayBucket).hasNext();
+  def v = g.mergeV([(T.label): '` + elementVertexLabel + `', userID: item.userID, type: item.type, normalizedContent: item.normalizedContent, dayBucket: item.dayBucket])
+            .option(Merge.onCreate, [content: item.content, elementKey: item.key, firstSeen: now, lastSeen: now, count: 1])
+            .option(Merge.onMatch, [lastSeen: now, content: item.content])
+            .next();
+  if (existed) {
+    g.V(v).property(Cardinality.single, 'count', __.coalesce(__.values('count'), __.constant(0)).math('_ + 1')).iterate();
+  } else {
+    nodesCreated++;
+  }
+  nodeIdByKey[item.key] = v.id();
+}
+for (edge in edges) {
+  def fromId = nodeIdByKey[edge.fromKey];
+  def toId = nodeIdByKey[edge.toKey];
+  if (fromId == null || toId == null) { continue; }
+  def edgeExisted = g.V(fromId).outE(edge.label).where(__.inV().hasId(toId)).hasNext();
+  g.V(fromId).as('from').V(toId).as('to')
+    .mergeE([(T.label): edge.label])
+      .option(Merge.outV, __.select('from'))
+      .option(Merge.inV, __.select('to'))
+      .option(Merge.onCreate, [firstSeen: now, lastSeen: now, count: 1])
+      .option(Merge.onMatch, [lastSeen: now])
+    .next();
+  if (edgeExisted) {
+    g.V(fromId).outE(edge.label).where(__.inV().hasId(toId)).property(Cardinality.single, 'count', __.coalesce(__.values('count'), __.constant(0)).math('_ + 1')).iterate();
+  } else {
+    edgesCreated++;
+  }
+}
+[nodesCreated: nodesCreated, edgesCreated: edgesCreated]
+`
+
+// userSummaryVertexLabel holds one vertex per user storing the
+// session-processor's LLM-generated rolling summary (see UpdateUserGraph),
+// kept separate from elementVertexLabel nodes so writing it never disturbs
+// the Element nodes subgraphQueryScript reads.
+const userSummaryVertexLabel = "UserSummary"
+
+// updateUserSummaryScript upserts userID's single UserSummary vertex via
+// Merge.onCreate/onMatch, so repeated calls (a Lambda retry) overwrite the
+// same vertex instead of creating duplicates.
+const updateUserSummaryScript = `
+g.mergeV([(T.label): '` + userSummaryVertexLabel + `', userID: userID])
+  .option(Merge.onCreate, [text: summary, updatedAt: now])
+  .option(Merge.onMatch, [text: summary, updatedAt: now])
+  .next()
+`
+
+// storedSummaryScript reads back the rolling summary UpdateUserGraph wrote,
+// if any.
+const storedSummaryScript = `g.V().has('` + userSummaryVertexLabel + `', 'userID', userID).values('text')`
+
+// UpdateUserGraph persists an LLM-generated rolling summary for userID as a
+// single UserSummary vertex. data must be a map[string]interface{} with a
+// non-empty "summary" string key; anything else is ignored (matching
+// AWSS3Client.UpdateUserGraph's handling of an unsupported payload shape),
+// since UpsertConversationElements remains the entry point for everything
+// else this interface updates.
+func (c *GremlinNeptuneClient) UpdateUserGraph(ctx context.Context, userID string, data interface{}) error {
+	updates, ok := data.(map[string]interface{})
+	if !ok {
+		logging.FromContext(ctx).InfoContext(ctx, "neptune: UpdateUserGraph called with unsupported data type (ignored)",
+			"component", "gremlin_neptune", "user_id", userID, "data_type", fmt.Sprintf("%T", data))
+		return nil
+	}
+	summary, ok := updates["summary"].(string)
+	if !ok || summary == "" {
+		return nil
+	}
+
+	bindings := map[string]interface{}{
+		"userID":  userID,
+		"summary": summary,
+		"now":     time.Now().UnixMilli(),
+	}
+	if _, err := c.eval(ctx, updateUserSummaryScript, bindings); err != nil {
+		return fmt.Errorf("failed to update rolling summary for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// storedSummary returns userID's rolling summary written by UpdateUserGraph,
+// and false if none has ever been written.
+func (c *GremlinNeptuneClient) storedSummary(ctx context.Context, userID string) (string, bool, error) {
+	results, err := c.eval(ctx, storedSummaryScript, map[string]interface{}{"userID": userID})
+	if err != nil {
+		return "", false, fmt.Errorf("failed to query rolling summary for user %s: %w", userID, err)
+	}
+	if len(results) == 0 {
+		return "", false, nil
+	}
+	text, _ := results[0].(string)
+	return text, text != "", nil
+}
+
+// GetUserContext prefers an LLM-generated rolling summary written by
+// UpdateUserGraph, if one exists. Otherwise it runs a subgraph query scoped
+// to userID, bounded by c.recencyWindow and c.maxSubgraphNodes, and
+// summarizes the resulting Element nodes (ordered by most-recently-touched)
+// into a GraphContext. Returns a default empty-history context rather than
+// an error when the user has no nodes yet, matching MockNeptuneClient's
+// auto-create semantics.
+func (c *GremlinNeptuneClient) GetUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
+	if summary, ok, err := c.storedSummary(ctx, userID); err != nil {
+		return nil, err
+	} else if ok {
+		return &types.GraphContext{UserID: userID, LastUpdated: time.Now(), Summary: summary}, nil
+	}
+
+	cutoffMillis := time.Now().Add(-c.recencyWindow).UnixMilli()
+	bindings := map[string]interface{}{
+		"userID":       userID,
+		"cutoffMillis": cutoffMillis,
+		"maxNodes":     c.maxSubgraphNodes,
+	}
+
+	results, err := c.eval(ctx, subgraphQueryScript, bindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subgraph for user %s: %w", userID, err)
+	}
+
+	if len(results) == 0 {
+		return &types.GraphContext{
+			UserID:      userID,
+			LastUpdated: time.Now(),
+			Summary:     "New user - no conversation history yet",
+		}, nil
+	}
+
+	return &types.GraphContext{
+		UserID:      userID,
+		LastUpdated: time.Now(),
+		Summary:     summarizeSubgraph(results),
+	}, nil
+}
+
+// subgraphQueryScript bounds the returned subgraph by recency (lastSeen >=
+// cutoffMillis) and size (limit maxNodes), ordering by lastSeen so the most
+// relevant nodes survive the limit when a user has more history than the
+// budget allows.
+const subgraphQueryScript = `
+g.V().has('` + elementVertexLabel + `', 'userID', userID)
+  .has('lastSeen', gte(cutoffMillis))
+  .order().by('lastSeen', decr)
+  .limit(maxNodes)
+  .valueMap('type', 'content', 'count')
+`
+
+// summarizeSubgraph renders decoded valueMap results (each a
+// map[string]interface{} with single-element []interface{} values, per
+// Gremlin's valueMap convention) into a short human-readable summary for
+// GraphContext.Summary.
+func summarizeSubgraph(nodes []interface{}) string {
+	parts := make([]string, 0, len(nodes))
+	for _, raw := range nodes {
+		node, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nodeType := firstOf(node["type"])
+		content := firstOf(node["content"])
+		count := toInt(firstOfRaw(node["count"]))
+		if count > 1 {
+			parts = append(parts, fmt.Sprintf("%s: %s (x%d)", nodeType, content, count))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s", nodeType, content))
+		}
+	}
+	if len(parts) == 0 {
+		return "New user - no conversation history yet"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func firstOf(v interface{}) string {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v", list[0])
+}
+
+func firstOfRaw(v interface{}) interface{} {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil
+	}
+	return list[0]
+}
+
+// idOf reads the vertex id valueMap(true, ...) injects under the "id" key -
+// a bare scalar, unlike the requested properties which valueMap always
+// wraps in a single-element list.
+func idOf(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// GetNeighbors walks one hop out from nodeID in either direction, matching
+// MockNeptuneClient's undirected traversal. limit is applied server-side so
+// a highly-connected node can't pull back more context than the caller
+// budgeted for.
+func (c *GremlinNeptuneClient) GetNeighbors(ctx context.Context, userID string, nodeID string, limit int) ([]GraphNode, error) {
+	defer metrics.NeptuneContextLoadDuration.ObserveDuration(time.Now())
+
+	bindings := map[string]interface{}{"nodeID": nodeID, "limit": limit}
+	results, err := c.eval(ctx, `
+g.V(nodeID).both().limit(limit).valueMap(true, 'type', 'content', 'embedding', 'firstSeen')
+`, bindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get neighbors of node %s for user %s: %w", nodeID, userID, err)
+	}
+
+	neighbors := make([]GraphNode, 0, len(results))
+	for _, raw := range results {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		neighbors = append(neighbors, GraphNode{
+			ID:        idOf(fields["id"]),
+			UserID:    userID,
+			NodeType:  firstOf(fields["type"]),
+			Content:   firstOf(fields["content"]),
+			Embedding: decodeEmbedding(firstOfRaw(fields["embedding"])),
+			CreatedAt: firstSeenOf(fields["firstSeen"]),
+		})
+	}
+
+	return neighbors, nil
+}
+
+// RefreshUserContext re-runs the same bounded subgraph query as
+// GetUserContext; Neptune has no separate "refresh" concept.
+func (c *GremlinNeptuneClient) RefreshUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
+	return c.GetUserContext(ctx, userID)
+}
+
+// HealthCheck verifies the websocket connection is alive with a trivial
+// eval round trip.
+func (c *GremlinNeptuneClient) HealthCheck(ctx context.Context) error {
+	if _, err := c.eval(ctx, "g.inject(1)", nil); err != nil {
+		return fmt.Errorf("Neptune health check failed: %w", err)
+	}
+	return nil
+}
+
+// CreateUser adds a User anchor vertex for userID, failing if one already
+// exists so callers don't silently re-initialize existing history.
+func (c *GremlinNeptuneClient) CreateUser(ctx context.Context, userID string) error {
+	bindings := map[string]interface{}{"userID": userID, "now": time.Now().UnixMilli()}
+	results, err := c.eval(ctx, `
+existed = g.V().hasLabel('User').has('userID', userID).hasNext();
+if (existed) { 'exists' } else { g.addV('User').property('userID', userID).property('createdAt', now).next(); 'created' }
+`, bindings)
+	if err != nil {
+		return fmt.Errorf("failed to create user %s: %w", userID, err)
+	}
+	if len(results) > 0 && fmt.Sprintf("%v", results[0]) == "exists" {
+		return fmt.Errorf("user %s already exists", userID)
+	}
+	return nil
+}
+
+// DeleteUserData drops every Element vertex (and their incident edges)
+// belonging to userID, plus their User anchor vertex.
+func (c *GremlinNeptuneClient) DeleteUserData(ctx context.Context, userID string) error {
+	bindings := map[string]interface{}{"userID": userID}
+	if _, err := c.eval(ctx, `g.V().has('userID', userID).drop().iterate(); 'ok'`, bindings); err != nil {
+		return fmt.Errorf("failed to delete data for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// CreateNode merges a single Element node the same way
+// UpsertConversationElements does, for callers that have one element at a
+// time rather than a whole session's batch.
+func (c *GremlinNeptuneClient) CreateNode(ctx context.Context, userID string, nodeType string, content string) error {
+	_, _, err := c.UpsertConversationElements(ctx, userID, []types.ConversationElement{{
+		Type:      nodeType,
+		Content:   content,
+		Timestamp: time.Now(),
+	}})
+	return err
+}
+
+// CreateEdge merges a single typed edge between two existing nodes,
+// identified by their vertex ids nodeID/target. The element dedup key
+// (userID|type|normalizedContent|dayBucket) is not available here, so
+// vertices are not matched by it - callers that need dedup-key matching
+// should go through UpsertConversationElements.
+func (c *GremlinNeptuneClient) CreateEdge(userID string, nodeID string, edgeType string, target string) error {
+	bindings := map[string]interface{}{
+		"fromID": nodeID,
+		"toID":   target,
+		"label":  edgeType,
+		"now":    time.Now().UnixMilli(),
+	}
+	_, err := c.eval(context.Background(), `
+g.V(fromID).as('from').V(toID).as('to')
+  .mergeE([(T.label): label])
+    .option(Merge.outV, __.select('from'))
+    .option(Merge.inV, __.select('to'))
+    .option(Merge.onCreate, [firstSeen: now, lastSeen: now, count: 1])
+    .option(Merge.onMatch, [lastSeen: now])
+  .next()
+`, bindings)
+	if err != nil {
+		return fmt.Errorf("failed to create edge %s->%s (%s) for user %s: %w", nodeID, target, edgeType, userID, err)
+	}
+	return nil
+}
+
+// QuerySimilarNodes reads userID's Element nodes with a stored embedding
+// and ranks them in Go via the same cosine-similarity/age-decay scoring
+// MockNeptuneClient uses, since this engine's Gremlin endpoint has no
+// native vector index to push the ranking down to.
+func (c *GremlinNeptuneClient) QuerySimilarNodes(ctx context.Context, userID string, queryEmbedding []float32, k int, nodeTypes []string) ([]GraphNode, error) {
+	defer metrics.NeptuneContextLoadDuration.ObserveDuration(time.Now())
+
+	bindings := map[string]interface{}{"userID": userID}
+	results, err := c.eval(ctx, `
+g.V().has('`+elementVertexLabel+`', 'userID', userID).valueMap(true, 'type', 'content', 'embedding', 'firstSeen')
+`, bindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nodes for user %s: %w", userID, err)
+	}
+
+	typeFilter := make(map[string]bool, len(nodeTypes))
+	for _, t := range nodeTypes {
+		typeFilter[t] = true
+	}
+
+	type scoredNode struct {
+		node  GraphNode
+		score float32
+	}
+	var scored []scoredNode
+
+	for _, raw := range results {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		nodeType := firstOf(fields["type"])
+		if len(typeFilter) > 0 && !typeFilter[nodeType] {
+			continue
+		}
+
+		embedding := decodeEmbedding(firstOfRaw(fields["embedding"]))
+		if len(embedding) == 0 {
+			continue
+		}
+
+		node := GraphNode{
+			ID:        idOf(fields["id"]),
+			UserID:    userID,
+			NodeType:  nodeType,
+			Content:   firstOf(fields["content"]),
+			Embedding: embedding,
+			CreatedAt: firstSeenOf(fields["firstSeen"]),
+		}
+		similarity := cosineSimilarity(queryEmbedding, embedding)
+		scored = append(scored, scoredNode{node: node, score: similarity * ageDecayWeight(node.CreatedAt)})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	if k > len(scored) {
+		k = len(scored)
+	}
+	ranked := make([]GraphNode, k)
+	for i := 0; i < k; i++ {
+		ranked[i] = scored[i].node
+	}
+	return ranked, nil
+}
+
+// decodeEmbedding reads an embedding stored as a JSON-encoded []float32
+// string property (Gremlin/Neptune has no native float-array property
+// type), returning nil if absent or malformed.
+func decodeEmbedding(v interface{}) []float32 {
+	encoded, ok := v.(string)
+	if !ok || encoded == "" {
+		return nil
+	}
+	var embedding []float32
+	if err := json.Unmarshal([]byte(encoded), &embedding); err != nil {
+		return nil
+	}
+	return embedding
+}
+
+// Close closes the underlying websocket connection.
+func (c *GremlinNeptuneClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
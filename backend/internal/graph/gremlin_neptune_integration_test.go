@@ -0,0 +1,98 @@
+//go:build integration
+
+package graph
+
+import (
+	"context"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/types"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// These tests exercise GremlinNeptuneClient against a real Gremlin Server,
+// e.g.:
+//
+//	docker run -p 8182:8182 tinkerpop/gremlin-server:3.7
+//
+// Point GREMLIN_INTEGRATION_ENDPOINT/PORT at it (IAM auth is skipped since a
+// local gremlin-server has no IAM concept) and run with:
+//
+//	go test -tags integration ./internal/graph/...
+func newIntegrationClient(t *testing.T) *GremlinNeptuneClient {
+	t.Helper()
+
+	endpoint := os.Getenv("GREMLIN_INTEGRATION_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("GREMLIN_INTEGRATION_ENDPOINT not set, skipping Gremlin integration test")
+	}
+
+	client, err := NewGremlinNeptuneClient(context.Background(), config.NeptuneConfig{
+		Endpoint: endpoint,
+		Port:     getIntEnv(t, "GREMLIN_INTEGRATION_PORT", 8182),
+		Region:   "us-west-2",
+		IAMAuth:  false,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to Gremlin Server: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func getIntEnv(t *testing.T, key string, defaultValue int) int {
+	t.Helper()
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func TestGremlinNeptuneClient_HealthCheck(t *testing.T) {
+	client := newIntegrationClient(t)
+
+	if err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestGremlinNeptuneClient_UpsertConversationElementsDedupes(t *testing.T) {
+	client := newIntegrationClient(t)
+	ctx := context.Background()
+	userID := "integration-test-user-" + time.Now().UTC().Format("20060102150405")
+
+	elements := []types.ConversationElement{
+		{Type: "Event", Content: "Had a hard conversation with a friend", Timestamp: time.Now()},
+		{Type: "Feeling", Content: "Anxious", Timestamp: time.Now()},
+	}
+
+	nodesCreated, edgesCreated, err := client.UpsertConversationElements(ctx, userID, elements)
+	if err != nil {
+		t.Fatalf("first upsert failed: %v", err)
+	}
+	if nodesCreated != 2 {
+		t.Errorf("expected 2 nodes created, got %d", nodesCreated)
+	}
+	if edgesCreated != 1 {
+		t.Errorf("expected 1 edge created, got %d", edgesCreated)
+	}
+
+	// Re-submitting the same elements should merge into the existing nodes
+	// and edge rather than creating new ones.
+	nodesCreated, edgesCreated, err = client.UpsertConversationElements(ctx, userID, elements)
+	if err != nil {
+		t.Fatalf("second upsert failed: %v", err)
+	}
+	if nodesCreated != 0 {
+		t.Errorf("expected 0 nodes created on re-submit, got %d", nodesCreated)
+	}
+	if edgesCreated != 0 {
+		t.Errorf("expected 0 edges created on re-submit, got %d", edgesCreated)
+	}
+
+	t.Cleanup(func() { _ = client.DeleteUserData(ctx, userID) })
+}
@@ -3,26 +3,74 @@ package graph
 import (
 	"context"
 	"fmt"
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/logging"
 	"innerworld-backend/internal/types"
-	"log"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// nodeAgeDecayHalfLife controls QuerySimilarNodes' age-decay weighting: a
+// node this old contributes half the similarity score of a brand-new one at
+// the same raw cosine similarity, so recent reflections rank above stale
+// ones with an otherwise-similar match.
+const nodeAgeDecayHalfLife = 30 * 24 * time.Hour
+
+// Embedder generates vector embeddings for node content. MockNeptuneClient
+// takes one as an optional dependency so CreateNode can store real
+// `text-embedding-3-small` vectors without the graph package importing a
+// concrete OpenAI client into its production interface.
+type Embedder interface {
+	GenerateEmbedding(ctx context.Context, text string) (*embeddings.EmbeddingResult, error)
+}
+
+// mockEdge is a single CreateEdge call recorded against userID, so
+// GetNeighbors has something to traverse.
+type mockEdge struct {
+	NodeID   string
+	EdgeType string
+	Target   string
+}
+
 // MockNeptuneClient implements basic NeptuneClient interface for development
 type MockNeptuneClient struct {
-	users map[string]*types.GraphContext
+	users       map[string]*types.GraphContext
+	nodesByUser map[string][]GraphNode
+	edgesByUser map[string][]mockEdge
+	mutex       sync.RWMutex
+
+	// embedder is nil unless the client was created with
+	// NewMockNeptuneClientWithEmbedder, in which case CreateNode embeds node
+	// content; otherwise nodes are stored without vectors.
+	embedder Embedder
 }
 
 // NewMockNeptuneClient creates a new mock Neptune client
 func NewMockNeptuneClient() *MockNeptuneClient {
 	return &MockNeptuneClient{
-		users: make(map[string]*types.GraphContext),
+		users:       make(map[string]*types.GraphContext),
+		nodesByUser: make(map[string][]GraphNode),
+		edgesByUser: make(map[string][]mockEdge),
 	}
 }
 
+// NewMockNeptuneClientWithEmbedder creates a mock Neptune client that embeds
+// node content through embedder as it's written, so QuerySimilarNodes has
+// real vectors to rank against.
+func NewMockNeptuneClientWithEmbedder(embedder Embedder) *MockNeptuneClient {
+	client := NewMockNeptuneClient()
+	client.embedder = embedder
+	return client
+}
+
 // GetUserContext returns mock user context data
 func (m *MockNeptuneClient) GetUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
-	log.Printf("[MOCK NEPTUNE] Getting context for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: getting user context", "component", "mock_neptune", "user_id", userID)
 
 	// Return existing context or create default
 	if context, exists := m.users[userID]; exists {
@@ -40,27 +88,47 @@ func (m *MockNeptuneClient) GetUserContext(ctx context.Context, userID string) (
 	return defaultContext, nil
 }
 
-// UpdateUserGraph is a placeholder for updating user graph (not implemented in Phase 1)
+// UpdateUserGraph persists an LLM-generated rolling summary for userID,
+// mirroring GremlinNeptuneClient.UpdateUserGraph's contract: data must be a
+// map[string]interface{} with a non-empty "summary" string key, anything
+// else is ignored.
 func (m *MockNeptuneClient) UpdateUserGraph(ctx context.Context, userID string, data interface{}) error {
-	log.Printf("[MOCK NEPTUNE] UpdateUserGraph called for user: %s (not implemented in Phase 1)", userID)
+	updates, ok := data.(map[string]interface{})
+	if !ok {
+		logging.FromContext(ctx).InfoContext(ctx, "mock neptune: UpdateUserGraph called with unsupported data type (ignored)", "component", "mock_neptune", "user_id", userID)
+		return nil
+	}
+	summary, ok := updates["summary"].(string)
+	if !ok || summary == "" {
+		return nil
+	}
+
+	context, err := m.GetUserContext(ctx, userID)
+	if err != nil {
+		return err
+	}
+	context.Summary = summary
+	context.LastUpdated = time.Now()
+
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: updated rolling summary", "component", "mock_neptune", "user_id", userID)
 	return nil
 }
 
 // RefreshUserContext returns current context
 func (m *MockNeptuneClient) RefreshUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
-	log.Printf("[MOCK NEPTUNE] Refreshing context for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: refreshing user context", "component", "mock_neptune", "user_id", userID)
 	return m.GetUserContext(ctx, userID)
 }
 
 // HealthCheck simulates Neptune connectivity check
 func (m *MockNeptuneClient) HealthCheck(ctx context.Context) error {
-	log.Println("[MOCK NEPTUNE] Health check - OK")
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: health check ok", "component", "mock_neptune")
 	return nil
 }
 
 // CreateUser initializes mock user
 func (m *MockNeptuneClient) CreateUser(ctx context.Context, userID string) error {
-	log.Printf("[MOCK NEPTUNE] Creating new user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: creating new user", "component", "mock_neptune", "user_id", userID)
 
 	if _, exists := m.users[userID]; exists {
 		return fmt.Errorf("user %s already exists", userID)
@@ -72,7 +140,182 @@ func (m *MockNeptuneClient) CreateUser(ctx context.Context, userID string) error
 
 // DeleteUserData removes mock user data
 func (m *MockNeptuneClient) DeleteUserData(ctx context.Context, userID string) error {
-	log.Printf("[MOCK NEPTUNE] Deleting data for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: deleting user data", "component", "mock_neptune", "user_id", userID)
 	delete(m.users, userID)
+
+	m.mutex.Lock()
+	delete(m.nodesByUser, userID)
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// CreateNode creates a mock node for the user and, when an embedder is
+// configured, stores its `text-embedding-3-small` vector alongside it so
+// QuerySimilarNodes can rank it later.
+func (m *MockNeptuneClient) CreateNode(ctx context.Context, userID string, nodeType string, content string) error {
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: creating node", "component", "mock_neptune", "user_id", userID, "node_type", nodeType)
+
+	node := GraphNode{
+		ID:        "node_" + uuid.New().String()[:8],
+		UserID:    userID,
+		NodeType:  nodeType,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+
+	if m.embedder != nil {
+		result, err := m.embedder.GenerateEmbedding(ctx, content)
+		if err != nil {
+			return fmt.Errorf("failed to embed node content: %w", err)
+		}
+		node.Embedding = result.Embedding
+	}
+
+	m.mutex.Lock()
+	m.nodesByUser[userID] = append(m.nodesByUser[userID], node)
+	m.mutex.Unlock()
+
 	return nil
 }
+
+// CreateEdge records a mock edge between two nodes so GetNeighbors can
+// traverse it later.
+func (m *MockNeptuneClient) CreateEdge(userID string, nodeID string, edgeType string, target string) error {
+	slog.Default().Info("mock neptune: creating edge", "component", "mock_neptune", "user_id", userID, "edge_type", edgeType, "node_id", nodeID, "target", target)
+
+	m.mutex.Lock()
+	m.edgesByUser[userID] = append(m.edgesByUser[userID], mockEdge{NodeID: nodeID, EdgeType: edgeType, Target: target})
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// UpsertConversationElements stores each element as a mock node, same as a
+// CreateNode call per element - this mock has no dedup index, so every call
+// creates a fresh node (GremlinNeptuneClient is what actually dedupes via
+// ElementDedupKey).
+func (m *MockNeptuneClient) UpsertConversationElements(ctx context.Context, userID string, elements []types.ConversationElement) (int, int, error) {
+	logging.FromContext(ctx).InfoContext(ctx, "mock neptune: upserting conversation elements", "component", "mock_neptune", "user_id", userID, "element_count", len(elements))
+
+	for _, element := range elements {
+		if err := m.CreateNode(ctx, userID, element.Type, element.Content); err != nil {
+			return 0, 0, fmt.Errorf("failed to create mock node for user %s: %w", userID, err)
+		}
+	}
+	return len(elements), 0, nil
+}
+
+// QuerySimilarNodes ranks userID's embedded nodes by cosine similarity to
+// queryEmbedding, applying an exponential age-decay so two nodes with
+// similar raw similarity favor the more recent one, and returns the top k.
+// Nodes with no embedding (created without an Embedder) are skipped.
+func (m *MockNeptuneClient) QuerySimilarNodes(ctx context.Context, userID string, queryEmbedding []float32, k int, nodeTypes []string) ([]GraphNode, error) {
+	m.mutex.RLock()
+	candidates := append([]GraphNode(nil), m.nodesByUser[userID]...)
+	m.mutex.RUnlock()
+
+	typeFilter := make(map[string]bool, len(nodeTypes))
+	for _, t := range nodeTypes {
+		typeFilter[t] = true
+	}
+
+	type scoredNode struct {
+		node  GraphNode
+		score float32
+	}
+
+	scored := make([]scoredNode, 0, len(candidates))
+	for _, node := range candidates {
+		if len(typeFilter) > 0 && !typeFilter[node.NodeType] {
+			continue
+		}
+		if len(node.Embedding) == 0 {
+			continue
+		}
+
+		similarity := cosineSimilarity(queryEmbedding, node.Embedding)
+		scored = append(scored, scoredNode{node: node, score: similarity * ageDecayWeight(node.CreatedAt)})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	if k > len(scored) {
+		k = len(scored)
+	}
+
+	results := make([]GraphNode, k)
+	for i := 0; i < k; i++ {
+		results[i] = scored[i].node
+	}
+
+	return results, nil
+}
+
+// GetNeighbors returns up to limit nodes connected to nodeID by an edge in
+// either direction, matching GremlinNeptuneClient's both()-direction
+// traversal.
+func (m *MockNeptuneClient) GetNeighbors(ctx context.Context, userID string, nodeID string, limit int) ([]GraphNode, error) {
+	m.mutex.RLock()
+	edges := m.edgesByUser[userID]
+	nodes := m.nodesByUser[userID]
+	m.mutex.RUnlock()
+
+	neighborIDs := make(map[string]bool)
+	for _, edge := range edges {
+		switch nodeID {
+		case edge.NodeID:
+			neighborIDs[edge.Target] = true
+		case edge.Target:
+			neighborIDs[edge.NodeID] = true
+		}
+	}
+
+	neighbors := make([]GraphNode, 0, len(neighborIDs))
+	for _, node := range nodes {
+		if !neighborIDs[node.ID] {
+			continue
+		}
+		neighbors = append(neighbors, node)
+		if len(neighbors) >= limit {
+			break
+		}
+	}
+
+	return neighbors, nil
+}
+
+// ageDecayWeight returns a value in (0, 1] that falls off exponentially with
+// age, halving every nodeAgeDecayHalfLife.
+func ageDecayWeight(createdAt time.Time) float32 {
+	age := time.Since(createdAt)
+	if age <= 0 {
+		return 1.0
+	}
+	halfLives := float64(age) / float64(nodeAgeDecayHalfLife)
+	return float32(math.Pow(0.5, halfLives))
+}
+
+// cosineSimilarity computes cosine similarity between two equal-length
+// vectors, mirroring embeddings.OpenAIEmbeddingsClient.CalculateCosineSimilarity
+// without requiring a client instance to call it.
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0.0
+	}
+
+	var dotProduct, normA, normB float64
+	for i := range a {
+		dotProduct += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0.0 || normB == 0.0 {
+		return 0.0
+	}
+
+	return float32(dotProduct / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
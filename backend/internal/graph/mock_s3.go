@@ -3,8 +3,9 @@ package graph
 import (
 	"context"
 	"fmt"
+	"innerworld-backend/internal/logging"
 	"innerworld-backend/internal/types"
-	"log"
+	"log/slog"
 	"time"
 )
 
@@ -22,7 +23,7 @@ func NewMockS3Client() *MockS3Client {
 
 // GetUserContext returns mock user context data
 func (m *MockS3Client) GetUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
-	log.Printf("[MOCK S3] Getting context for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: getting user context", "component", "mock_s3", "user_id", userID)
 
 	// Return existing context or create default
 	if context, exists := m.users[userID]; exists {
@@ -42,25 +43,25 @@ func (m *MockS3Client) GetUserContext(ctx context.Context, userID string) (*type
 
 // UpdateUserGraph is a placeholder for updating user graph (not implemented in Phase 1)
 func (m *MockS3Client) UpdateUserGraph(ctx context.Context, userID string, data interface{}) error {
-	log.Printf("[MOCK S3] UpdateUserGraph called for user: %s (not implemented in Phase 1)", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: UpdateUserGraph not implemented in Phase 1", "component", "mock_s3", "user_id", userID)
 	return nil
 }
 
 // RefreshUserContext returns current context
 func (m *MockS3Client) RefreshUserContext(ctx context.Context, userID string) (*types.GraphContext, error) {
-	log.Printf("[MOCK S3] Refreshing context for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: refreshing user context", "component", "mock_s3", "user_id", userID)
 	return m.GetUserContext(ctx, userID)
 }
 
 // HealthCheck simulates S3 connectivity check
 func (m *MockS3Client) HealthCheck(ctx context.Context) error {
-	log.Println("[MOCK S3] Health check - OK")
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: health check ok", "component", "mock_s3")
 	return nil
 }
 
 // CreateUser initializes mock user
 func (m *MockS3Client) CreateUser(ctx context.Context, userID string) error {
-	log.Printf("[MOCK S3] Creating new user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: creating new user", "component", "mock_s3", "user_id", userID)
 
 	if _, exists := m.users[userID]; exists {
 		return fmt.Errorf("user %s already exists", userID)
@@ -72,21 +73,21 @@ func (m *MockS3Client) CreateUser(ctx context.Context, userID string) error {
 
 // DeleteUserData removes mock user data
 func (m *MockS3Client) DeleteUserData(ctx context.Context, userID string) error {
-	log.Printf("[MOCK S3] Deleting data for user: %s", userID)
+	logging.FromContext(ctx).InfoContext(ctx, "mock s3: deleting user data", "component", "mock_s3", "user_id", userID)
 	delete(m.users, userID)
 	return nil
 }
 
 // CreateNode creates a mock node in S3
 func (m *MockS3Client) CreateNode(userID string, nodeType string, content string) error {
-	log.Printf("[MOCK S3] Creating %s node for user %s: %s", nodeType, userID, content)
+	slog.Default().Info("mock s3: creating node", "component", "mock_s3", "user_id", userID, "node_type", nodeType)
 	// Mock implementation - just log the operation
 	return nil
 }
 
 // CreateEdge creates a mock edge in S3
 func (m *MockS3Client) CreateEdge(userID string, nodeID string, edgeType string, target string) error {
-	log.Printf("[MOCK S3] Creating %s edge for user %s: %s -> %s", edgeType, userID, nodeID, target)
+	slog.Default().Info("mock s3: creating edge", "component", "mock_s3", "user_id", userID, "edge_type", edgeType, "node_id", nodeID, "target", target)
 	// Mock implementation - just log the operation
 	return nil
 }
@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"innerworld-backend/internal/types"
+	"time"
 )
 
 // NeptuneClient interface defines Neptune operations for Phase 1 & 2
@@ -10,7 +11,10 @@ type NeptuneClient interface {
 	// GetUserContext retrieves basic user context
 	GetUserContext(ctx context.Context, userID string) (*types.GraphContext, error)
 
-	// UpdateUserGraph placeholder for future graph updates
+	// UpdateUserGraph applies an out-of-band update to userID's graph data.
+	// Implementations only recognize a map[string]interface{} with a
+	// "summary" key (an LLM-generated rolling summary, as session-processor
+	// writes after each session); any other shape is ignored.
 	UpdateUserGraph(ctx context.Context, userID string, data interface{}) error
 
 	// RefreshUserContext updates cached context
@@ -26,12 +30,47 @@ type NeptuneClient interface {
 	DeleteUserData(ctx context.Context, userID string) error
 
 	// Phase 2 additions for session processing
-	CreateNode(userID string, nodeType string, content string) error
+	CreateNode(ctx context.Context, userID string, nodeType string, content string) error
 	CreateEdge(userID string, nodeID string, edgeType string, target string) error
+
+	// QuerySimilarNodes ranks userID's nodes by similarity to queryEmbedding
+	// and returns the top k, optionally restricted to nodeTypes (a nil or
+	// empty slice matches every node type). Powers GraphRAG context
+	// retrieval for persona prompts.
+	QuerySimilarNodes(ctx context.Context, userID string, queryEmbedding []float32, k int, nodeTypes []string) ([]GraphNode, error)
+
+	// GetNeighbors returns up to limit nodes one hop away from nodeID via
+	// any edge, in either direction. Used to expand a small set of
+	// vector/BM25-ranked seed nodes with their immediate graph context
+	// (retrieval.HybridRetriever's final fusion step).
+	GetNeighbors(ctx context.Context, userID string, nodeID string, limit int) ([]GraphNode, error)
+
+	// UpsertConversationElements merges a whole session's extracted
+	// elements into the graph as a single batched operation, deduping
+	// repeated Feelings/Values by (userID, type, normalizedContent, dayBucket)
+	// instead of creating a new node every session, and returns how many
+	// nodes and edges were newly created (as opposed to merged into an
+	// existing one). The merge keys are deterministic, so retrying a call
+	// with the same elements (e.g. a Lambda retry) merges into the same
+	// nodes and edges (updating their lastSeen/count) rather than creating
+	// duplicates.
+	UpsertConversationElements(ctx context.Context, userID string, elements []types.ConversationElement) (nodesCreated int, edgesCreated int, err error)
+}
+
+// GraphNode is a single node read back from Neptune, with the vector
+// embedding stored alongside its content so QuerySimilarNodes can rank by
+// similarity without re-embedding on every read.
+type GraphNode struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	NodeType  string    `json:"node_type"`
+	Content   string    `json:"content"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-// Config holds basic Neptune connection configuration
-type Config struct {
+// NeptuneConfig holds basic Neptune connection configuration
+type NeptuneConfig struct {
 	Endpoint string `json:"endpoint"`
 	Port     int    `json:"port"`
 	Region   string `json:"region"`
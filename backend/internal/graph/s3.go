@@ -30,8 +30,8 @@ type S3Client interface {
 	CreateEdge(userID string, nodeID string, edgeType string, target string) error
 }
 
-// Config holds basic S3 connection configuration
-type Config struct {
+// S3Config holds basic S3 connection configuration
+type S3Config struct {
 	Bucket string `json:"bucket"`
 	Region string `json:"region"`
 }
@@ -0,0 +1,93 @@
+// Package health runs deep connectivity probes against InnerWorld's
+// downstream dependencies (S3, DynamoDB, OpenRouter, OpenAI), each wrapped in its own
+// circuit breaker so a struggling dependency stops being hammered on every
+// request: during its cool-down the probe fails immediately, without a call,
+// and its ProbeResult carries the breaker's state.
+package health
+
+import (
+	"context"
+	"innerworld-backend/internal/graph"
+	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/storage"
+)
+
+// Prober is a single dependency a deep health check can verify directly.
+type Prober interface {
+	// Name identifies the dependency in ProbeResult and breaker registry
+	// keys (e.g. "s3", "openrouter", "openai").
+	Name() string
+
+	// Probe makes one lightweight call to the dependency, returning an
+	// error if it's unreachable or misconfigured.
+	Probe(ctx context.Context) error
+}
+
+// S3Prober probes S3 connectivity via the same HealthCheck S3Client already
+// exposes for the shallow check.
+type S3Prober struct {
+	client graph.S3Client
+}
+
+// NewS3Prober creates a Prober around an existing S3Client.
+func NewS3Prober(client graph.S3Client) *S3Prober {
+	return &S3Prober{client: client}
+}
+
+func (p *S3Prober) Name() string { return "s3" }
+
+func (p *S3Prober) Probe(ctx context.Context) error {
+	return p.client.HealthCheck(ctx)
+}
+
+// OpenRouterProber probes OpenRouter's /models endpoint, which costs no
+// completion tokens - unlike OpenRouterBackend.HealthCheck's "ping" request,
+// which is meant for BackendRouter failover decisions, not cheap polling.
+type OpenRouterProber struct {
+	client *llm.OpenRouterClient
+}
+
+// NewOpenRouterProber creates a Prober around an existing OpenRouterClient.
+func NewOpenRouterProber(client *llm.OpenRouterClient) *OpenRouterProber {
+	return &OpenRouterProber{client: client}
+}
+
+func (p *OpenRouterProber) Name() string { return "openrouter" }
+
+func (p *OpenRouterProber) Probe(ctx context.Context) error {
+	return p.client.CheckModelsEndpoint(ctx)
+}
+
+// DynamoDBProber probes DynamoDB connectivity via the same HealthCheck
+// DynamoDBClient already exposes for the shallow check.
+type DynamoDBProber struct {
+	client storage.DynamoDBClient
+}
+
+// NewDynamoDBProber creates a Prober around an existing DynamoDBClient.
+func NewDynamoDBProber(client storage.DynamoDBClient) *DynamoDBProber {
+	return &DynamoDBProber{client: client}
+}
+
+func (p *DynamoDBProber) Name() string { return "dynamodb" }
+
+func (p *DynamoDBProber) Probe(ctx context.Context) error {
+	return p.client.HealthCheck(ctx)
+}
+
+// OpenAIProber probes OpenAI's /v1/models endpoint via OpenAIBackend's
+// existing lightweight HealthCheck.
+type OpenAIProber struct {
+	backend *llm.OpenAIBackend
+}
+
+// NewOpenAIProber creates a Prober around an existing OpenAIBackend.
+func NewOpenAIProber(backend *llm.OpenAIBackend) *OpenAIProber {
+	return &OpenAIProber{backend: backend}
+}
+
+func (p *OpenAIProber) Name() string { return "openai" }
+
+func (p *OpenAIProber) Probe(ctx context.Context) error {
+	return p.backend.HealthCheck(ctx)
+}
@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// minBackoff is the polling interval Register falls back to when given none;
+// maxBackoff caps a probeState's exponential backoff once a dependency
+// starts failing: wait doubles from the probe's interval on each
+// consecutive failure (1s -> 2s -> 4s -> ... for a 1s interval) and is
+// capped at maxBackoff, so a struggling dependency is polled less often
+// instead of being hammered every interval.
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 60 * time.Second
+)
+
+// probeState is one registered Prober's background-polled, cached state.
+// Reads never block on a live probe call - HandlerFuncs built on top of
+// Registry.Snapshot always see the last completed probe's result.
+type probeState struct {
+	probe    Prober
+	interval time.Duration // steady-state interval once healthy again
+
+	mu   sync.RWMutex
+	wait time.Duration
+	last ProbeResult
+}
+
+// Registry runs every registered Prober on its own background goroutine,
+// polling at its configured interval while healthy and backing off
+// exponentially after consecutive failures, so a request to /readyz never
+// blocks on a slow or unreachable dependency - it just reads the last
+// cached ProbeResult.
+type Registry struct {
+	mu     sync.RWMutex
+	states []*probeState
+}
+
+// NewRegistry creates an empty Registry. Register probes into it before
+// mounting NewMux's handlers.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds probe to the registry and immediately starts its background
+// polling goroutine, probing once right away so Snapshot has a result before
+// the first interval elapses. The goroutine stops when ctx is cancelled.
+func (r *Registry) Register(ctx context.Context, probe Prober, interval time.Duration) {
+	if interval <= 0 {
+		interval = minBackoff
+	}
+
+	state := &probeState{probe: probe, interval: interval, wait: interval}
+
+	r.mu.Lock()
+	r.states = append(r.states, state)
+	r.mu.Unlock()
+
+	go state.run(ctx)
+}
+
+func (s *probeState) run(ctx context.Context) {
+	s.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.currentWait()):
+			s.poll(ctx)
+		}
+	}
+}
+
+func (s *probeState) poll(ctx context.Context) {
+	start := time.Now()
+	err := s.probe.Probe(ctx)
+	latency := time.Since(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.wait = nextBackoff(s.wait)
+		s.last = ProbeResult{
+			Name:    s.probe.Name(),
+			Healthy: false,
+			Error:   err.Error(),
+			Latency: latency.String(),
+			// Preserve the last known success time across failures, so a
+			// reader can tell "never seen healthy" apart from "was healthy
+			// until N minutes ago".
+			LastSuccess: s.last.LastSuccess,
+		}
+		return
+	}
+
+	s.wait = s.interval
+	s.last = ProbeResult{
+		Name:        s.probe.Name(),
+		Healthy:     true,
+		Latency:     latency.String(),
+		LastSuccess: time.Now(),
+	}
+}
+
+func (s *probeState) currentWait() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.wait
+}
+
+// nextBackoff doubles current, capped at maxBackoff.
+func nextBackoff(current time.Duration) time.Duration {
+	next := current * 2
+	if next <= 0 || next > maxBackoff {
+		return maxBackoff
+	}
+	return next
+}
+
+// Snapshot returns every registered probe's last cached result, in
+// registration order. Safe to call from an HTTP handler - it never blocks
+// on a live probe.
+func (r *Registry) Snapshot() []ProbeResult {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]ProbeResult, len(r.states))
+	for i, s := range r.states {
+		s.mu.RLock()
+		results[i] = s.last
+		s.mu.RUnlock()
+	}
+	return results
+}
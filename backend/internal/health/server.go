@@ -0,0 +1,88 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Report is the aggregated health document served at /health: an overall
+// status plus one ServiceStatus per registered probe, built from Registry's
+// cached state.
+type Report struct {
+	Status    string                   `json:"status"`
+	Timestamp string                   `json:"timestamp"`
+	Services  map[string]ServiceStatus `json:"services"`
+}
+
+// ServiceStatus is one dependency's last cached probe outcome, shaped for
+// the /health JSON response.
+type ServiceStatus struct {
+	Status      string `json:"status"` // "healthy" | "unhealthy"
+	Latency     string `json:"latency,omitempty"`
+	Error       string `json:"error,omitempty"`
+	LastSuccess string `json:"lastSuccess,omitempty"`
+}
+
+// NewMux builds the health-check service's HTTP surface:
+//
+//   - /healthz - liveness: 200 as long as the process is running. Never
+//     touches registry, so it stays cheap even if every dependency is down.
+//   - /readyz  - readiness: 200 only if every registered probe's last
+//     cached result was healthy, 503 otherwise.
+//   - /health  - the aggregated JSON report (same data as /readyz, kept as
+//     its own route for existing monitoring that expects this path).
+func NewMux(registry *Registry) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleLiveness)
+	mux.HandleFunc("/readyz", handleReport(registry))
+	mux.HandleFunc("/health", handleReport(registry))
+	return mux
+}
+
+func handleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func handleReport(registry *Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := BuildReport(registry)
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != "healthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// BuildReport snapshots registry and shapes it into a Report, exported so
+// a Lambda handler can reuse the same aggregation logic instead of
+// re-implementing it alongside the HTTP server's handlers.
+func BuildReport(registry *Registry) Report {
+	results := registry.Snapshot()
+
+	services := make(map[string]ServiceStatus, len(results))
+	status := "healthy"
+	for _, result := range results {
+		s := ServiceStatus{Latency: result.Latency}
+		if result.Healthy {
+			s.Status = "healthy"
+		} else {
+			s.Status = "unhealthy"
+			s.Error = result.Error
+			status = "unhealthy"
+		}
+		if !result.LastSuccess.IsZero() {
+			s.LastSuccess = result.LastSuccess.UTC().Format(time.RFC3339)
+		}
+		services[result.Name] = s
+	}
+
+	return Report{
+		Status:    status,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Services:  services,
+	}
+}
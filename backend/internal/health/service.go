@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"innerworld-backend/internal/resilience"
+	"sync"
+	"time"
+)
+
+// BreakerProbe pairs a Prober with the circuit breaker guarding it. Probes
+// share no breaker state with each other, so one dependency tripping open
+// doesn't affect the others' probes.
+type BreakerProbe struct {
+	Prober  Prober
+	Breaker *resilience.CircuitBreaker
+}
+
+// NewBreakerProbe wraps prober with a breaker built from config.
+func NewBreakerProbe(prober Prober, config resilience.BreakerConfig) BreakerProbe {
+	return BreakerProbe{Prober: prober, Breaker: resilience.NewCircuitBreaker(config)}
+}
+
+// ProbeResult is one dependency's outcome from a deep health check,
+// including enough circuit breaker state for an operator to tell "it's down
+// right now" apart from "we've stopped checking until the cool-down elapses".
+type ProbeResult struct {
+	Name         string    `json:"name"`
+	Healthy      bool      `json:"healthy"`
+	Error        string    `json:"error,omitempty"`
+	Latency      string    `json:"latency"`
+	BreakerState string    `json:"breakerState"`
+	LastSuccess  time.Time `json:"lastSuccess,omitempty"`
+	FailureRate  float64   `json:"failureRate"`
+}
+
+// RunDeep runs every probe concurrently, each under its own breaker and a
+// context.WithTimeout derived from timeout, and returns one ProbeResult per
+// probe in the same order probes was given.
+func RunDeep(ctx context.Context, probes []BreakerProbe, timeout time.Duration) []ProbeResult {
+	results := make([]ProbeResult, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe BreakerProbe) {
+			defer wg.Done()
+			results[i] = runOne(ctx, probe, timeout)
+		}(i, probe)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func runOne(ctx context.Context, probe BreakerProbe, timeout time.Duration) ProbeResult {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe.Breaker.Execute(probeCtx, func() error {
+		return probe.Prober.Probe(probeCtx)
+	})
+	latency := time.Since(start)
+
+	stats := probe.Breaker.Stats()
+	result := ProbeResult{
+		Name:         probe.Prober.Name(),
+		Healthy:      err == nil,
+		Latency:      latency.String(),
+		BreakerState: stats.State.String(),
+		LastSuccess:  stats.LastSuccess,
+		FailureRate:  stats.FailureRate,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
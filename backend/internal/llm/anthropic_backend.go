@@ -0,0 +1,201 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultAnthropicModel is a quality/cost tier comparable to the other
+// backends' defaults.
+const defaultAnthropicModel = "claude-3-5-sonnet-20241022"
+
+const defaultAnthropicMaxTokens = 150
+
+// AnthropicBackend calls Anthropic's Messages API directly. There's no
+// official Anthropic Go SDK vendored in this module, so this talks HTTP
+// directly the same way OpenAIModerator does for OpenAI's moderation
+// endpoint.
+type AnthropicBackend struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+// NewAnthropicBackend creates an Anthropic backend using the default model.
+func NewAnthropicBackend(apiKey string) *AnthropicBackend {
+	return &AnthropicBackend{
+		apiKey:    apiKey,
+		baseURL:   "https://api.anthropic.com/v1",
+		model:     defaultAnthropicModel,
+		maxTokens: defaultAnthropicMaxTokens,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetModel overrides the Claude model used for Generate/GenerateStream.
+func (b *AnthropicBackend) SetModel(model string) {
+	b.model = model
+}
+
+// Name identifies this backend as "anthropic".
+func (b *AnthropicBackend) Name() string {
+	return "anthropic"
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	MaxTokens int                `json:"max_tokens"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Generate calls the Messages API and translates the response into the
+// shared ChatResponse shape.
+func (b *AnthropicBackend) Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	payload, err := json.Marshal(anthropicRequest{
+		Model:     b.model,
+		System:    systemPrompt,
+		MaxTokens: b.maxTokens,
+		Messages:  []anthropicMessage{{Role: "user", Content: userMessage}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Anthropic request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/messages", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Anthropic request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", b.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Anthropic API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Anthropic response: %w", err)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+
+	return &ChatResponse{
+		ID:      result.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   result.Model,
+		Choices: []ChatChoice{
+			{Index: 0, Message: ChatMessage{Role: "assistant", Content: text.String()}, FinishReason: result.StopReason},
+		},
+		Usage: Usage{
+			PromptTokens:     result.Usage.InputTokens,
+			CompletionTokens: result.Usage.OutputTokens,
+			TotalTokens:      result.Usage.InputTokens + result.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// GenerateStream fakes streaming over the full response, consistent with
+// the other backends until real SSE streaming is wired up.
+func (b *AnthropicBackend) GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error) {
+	response, err := b.Generate(ctx, systemPrompt, userMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	content := ""
+	if len(response.Choices) > 0 {
+		content = response.Choices[0].Message.Content
+	}
+
+	deltas := make(chan string)
+	go func() {
+		defer close(deltas)
+		for _, word := range strings.Fields(content) {
+			select {
+			case deltas <- word + " ":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return deltas, nil
+}
+
+// HealthCheck sends a minimal one-token message to confirm the API key and
+// endpoint are reachable; Anthropic has no dedicated health endpoint.
+func (b *AnthropicBackend) HealthCheck(ctx context.Context) error {
+	payload, err := json.Marshal(anthropicRequest{
+		Model:     b.model,
+		MaxTokens: 1,
+		Messages:  []anthropicMessage{{Role: "user", Content: "ping"}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal health check request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/messages", bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", b.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("anthropic health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("anthropic health check returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
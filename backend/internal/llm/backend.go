@@ -0,0 +1,28 @@
+package llm
+
+import "context"
+
+// Backend is implemented by anything that can generate a conversational
+// response from a system prompt and a user message. ConversationChain
+// depends on this instead of a concrete *OpenRouterClient so additional
+// providers (direct OpenAI, Anthropic, a self-hosted gRPC backend) can be
+// swapped in, or chained behind a BackendRouter, without workflow code
+// knowing which one actually served the request.
+type Backend interface {
+	// Name identifies the backend for logging, metrics, and persona pinning
+	// (e.g. "openrouter", "openai", "anthropic", "grpc-local").
+	Name() string
+
+	// Generate produces a full response for userMessage given systemPrompt.
+	Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error)
+
+	// GenerateStream produces the response incrementally as plain text
+	// deltas. Backends without real token-level streaming may emit the full
+	// response as a single item, the same simplification
+	// workflow.ConversationChain.streamPersonaResponse already makes.
+	GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error)
+
+	// HealthCheck reports whether the backend is currently reachable, used
+	// by BackendRouter to skip backends it already knows are down.
+	HealthCheck(ctx context.Context) error
+}
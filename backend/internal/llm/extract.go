@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// defaultMaxRepairAttempts bounds StructuredExtract's repair loop: this many
+// attempts in total, the initial one plus retries asking the model to fix
+// whatever the schema validator rejected, before giving up.
+const defaultMaxRepairAttempts = 3
+
+// Extractor pairs a Router with the TaskProfile StructuredExtract should use
+// for every call it makes, so callers configure model preference/cost once
+// instead of on every StructuredExtract call site.
+type Extractor struct {
+	router     *Router
+	profile    TaskProfile
+	maxRepairs int
+}
+
+// NewExtractor creates an Extractor issuing every call through router under
+// profile.
+func NewExtractor(router *Router, profile TaskProfile) *Extractor {
+	return &Extractor{
+		router:     router,
+		profile:    profile,
+		maxRepairs: defaultMaxRepairAttempts,
+	}
+}
+
+// SetMaxRepairAttempts overrides the default repair loop bound.
+func (e *Extractor) SetMaxRepairAttempts(attempts int) {
+	if attempts < 1 {
+		attempts = 1
+	}
+	e.maxRepairs = attempts
+}
+
+// StructuredExtract asks the model to produce JSON matching schemaJSON (a
+// JSON Schema document) from userPrompt, validates the response against it,
+// and on a validation failure feeds the validator's error back to the model
+// asking it to fix only the invalid fields, up to e's repair-attempt bound.
+// It replaces parseExtractedElements' bracket-scan-and-hope approach: a
+// response the model wraps in prose, or that hallucinates a field, is
+// rejected and repaired instead of silently producing zero elements.
+//
+// T is decoded from the final schema-valid JSON via encoding/json, so its
+// struct tags should match schemaJSON's property names.
+func StructuredExtract[T any](ctx context.Context, e *Extractor, sessionID, userID, userPrompt, schemaJSON string) (T, error) {
+	var zero T
+
+	schema, err := jsonschema.CompileString("structured-extract.json", schemaJSON)
+	if err != nil {
+		return zero, fmt.Errorf("llm: invalid extraction schema: %w", err)
+	}
+
+	systemPrompt := buildSchemaSystemPrompt(schemaJSON)
+	responseFormat := &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &JSONSchemaSpec{
+			Name:   "structured_extract",
+			Strict: true,
+			Schema: json.RawMessage(schemaJSON),
+		},
+	}
+
+	var lastErr error
+	prompt := userPrompt
+	for attempt := 1; attempt <= e.maxRepairs; attempt++ {
+		req := ChatRequest{
+			ResponseFormat: responseFormat,
+			Messages: []ChatMessage{
+				{Role: "system", Content: systemPrompt},
+				{Role: "user", Content: prompt},
+			},
+		}
+
+		response, err := e.router.Chat(ctx, req, e.profile, sessionID, userID)
+		if err != nil {
+			return zero, fmt.Errorf("llm: structured extraction call failed: %w", err)
+		}
+		if len(response.Choices) == 0 {
+			return zero, errors.New("llm: structured extraction returned no choices")
+		}
+
+		raw := extractJSONPayload(response.Choices[0].Message.Content)
+
+		var decoded interface{}
+		if jsonErr := json.Unmarshal([]byte(raw), &decoded); jsonErr != nil {
+			lastErr = fmt.Errorf("response is not valid JSON: %w", jsonErr)
+			prompt = repairPrompt(userPrompt, raw, lastErr)
+			continue
+		}
+
+		if validationErr := schema.Validate(decoded); validationErr != nil {
+			lastErr = validationErr
+			prompt = repairPrompt(userPrompt, raw, validationErr)
+			continue
+		}
+
+		var result T
+		if jsonErr := json.Unmarshal([]byte(raw), &result); jsonErr != nil {
+			return zero, fmt.Errorf("llm: schema-valid response failed to decode into %T: %w", zero, jsonErr)
+		}
+		return result, nil
+	}
+
+	return zero, fmt.Errorf("llm: structured extraction did not produce schema-valid JSON after %d attempts, last error: %w", e.maxRepairs, lastErr)
+}
+
+// buildSchemaSystemPrompt instructs the model to answer with bare JSON
+// matching schemaJSON, no surrounding commentary.
+func buildSchemaSystemPrompt(schemaJSON string) string {
+	return fmt.Sprintf(`You are a structured data extraction assistant. Respond with ONLY a single JSON value matching this JSON Schema - no surrounding prose, no markdown code fences:
+
+%s`, schemaJSON)
+}
+
+// repairPrompt asks the model to fix invalidResponse's schema violations
+// without changing anything else, keeping originalPrompt's instructions in
+// view so the repair doesn't drift from the original extraction task.
+func repairPrompt(originalPrompt, invalidResponse string, validationErr error) string {
+	return fmt.Sprintf(`%s
+
+Your previous response did not satisfy the required schema:
+%s
+
+Validation error:
+%s
+
+Respond again with ONLY the corrected JSON. Fix just the invalid fields; keep everything else the same.`, originalPrompt, invalidResponse, validationErr)
+}
+
+// extractJSONPayload returns the substring of text between its first "{" or
+// "[" and the last closing bracket of the same kind, so a model that wraps the JSON
+// payload in prose (or a markdown code fence) still gets validated on just
+// the JSON part. Returns text unchanged if no bracket is found.
+func extractJSONPayload(text string) string {
+	start := strings.IndexAny(text, "{[")
+	if start == -1 {
+		return text
+	}
+
+	closing := byte('}')
+	if text[start] == '[' {
+		closing = ']'
+	}
+
+	end := strings.LastIndexByte(text, closing)
+	if end == -1 || end < start {
+		return text
+	}
+
+	return text[start : end+1]
+}
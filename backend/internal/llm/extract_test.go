@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const testExtractSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "name": {"type": "string"},
+      "score": {"type": "number", "minimum": 0, "maximum": 1}
+    },
+    "required": ["name", "score"],
+    "additionalProperties": false
+  }
+}`
+
+type testExtracted struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+func newTestExtractor(t *testing.T, responses []string) *Extractor {
+	t.Helper()
+	i := 0
+	provider := &fakeProvider{name: "test", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		if i >= len(responses) {
+			t.Fatalf("provider called more times (%d) than responses configured (%d)", i+1, len(responses))
+		}
+		content := responses[i]
+		i++
+		return &ChatResponse{Choices: []ChatChoice{{Message: ChatMessage{Content: content}}}}, nil
+	}}
+
+	router := NewRouter(nil)
+	router.AddProvider(provider, "model-a")
+	return NewExtractor(router, TaskProfile{})
+}
+
+func TestStructuredExtract_ValidFirstResponse(t *testing.T) {
+	extractor := newTestExtractor(t, []string{`[{"name": "a", "score": 0.5}]`})
+
+	result, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "session-1", "user-1", "extract it", testExtractSchema)
+	if err != nil {
+		t.Fatalf("StructuredExtract() error = %v, want nil", err)
+	}
+	if len(result) != 1 || result[0].Name != "a" || result[0].Score != 0.5 {
+		t.Errorf("result = %+v, want [{a 0.5}]", result)
+	}
+}
+
+func TestStructuredExtract_StripsProseAroundJSON(t *testing.T) {
+	extractor := newTestExtractor(t, []string{"Sure, here you go:\n```json\n[{\"name\": \"a\", \"score\": 0.2}]\n```"})
+
+	result, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "", "", "extract it", testExtractSchema)
+	if err != nil {
+		t.Fatalf("StructuredExtract() error = %v, want nil", err)
+	}
+	if len(result) != 1 || result[0].Name != "a" {
+		t.Errorf("result = %+v, want [{a 0.2}]", result)
+	}
+}
+
+func TestStructuredExtract_RepairsAfterInvalidResponse(t *testing.T) {
+	extractor := newTestExtractor(t, []string{
+		`[{"name": "a", "score": 5}]`, // score out of range
+		`[{"name": "a", "score": 0.5}]`,
+	})
+
+	result, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "", "", "extract it", testExtractSchema)
+	if err != nil {
+		t.Fatalf("StructuredExtract() error = %v, want nil", err)
+	}
+	if len(result) != 1 || result[0].Score != 0.5 {
+		t.Errorf("result = %+v, want [{a 0.5}]", result)
+	}
+}
+
+func TestStructuredExtract_GivesUpAfterMaxRepairAttempts(t *testing.T) {
+	extractor := newTestExtractor(t, []string{
+		`[{"name": "a", "score": 5}]`,
+		`[{"name": "a", "score": 6}]`,
+		`[{"name": "a", "score": 7}]`,
+	})
+	extractor.SetMaxRepairAttempts(3)
+
+	_, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "", "", "extract it", testExtractSchema)
+	if err == nil {
+		t.Fatal("expected StructuredExtract() to return an error after exhausting repair attempts")
+	}
+}
+
+func TestStructuredExtract_InvalidSchemaErrorsImmediately(t *testing.T) {
+	extractor := newTestExtractor(t, nil)
+
+	_, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "", "", "extract it", "{not valid json schema")
+	if err == nil {
+		t.Fatal("expected StructuredExtract() to reject an invalid schema before calling the provider")
+	}
+}
+
+func TestStructuredExtract_PropagatesRouterError(t *testing.T) {
+	provider := &fakeProvider{name: "test", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return nil, errors.New("invalid request: bad auth")
+	}}
+	router := NewRouter(nil)
+	router.SetRetryConfig(noBackoffRetryConfig())
+	router.AddProvider(provider, "model-a")
+	extractor := NewExtractor(router, TaskProfile{})
+
+	_, err := StructuredExtract[[]testExtracted](context.Background(), extractor, "", "", "extract it", testExtractSchema)
+	if err == nil {
+		t.Fatal("expected StructuredExtract() to propagate a Router.Chat error")
+	}
+}
@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// predictMethod and predictStreamMethod name the RPCs a self-hosted Predict
+// service exposes: a unary call and a server-streaming variant, matching the
+// shape of a standard Predict(stream) RPC so llama.cpp-style servers can
+// implement the same two methods to plug in.
+const (
+	predictMethod       = "/innerworld.llm.Predictor/Predict"
+	predictStreamMethod = "/innerworld.llm.Predictor/PredictStream"
+)
+
+// grpcPredictRequest is the request message for both RPCs.
+type grpcPredictRequest struct {
+	SystemPrompt string `json:"system_prompt"`
+	UserMessage  string `json:"user_message"`
+}
+
+// grpcPredictResponse is the response message; PredictStream sends a
+// sequence of these with Done set only on the last one.
+type grpcPredictResponse struct {
+	Text             string `json:"text"`
+	Done             bool   `json:"done"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+}
+
+// GRPCBackend talks to a locally (VPC-internal) hosted Predict service over
+// gRPC, for HIPAA-constrained deployments where traffic to a model can't
+// leave the VPC. See grpc_codec.go for why this speaks JSON over gRPC
+// framing instead of a protoc-compiled .proto.
+type GRPCBackend struct {
+	conn *grpc.ClientConn
+	name string
+}
+
+// NewGRPCBackend dials target (e.g. "llama-server.internal:50051"). The
+// connection is insecure by default since it's expected to stay inside a
+// private VPC; wrap target behind a service mesh or mTLS sidecar for
+// deployments that need transport security.
+func NewGRPCBackend(target string) (*GRPCBackend, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial gRPC backend at %s: %w", target, err)
+	}
+
+	return &GRPCBackend{conn: conn, name: "grpc-local"}, nil
+}
+
+// Name identifies this backend as "grpc-local".
+func (b *GRPCBackend) Name() string {
+	return b.name
+}
+
+// Generate calls the unary Predict RPC.
+func (b *GRPCBackend) Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	req := &grpcPredictRequest{SystemPrompt: systemPrompt, UserMessage: userMessage}
+	resp := new(grpcPredictResponse)
+
+	if err := b.conn.Invoke(ctx, predictMethod, req, resp, grpc.CallContentSubtype(grpcJSONSubtype)); err != nil {
+		return nil, fmt.Errorf("gRPC Predict call failed: %w", err)
+	}
+
+	return &ChatResponse{
+		Model: b.name,
+		Choices: []ChatChoice{
+			{Index: 0, Message: ChatMessage{Role: "assistant", Content: resp.Text}, FinishReason: "stop"},
+		},
+		Usage: Usage{
+			PromptTokens:     resp.PromptTokens,
+			CompletionTokens: resp.CompletionTokens,
+			TotalTokens:      resp.PromptTokens + resp.CompletionTokens,
+		},
+	}, nil
+}
+
+// GenerateStream calls the server-streaming PredictStream RPC and forwards
+// each chunk's text as it arrives - real token-level streaming, unlike the
+// other backends' faked word-splitting.
+func (b *GRPCBackend) GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error) {
+	stream, err := b.conn.NewStream(ctx, &grpc.StreamDesc{StreamName: "PredictStream", ServerStreams: true},
+		predictStreamMethod, grpc.CallContentSubtype(grpcJSONSubtype))
+	if err != nil {
+		return nil, fmt.Errorf("gRPC PredictStream call failed: %w", err)
+	}
+
+	req := &grpcPredictRequest{SystemPrompt: systemPrompt, UserMessage: userMessage}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, fmt.Errorf("failed to send gRPC predict request: %w", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, fmt.Errorf("failed to close gRPC predict send side: %w", err)
+	}
+
+	deltas := make(chan string)
+	go func() {
+		defer close(deltas)
+		for {
+			chunk := new(grpcPredictResponse)
+			if err := stream.RecvMsg(chunk); err != nil {
+				if err != io.EOF {
+					// The channel has no way to carry an error; callers
+					// that need to distinguish a clean close from a
+					// mid-stream failure should watch ctx for cancellation.
+				}
+				return
+			}
+
+			select {
+			case deltas <- chunk.Text:
+			case <-ctx.Done():
+				return
+			}
+
+			if chunk.Done {
+				return
+			}
+		}
+	}()
+
+	return deltas, nil
+}
+
+// HealthCheck sends a trivial Predict call to confirm the backend is
+// reachable; a local Predict service has no separate health RPC defined yet.
+func (b *GRPCBackend) HealthCheck(ctx context.Context) error {
+	if _, err := b.Generate(ctx, "", "ping"); err != nil {
+		return fmt.Errorf("grpc-local health check failed: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying connection.
+func (b *GRPCBackend) Close() error {
+	return b.conn.Close()
+}
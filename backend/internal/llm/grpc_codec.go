@@ -0,0 +1,34 @@
+package llm
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// grpcJSONSubtype is the gRPC content-subtype GRPCBackend negotiates instead
+// of protobuf. There's no protoc toolchain in this build, so the local
+// Predict service and this client exchange JSON framed by gRPC/HTTP2 rather
+// than a compiled .proto contract; a self-hosted server just needs to
+// register the same codec name to be a drop-in backend.
+const grpcJSONSubtype = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec implements google.golang.org/grpc/encoding.Codec by marshaling
+// messages as JSON instead of protobuf.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return grpcJSONSubtype
+}
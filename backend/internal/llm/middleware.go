@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+
+	"innerworld-backend/internal/logging"
+)
+
+// ChatFunc is the shape Middleware wraps: a single Chat attempt against one
+// resolved provider, after Router has already picked which candidate and
+// model to try.
+type ChatFunc func(ctx context.Context, provider Provider, req ChatRequest) (*ChatResponse, error)
+
+// Middleware wraps a ChatFunc, e.g. to log the call, attach tracing spans,
+// or redact PII from req before it reaches provider. A middleware calls
+// next itself, so it can run code both before and after the call, or
+// short-circuit it entirely (e.g. rejecting a request that fails a
+// moderation check). Router.Use registers middleware in the order every
+// call should pass through them.
+type Middleware func(next ChatFunc) ChatFunc
+
+// LoggingMiddleware logs each attempt's provider, model, and outcome
+// through internal/logging, so a correlation-ID-scoped logger seeded via
+// logging.ContextWithLogger automatically ties a Router.Chat call back to
+// the request that triggered it.
+func LoggingMiddleware() Middleware {
+	return func(next ChatFunc) ChatFunc {
+		return func(ctx context.Context, provider Provider, req ChatRequest) (*ChatResponse, error) {
+			logger := logging.FromContext(ctx)
+			logger.DebugContext(ctx, "llm router: attempting chat completion",
+				"provider", provider.Name(), "model", req.Model)
+
+			response, err := next(ctx, provider, req)
+			if err != nil {
+				logger.ErrorContext(ctx, "llm router: chat completion failed",
+					"provider", provider.Name(), "model", req.Model, "error", err)
+				return nil, err
+			}
+
+			logger.InfoContext(ctx, "llm router: chat completion succeeded",
+				"provider", provider.Name(), "model", req.Model,
+				"prompt_tokens", response.Usage.PromptTokens,
+				"completion_tokens", response.Usage.CompletionTokens)
+			return response, nil
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"innerworld-backend/internal/logging"
+	"innerworld-backend/internal/resilience"
+)
+
+// userIDCtxKey is the context key Router.Chat seeds with the call's userID,
+// mirroring internal/logging's ContextWithLogger/FromContext pattern so
+// middleware (e.g. SafetyFilter.Middleware) can read it without ChatFunc's
+// signature having to carry it explicitly.
+type userIDCtxKey struct{}
+
+// ContextWithUserID returns a copy of ctx carrying userID, readable back via
+// UserIDFromContext.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDCtxKey{}, userID)
+}
+
+// UserIDFromContext returns the userID ctx was seeded with, or "" if none
+// was set.
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDCtxKey{}).(string)
+	return userID
+}
+
+// CallUsage attributes one candidate's share of a Router.Chat call's token
+// usage to the session/user that triggered it, summed over every attempt
+// Router made against that candidate that returned a response (Attempts
+// counts all attempts, including failed ones that returned no response).
+type CallUsage struct {
+	SessionID string
+	UserID    string
+	Provider  string
+	Model     string
+	Usage     Usage
+	Attempts  int
+
+	// Err is set when this candidate failed; Usage is then usually zero,
+	// since a failed attempt rarely returns a billable response.
+	Err error
+}
+
+// UsageRecorder receives one CallUsage for every candidate a Router.Chat
+// call tries, failed ones included. Router calls it synchronously, so a slow recorder adds to Chat's latency - keep
+// it non-blocking (e.g. buffer + background flush) for anything beyond an
+// in-memory counter.
+type UsageRecorder func(CallUsage)
+
+// routerCandidate pairs a registered Provider with the model Router asks it
+// to serve when a call's ChatRequest/TaskProfile doesn't pin a more specific
+// one.
+type routerCandidate struct {
+	provider Provider
+	model    string
+}
+
+// Router fronts multiple Providers (OpenRouter, direct Anthropic, direct
+// OpenAI, local Ollama) behind a policy-driven Chat call: given a
+// TaskProfile, it orders registered candidates by preference, drops those
+// that fail the cost/JSON filters, then
+// tries them in order with per-provider circuit breakers and retry/backoff,
+// falling over to the next candidate on a transport-level or rate-limit
+// error the same way BackendRouter does for the simpler Backend interface.
+// Router is what extractConversationElements in cmd/session-processor
+// should call instead of a bare *OpenRouterClient, so a single provider's
+// outage degrades to a fallback provider instead of straight to mock data.
+type Router struct {
+	candidates    []routerCandidate
+	breakers      *resilience.Registry
+	retryConfig   resilience.RetryConfig
+	isRetryable   resilience.IsRetryableError
+	middleware    []Middleware
+	usageRecorder UsageRecorder
+}
+
+// NewRouter creates a Router with no registered providers. breakers may be
+// nil to run every candidate without circuit breaker protection (e.g. in
+// tests); retry still applies via resilience.DefaultRetryConfig.
+func NewRouter(breakers *resilience.Registry) *Router {
+	return &Router{
+		breakers:    breakers,
+		retryConfig: resilience.DefaultRetryConfig(),
+		isRetryable: resilience.OpenRouterRetryableErrors,
+	}
+}
+
+// HasProviders reports whether any provider has been registered yet, so
+// callers building a Router from optional config (e.g. whichever API keys
+// happen to be set) can tell whether to also keep a non-Router fallback.
+func (r *Router) HasProviders() bool {
+	return len(r.candidates) > 0
+}
+
+// AddProvider registers provider as a fallback candidate serving
+// defaultModel when a call doesn't pin a more specific one. Candidates are
+// tried in registration order except where a TaskProfile's PreferredModels
+// reorders them.
+func (r *Router) AddProvider(provider Provider, defaultModel string) {
+	r.candidates = append(r.candidates, routerCandidate{provider: provider, model: defaultModel})
+}
+
+// Use appends mw to the middleware chain every Chat attempt passes through,
+// outermost-registered-first (the first Use call wraps everything the rest
+// of the chain does).
+func (r *Router) Use(mw Middleware) {
+	r.middleware = append(r.middleware, mw)
+}
+
+// SetRetryConfig overrides the per-candidate retry/backoff policy (default:
+// resilience.DefaultRetryConfig).
+func (r *Router) SetRetryConfig(config resilience.RetryConfig) {
+	r.retryConfig = config
+}
+
+// SetUsageRecorder registers recorder to receive a CallUsage after every
+// Chat call. Passing nil (the default) drops usage accounting entirely.
+func (r *Router) SetUsageRecorder(recorder UsageRecorder) {
+	r.usageRecorder = recorder
+}
+
+// Breaker exposes the circuit breaker backing providerName, so callers can
+// inspect BreakerStats (e.g. to surface it on the admin/debug HTTP surface)
+// without Router having to re-expose every CircuitBreaker method itself.
+// Returns nil if Router has no breaker registry configured.
+func (r *Router) Breaker(providerName string) *resilience.CircuitBreaker {
+	if r.breakers == nil {
+		return nil
+	}
+	return r.breakers.Get(providerName)
+}
+
+// Chat tries registered candidates in profile's preference order, applying
+// middleware and per-candidate retry/circuit-breaker protection, until one
+// succeeds or all are exhausted. sessionID/userID are attribution-only: they
+// never reach a provider, only the UsageRecorder. req.Model, if already set,
+// pins every candidate to that exact model instead of each candidate's own
+// default.
+func (r *Router) Chat(ctx context.Context, req ChatRequest, profile TaskProfile, sessionID, userID string) (*ChatResponse, error) {
+	candidates := r.orderCandidates(profile)
+	if len(candidates) == 0 {
+		return nil, errors.New("llm: router has no registered providers matching this task profile")
+	}
+
+	ctx = ContextWithUserID(ctx, userID)
+	chat := r.chain()
+
+	var lastErr error
+	for _, candidate := range candidates {
+		attemptReq := req
+		if attemptReq.Model == "" {
+			attemptReq.Model = candidate.model
+		}
+
+		response, attempts, usage, err := r.callCandidate(ctx, candidate, attemptReq, chat)
+		r.recordUsage(candidate, attemptReq, sessionID, userID, usage, attempts, err)
+
+		if err == nil {
+			return response, nil
+		}
+		if !isFailoverEligible(err) {
+			return nil, err
+		}
+
+		logging.FromContext(ctx).ErrorContext(ctx, "llm router: provider exhausted retries, trying next candidate",
+			"provider", candidate.provider.Name(), "error", err)
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("llm: all providers failed, last error: %w", lastErr)
+}
+
+// chain wraps the base single-attempt call (just calling provider.Chat)
+// with every registered middleware, outermost-first.
+func (r *Router) chain() ChatFunc {
+	fn := ChatFunc(func(ctx context.Context, provider Provider, req ChatRequest) (*ChatResponse, error) {
+		return provider.Chat(ctx, req)
+	})
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		fn = r.middleware[i](fn)
+	}
+	return fn
+}
+
+// callCandidate runs chat against candidate with retry/backoff, wrapped in
+// candidate's circuit breaker if Router has one configured, and reports how
+// many attempts it took and the usage summed over every attempt that
+// returned a response.
+func (r *Router) callCandidate(ctx context.Context, candidate routerCandidate, req ChatRequest, chat ChatFunc) (*ChatResponse, int, Usage, error) {
+	attempts := 0
+	var usage Usage
+	retry := func(ctx context.Context, _ int) (*ChatResponse, error) {
+		attempts++
+		response, err := chat(ctx, candidate.provider, req)
+		if response != nil {
+			usage.PromptTokens += response.Usage.PromptTokens
+			usage.CompletionTokens += response.Usage.CompletionTokens
+			usage.TotalTokens += response.Usage.TotalTokens
+		}
+		return response, err
+	}
+
+	if r.breakers == nil {
+		response, err := resilience.RetryWithBackoff(ctx, r.retryConfig, r.isRetryable, retry)
+		return response, attempts, usage, err
+	}
+
+	var response *ChatResponse
+	err := r.breakers.Execute(ctx, candidate.provider.Name(), func() error {
+		result, innerErr := resilience.RetryWithBackoff(ctx, r.retryConfig, r.isRetryable, retry)
+		response = result
+		return innerErr
+	})
+	return response, attempts, usage, err
+}
+
+// recordUsage reports call to r.usageRecorder, if one is registered.
+func (r *Router) recordUsage(candidate routerCandidate, req ChatRequest, sessionID, userID string, usage Usage, attempts int, err error) {
+	if r.usageRecorder == nil {
+		return
+	}
+
+	call := CallUsage{
+		SessionID: sessionID,
+		UserID:    userID,
+		Provider:  candidate.provider.Name(),
+		Model:     req.Model,
+		Usage:     usage,
+		Attempts:  attempts,
+		Err:       err,
+	}
+	r.usageRecorder(call)
+}
+
+// orderCandidates returns candidates in the order Chat should try them:
+// first every candidate serving a model in profile.PreferredModels (in the
+// order listed), then every remaining candidate in registration order, with
+// CostAware filtering applied throughout.
+func (r *Router) orderCandidates(profile TaskProfile) []routerCandidate {
+	ordered := make([]routerCandidate, 0, len(r.candidates))
+	used := make([]bool, len(r.candidates))
+
+	for _, model := range profile.PreferredModels {
+		for i, candidate := range r.candidates {
+			if used[i] || candidate.model != model {
+				continue
+			}
+			if !r.passesFilters(candidate, model, profile) {
+				continue
+			}
+			ordered = append(ordered, candidate)
+			used[i] = true
+		}
+	}
+
+	for i, candidate := range r.candidates {
+		if used[i] || !r.passesFilters(candidate, candidate.model, profile) {
+			continue
+		}
+		ordered = append(ordered, candidate)
+		used[i] = true
+	}
+
+	return ordered
+}
+
+// passesFilters reports whether candidate may serve model under profile's
+// MaxCostUSD/RequiresJSON constraints. Candidates that don't implement
+// CostAware always pass, since Router has no way to estimate their cost.
+func (r *Router) passesFilters(candidate routerCandidate, model string, profile TaskProfile) bool {
+	costAware, ok := candidate.provider.(CostAware)
+	if !ok {
+		return true
+	}
+
+	cost := costAware.EstimateCost(model)
+	if profile.MaxCostUSD > 0 && cost.CostPerCallUSD > profile.MaxCostUSD {
+		return false
+	}
+	if profile.RequiresJSON && !cost.SupportsJSON {
+		return false
+	}
+	return true
+}
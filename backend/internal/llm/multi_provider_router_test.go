@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"innerworld-backend/internal/resilience"
+)
+
+// fakeProvider is a minimal Provider test double whose Chat behavior is
+// supplied per-test via chatFunc.
+type fakeProvider struct {
+	name     string
+	chatFunc func(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	calls    int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	return p.chatFunc(ctx, req)
+}
+
+func TestRouter_FallsOverToNextProviderOnTransientError(t *testing.T) {
+	failing := &fakeProvider{name: "primary", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return nil, errors.New("connection refused")
+	}}
+	succeeding := &fakeProvider{name: "fallback", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return &ChatResponse{Model: req.Model}, nil
+	}}
+
+	router := NewRouter(nil)
+	router.SetRetryConfig(noBackoffRetryConfig())
+	router.AddProvider(failing, "model-a")
+	router.AddProvider(succeeding, "model-b")
+
+	response, err := router.Chat(context.Background(), ChatRequest{}, TaskProfile{}, "session-1", "user-1")
+	if err != nil {
+		t.Fatalf("Chat() error = %v, want nil", err)
+	}
+	if response.Model != "model-b" {
+		t.Errorf("response.Model = %q, want %q", response.Model, "model-b")
+	}
+	if failing.calls == 0 {
+		t.Error("expected the failing provider to have been tried at least once")
+	}
+}
+
+func TestRouter_NonFailoverEligibleErrorStopsImmediately(t *testing.T) {
+	rejecting := &fakeProvider{name: "primary", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return nil, errors.New("invalid request: missing field")
+	}}
+	neverCalled := &fakeProvider{name: "fallback", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return &ChatResponse{}, nil
+	}}
+
+	router := NewRouter(nil)
+	router.SetRetryConfig(noBackoffRetryConfig())
+	router.AddProvider(rejecting, "model-a")
+	router.AddProvider(neverCalled, "model-b")
+
+	_, err := router.Chat(context.Background(), ChatRequest{}, TaskProfile{}, "session-1", "user-1")
+	if err == nil {
+		t.Fatal("expected Chat() to return an error")
+	}
+	if neverCalled.calls != 0 {
+		t.Error("expected fallback provider not to be tried for a non-failover-eligible error")
+	}
+}
+
+func TestRouter_PreferredModelsReorderCandidates(t *testing.T) {
+	var order []string
+	record := func(name string) func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		return func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+			order = append(order, name)
+			return nil, errors.New("connection refused")
+		}
+	}
+
+	a := &fakeProvider{name: "a", chatFunc: record("a")}
+	b := &fakeProvider{name: "b", chatFunc: record("b")}
+
+	router := NewRouter(nil)
+	router.SetRetryConfig(resilience.RetryConfig{MaxAttempts: 1, JitterMode: resilience.JitterNone})
+	router.AddProvider(a, "model-a")
+	router.AddProvider(b, "model-b")
+
+	_, _ = router.Chat(context.Background(), ChatRequest{}, TaskProfile{PreferredModels: []string{"model-b"}}, "", "")
+
+	if len(order) != 2 || order[0] != "b" || order[1] != "a" {
+		t.Errorf("call order = %v, want [b a]", order)
+	}
+}
+
+func TestRouter_RecordsUsageAcrossAttempts(t *testing.T) {
+	attempts := 0
+	flaky := &fakeProvider{name: "flaky", chatFunc: func(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+		attempts++
+		if attempts < 2 {
+			return nil, errors.New("timeout")
+		}
+		return &ChatResponse{Model: req.Model, Usage: Usage{TotalTokens: 42}}, nil
+	}}
+
+	router := NewRouter(nil)
+	router.SetRetryConfig(noBackoffRetryConfig())
+	router.AddProvider(flaky, "model-a")
+
+	var recorded CallUsage
+	router.SetUsageRecorder(func(call CallUsage) { recorded = call })
+
+	_, err := router.Chat(context.Background(), ChatRequest{}, TaskProfile{}, "session-1", "user-1")
+	if err != nil {
+		t.Fatalf("Chat() error = %v, want nil", err)
+	}
+
+	if recorded.SessionID != "session-1" || recorded.UserID != "user-1" {
+		t.Errorf("recorded attribution = %+v, want session-1/user-1", recorded)
+	}
+	if recorded.Attempts != 2 {
+		t.Errorf("recorded.Attempts = %d, want 2", recorded.Attempts)
+	}
+	if recorded.Usage.TotalTokens != 42 {
+		t.Errorf("recorded.Usage.TotalTokens = %d, want 42", recorded.Usage.TotalTokens)
+	}
+}
+
+// noBackoffRetryConfig keeps these tests fast: one retry, no sleep.
+func noBackoffRetryConfig() resilience.RetryConfig {
+	return resilience.RetryConfig{
+		MaxAttempts:  2,
+		InitialDelay: 0,
+		MaxDelay:     0,
+		JitterMode:   resilience.JitterNone,
+	}
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultOllamaBaseURL points at a local Ollama daemon's default port.
+const defaultOllamaBaseURL = "http://localhost:11434"
+
+// defaultOllamaModel is a small, fast model suitable for dev boxes and CI
+// runners without a GPU.
+const defaultOllamaModel = "llama3.2"
+
+// OllamaBackend calls a local Ollama daemon's chat API, used in place of a
+// paid provider for local development and integration tests (Router.Chat
+// can fall back to it when OPENROUTER_API_KEY/ANTHROPIC_API_KEY aren't set,
+// rather than the test suite silently exercising mock element extraction).
+type OllamaBackend struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOllamaBackend creates an Ollama backend against baseURL using the
+// default model. An empty baseURL uses defaultOllamaBaseURL.
+func NewOllamaBackend(baseURL string) *OllamaBackend {
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	return &OllamaBackend{
+		baseURL: baseURL,
+		model:   defaultOllamaModel,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// SetModel overrides the model used for Generate/GenerateStream.
+func (b *OllamaBackend) SetModel(model string) {
+	b.model = model
+}
+
+// Name identifies this backend as "ollama".
+func (b *OllamaBackend) Name() string {
+	return "ollama"
+}
+
+type ollamaChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type ollamaChatResponse struct {
+	Model   string      `json:"model"`
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+
+	// Ollama reports usage in these top-level counts rather than a nested
+	// "usage" object.
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+// Generate calls Ollama's /api/chat with stream: false and translates the
+// response into the shared ChatResponse shape.
+func (b *OllamaBackend) Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	payload, err := json.Marshal(ollamaChatRequest{
+		Model: b.model,
+		Messages: []ChatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userMessage},
+		},
+		Stream: false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.baseURL+"/api/chat", bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+
+	return &ChatResponse{
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   result.Model,
+		Choices: []ChatChoice{
+			{Index: 0, Message: result.Message, FinishReason: "stop"},
+		},
+		Usage: Usage{
+			PromptTokens:     result.PromptEvalCount,
+			CompletionTokens: result.EvalCount,
+			TotalTokens:      result.PromptEvalCount + result.EvalCount,
+		},
+	}, nil
+}
+
+// GenerateStream fakes streaming: it makes one non-streaming Generate call
+// and replays the content word by word, without using Ollama's own
+// streaming mode.
+func (b *OllamaBackend) GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error) {
+	response, err := b.Generate(ctx, systemPrompt, userMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	content := ""
+	if len(response.Choices) > 0 {
+		content = response.Choices[0].Message.Content
+	}
+
+	deltas := make(chan string)
+	go func() {
+		defer close(deltas)
+		for _, word := range strings.Fields(content) {
+			select {
+			case deltas <- word + " ":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return deltas, nil
+}
+
+// HealthCheck lists locally pulled models as a lightweight call confirming
+// the daemon is reachable; Ollama has no dedicated health endpoint.
+func (b *OllamaBackend) HealthCheck(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", b.baseURL+"/api/tags", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("ollama health check returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
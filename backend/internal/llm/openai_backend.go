@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// defaultOpenAIBackendModel mirrors the OpenRouter backend's default model
+// family at a comparable quality/cost tier.
+const defaultOpenAIBackendModel = "gpt-4o-mini"
+
+// OpenAIBackend talks to OpenAI's own chat completions API directly,
+// bypassing OpenRouter. Useful as a fallback when OpenRouter itself is the
+// thing that's down, or for personas pinned to a specific OpenAI model.
+type OpenAIBackend struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIBackend creates a direct OpenAI backend using the default model.
+func NewOpenAIBackend(apiKey string) *OpenAIBackend {
+	return &OpenAIBackend{
+		client: openai.NewClient(apiKey),
+		model:  defaultOpenAIBackendModel,
+	}
+}
+
+// SetModel overrides the chat model used for Generate/GenerateStream.
+func (b *OpenAIBackend) SetModel(model string) {
+	b.model = model
+}
+
+// SetAPIKey swaps the key used to authorize subsequent requests, so a
+// long-lived backend can pick up a rotated OPENAI_API_KEY without being
+// reconstructed.
+func (b *OpenAIBackend) SetAPIKey(apiKey string) {
+	b.client = openai.NewClient(apiKey)
+}
+
+// Name identifies this backend as "openai".
+func (b *OpenAIBackend) Name() string {
+	return "openai"
+}
+
+// Generate creates a chat completion via OpenAI and translates it into the
+// shared ChatResponse shape so callers don't care which backend answered.
+func (b *OpenAIBackend) Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	resp, err := b.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       b.model,
+		Temperature: 0.7,
+		MaxTokens:   150,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: userMessage},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI chat completion failed: %w", err)
+	}
+
+	choices := make([]ChatChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choices[i] = ChatChoice{
+			Index:        choice.Index,
+			Message:      ChatMessage{Role: choice.Message.Role, Content: choice.Message.Content},
+			FinishReason: string(choice.FinishReason),
+		}
+	}
+
+	return &ChatResponse{
+		ID:      resp.ID,
+		Object:  resp.Object,
+		Created: resp.Created,
+		Model:   resp.Model,
+		Choices: choices,
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// GenerateStream fakes streaming: it makes one non-streaming Generate call
+// and replays the content word by word. The go-openai client supports real
+// token streaming, but this backend does not use it.
+func (b *OpenAIBackend) GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error) {
+	response, err := b.Generate(ctx, systemPrompt, userMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	content := ""
+	if len(response.Choices) > 0 {
+		content = response.Choices[0].Message.Content
+	}
+
+	deltas := make(chan string)
+	go func() {
+		defer close(deltas)
+		for _, word := range strings.Fields(content) {
+			select {
+			case deltas <- word + " ":
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return deltas, nil
+}
+
+// HealthCheck lists models as a lightweight call that confirms the API key
+// and network path are good without spending completion tokens.
+func (b *OpenAIBackend) HealthCheck(ctx context.Context) error {
+	if _, err := b.client.ListModels(ctx); err != nil {
+		return fmt.Errorf("openai health check failed: %w", err)
+	}
+	return nil
+}
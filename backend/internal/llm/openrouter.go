@@ -1,20 +1,30 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"innerworld-backend/internal/metrics"
+	"innerworld-backend/internal/resilience"
 	"io"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// openRouterBreakerEndpoint is this client's key in a resilience.Registry.
+const openRouterBreakerEndpoint = "openrouter"
+
 // OpenRouterClient handles basic interactions with OpenRouter API
 type OpenRouterClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
+	breakers   *resilience.Registry
+	bulkheads  *resilience.BulkheadRegistry
 }
 
 // NewOpenRouterClient creates a new OpenRouter API client
@@ -28,12 +38,87 @@ func NewOpenRouterClient(apiKey string) *OpenRouterClient {
 	}
 }
 
+// SetBreakerRegistry wraps subsequent API calls through registry's
+// "openrouter" circuit breaker, so repeated OpenRouter failures start
+// failing fast instead of retrying a dependency that's already down.
+func (c *OpenRouterClient) SetBreakerRegistry(registry *resilience.Registry) {
+	c.breakers = registry
+}
+
+// SetBulkheadRegistry caps concurrent in-flight API calls through registry's
+// "openrouter" bulkhead, so a burst of slow completions can't alone consume
+// every goroutine the Lambda container has available for other work. When
+// both a breaker and bulkhead registry are set, makeRequest composes them
+// with a retry via resilience.Wrap instead of just the breaker.
+func (c *OpenRouterClient) SetBulkheadRegistry(registry *resilience.BulkheadRegistry) {
+	c.bulkheads = registry
+}
+
+// SetAPIKey swaps the key used to authorize subsequent requests, so a
+// long-lived client can pick up a rotated OPENROUTER_API_KEY without being
+// reconstructed.
+func (c *OpenRouterClient) SetAPIKey(apiKey string) {
+	c.apiKey = apiKey
+}
+
+// Name identifies this client as "openrouter", satisfying Provider for
+// Router registration.
+func (c *OpenRouterClient) Name() string {
+	return "openrouter"
+}
+
+// Chat implements Provider directly against OpenRouter's native ChatRequest
+// shape, preserving req.Model/Temperature/MaxTokens/Stream instead of
+// flattening to GenerateResponseWithSystem's fixed defaults the way
+// AsProvider(NewOpenRouterBackend(c)) would.
+func (c *OpenRouterClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	response, err := c.makeRequest(ctx, "/chat/completions", req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenRouter API request failed: %w", err)
+	}
+	return response, nil
+}
+
 // ChatRequest represents OpenRouter chat completion request
 type ChatRequest struct {
 	Model       string        `json:"model"`
 	Messages    []ChatMessage `json:"messages"`
 	Temperature float64       `json:"temperature,omitempty"`
 	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+
+	// ResponseFormat asks OpenRouter to constrain the completion to a
+	// specific shape (OpenAI-style structured outputs). Only OpenRouterClient
+	// forwards it to the wire; Provider implementations adapted from Backend
+	// (AsProvider) flatten ChatRequest down to two strings and drop it, so
+	// StructuredExtract's repair loop is what makes extraction reliable on
+	// those providers instead.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
+	// StreamOptions only applies when Stream is true; setting IncludeUsage
+	// asks OpenRouter to append a final usage-only chunk, which is what lets
+	// GenerateResponseStream/StreamChannel assemble Usage for a streamed
+	// call the same way a non-streamed Chat call gets it for free.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions mirrors OpenRouter/OpenAI's stream_options field.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ResponseFormat mirrors OpenRouter/OpenAI's response_format field.
+type ResponseFormat struct {
+	Type       string          `json:"type"` // "json_schema" or "json_object"
+	JSONSchema *JSONSchemaSpec `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaSpec names and carries the schema for a "json_schema" response
+// format.
+type JSONSchemaSpec struct {
+	Name   string          `json:"name"`
+	Strict bool            `json:"strict"`
+	Schema json.RawMessage `json:"schema"`
 }
 
 // ChatMessage represents a chat message
@@ -66,11 +151,62 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
-// GenerateResponse creates a basic LLM response
+// chatCompletionChunk is a single OpenRouter SSE "data:" event for a
+// stream: true request, carrying an incremental content delta rather than a
+// full message. Usage is only populated on the final chunk, and only when
+// the request set StreamOptions.IncludeUsage.
+type chatCompletionChunk struct {
+	ID      string            `json:"id"`
+	Model   string            `json:"model"`
+	Choices []chatChunkChoice `json:"choices"`
+	Usage   *Usage            `json:"usage,omitempty"`
+}
+
+// chatChunkChoice carries the delta for one stream event.
+type chatChunkChoice struct {
+	Delta        chatChunkDelta `json:"delta"`
+	FinishReason string         `json:"finish_reason"`
+}
+
+// chatChunkDelta is a stream chunk's incremental content: either plain text
+// or, when the model is calling a tool, one or more tool-call argument
+// fragments.
+type chatChunkDelta struct {
+	Role      string              `json:"role,omitempty"`
+	Content   string              `json:"content,omitempty"`
+	ToolCalls []chatToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// chatToolCallDelta is one incremental fragment of a tool call OpenRouter is
+// streaming back piecemeal. Index identifies which tool call a fragment
+// belongs to when a response makes more than one, since fragments for
+// different calls can interleave across chunks.
+type chatToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function,omitempty"`
+}
+
+// streamDoneMarker is the terminal "data: [DONE]" line OpenRouter sends
+// after the last content chunk.
+const streamDoneMarker = "[DONE]"
+
+// defaultSystemMessage is used by GenerateResponse for callers that don't
+// have a persona-specific system prompt to send.
+const defaultSystemMessage = "You are a helpful AI assistant for a teen wellness app called InnerWorld. Be supportive and encouraging."
+
+// GenerateResponse creates a basic LLM response using the default system message
 func (c *OpenRouterClient) GenerateResponse(ctx context.Context, userMessage string) (*ChatResponse, error) {
-	// Simple system message for basic conversation
-	systemMessage := "You are a helpful AI assistant for a teen wellness app called InnerWorld. Be supportive and encouraging."
+	return c.GenerateResponseWithSystem(ctx, defaultSystemMessage, userMessage)
+}
 
+// GenerateResponseWithSystem creates an LLM response using systemMessage in
+// place of the default, so callers with a formatted persona prompt (and any
+// GraphRAG context injected into it) can have it actually reach the model.
+func (c *OpenRouterClient) GenerateResponseWithSystem(ctx context.Context, systemMessage, userMessage string) (*ChatResponse, error) {
 	request := ChatRequest{
 		Model:       "anthropic/claude-3.5-sonnet",
 		Temperature: 0.7,
@@ -81,7 +217,9 @@ func (c *OpenRouterClient) GenerateResponse(ctx context.Context, userMessage str
 		},
 	}
 
+	start := time.Now()
 	response, err := c.makeRequest(ctx, "/chat/completions", request)
+	metrics.LLMRequestDuration.ObserveDuration(start, request.Model)
 	if err != nil {
 		return nil, fmt.Errorf("OpenRouter API request failed: %w", err)
 	}
@@ -89,8 +227,34 @@ func (c *OpenRouterClient) GenerateResponse(ctx context.Context, userMessage str
 	return response, nil
 }
 
-// makeRequest handles HTTP requests to OpenRouter API
-func (c *OpenRouterClient) makeRequest(ctx context.Context, endpoint string, payload interface{}) (*ChatResponse, error) {
+// GenerateStreamWithSystem is GenerateResponseWithSystem's streaming
+// counterpart: it issues a stream: true request and returns a channel of
+// content deltas as OpenRouter's SSE events arrive, closing the channel when
+// the stream ends (a "[DONE]" event, EOF, or ctx cancellation). The initial
+// request (headers, status code) is validated synchronously before the
+// channel is returned, so callers still get an error for auth/4xx failures
+// instead of an empty stream.
+func (c *OpenRouterClient) GenerateStreamWithSystem(ctx context.Context, systemMessage, userMessage string) (<-chan string, error) {
+	request := ChatRequest{
+		Model:       "anthropic/claude-3.5-sonnet",
+		Temperature: 0.7,
+		MaxTokens:   150,
+		Stream:      true,
+		Messages: []ChatMessage{
+			{Role: "system", Content: systemMessage},
+			{Role: "user", Content: userMessage},
+		},
+	}
+
+	return c.makeStreamRequest(ctx, "/chat/completions", request)
+}
+
+// openStream issues a stream: true POST to endpoint and returns the raw
+// response for the caller to scan as SSE, once the initial status code has
+// been validated - so an auth/4xx failure still surfaces as a returned
+// error instead of opening an empty stream. Shared by makeStreamRequest and
+// GenerateResponseStream/StreamChannel.
+func (c *OpenRouterClient) openStream(ctx context.Context, endpoint string, payload interface{}) (*http.Response, error) {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -105,22 +269,155 @@ func (c *OpenRouterClient) makeRequest(ctx context.Context, endpoint string, pay
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("HTTP-Referer", "https://innerworld.app")
 	req.Header.Set("X-Title", "InnerWorld")
+	req.Header.Set("Accept", "text/event-stream")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		cause := fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, resilience.ClassifyHTTPError(resp.StatusCode, resp.Header, cause)
+	}
+
+	return resp, nil
+}
+
+// makeStreamRequest opens the SSE connection for a stream: true request and
+// returns a channel fed by a goroutine parsing "data: " lines as they
+// arrive.
+func (c *OpenRouterClient) makeStreamRequest(ctx context.Context, endpoint string, payload interface{}) (<-chan string, error) {
+	resp, err := c.openStream(ctx, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan string)
+	go func() {
+		defer close(deltas)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+
+			data := strings.TrimPrefix(line, "data: ")
+			if data == streamDoneMarker {
+				return
+			}
+
+			var chunk chatCompletionChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				log.Printf("Failed to decode OpenRouter stream chunk: %v", err)
+				continue
+			}
+
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+				continue
+			}
+
+			select {
+			case deltas <- chunk.Choices[0].Delta.Content:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Printf("OpenRouter stream read failed: %v", err)
+		}
+	}()
+
+	return deltas, nil
+}
+
+// CheckModelsEndpoint makes a lightweight GET against OpenRouter's /models
+// endpoint to confirm the API is reachable, without spending completion
+// tokens the way OpenRouterBackend.HealthCheck's "ping" request does. This
+// is what deep health-check probes should use instead.
+func (c *OpenRouterClient) CheckModelsEndpoint(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		cause := fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return resilience.ClassifyHTTPError(resp.StatusCode, resp.Header, cause)
+	}
+	return nil
+}
+
+// makeRequest handles HTTP requests to OpenRouter API
+func (c *OpenRouterClient) makeRequest(ctx context.Context, endpoint string, payload interface{}) (*ChatResponse, error) {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	attempt := func(ctx context.Context) (*ChatResponse, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, bytes.NewBuffer(jsonPayload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		req.Header.Set("HTTP-Referer", "https://innerworld.app")
+		req.Header.Set("X-Title", "InnerWorld")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			cause := fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+			return nil, resilience.ClassifyHTTPError(resp.StatusCode, resp.Header, cause)
+		}
+
+		var response ChatResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return &response, nil
+	}
+
+	// With both a breaker and a bulkhead configured, protect the call with
+	// the full retry -> circuit -> bulkhead composition; with just a
+	// breaker (the common case today), keep the single-attempt behavior so
+	// existing callers don't start retrying non-idempotent-looking calls
+	// they didn't ask for.
+	if c.breakers != nil && c.bulkheads != nil {
+		return resilience.Wrap(ctx, c.breakers.Get(openRouterBreakerEndpoint), c.bulkheads.Get(openRouterBreakerEndpoint),
+			resilience.DefaultRetryConfig(), resilience.OpenRouterRetryableErrors,
+			func(ctx context.Context, _ int) (*ChatResponse, error) { return attempt(ctx) })
 	}
 
-	var response ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if c.breakers != nil {
+		var response *ChatResponse
+		err := c.breakers.Execute(ctx, openRouterBreakerEndpoint, func() error {
+			var attemptErr error
+			response, attemptErr = attempt(ctx)
+			return attemptErr
+		})
+		return response, err
 	}
 
-	return &response, nil
+	return attempt(ctx)
 }
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// OpenRouterBackend adapts OpenRouterClient to the Backend interface.
+type OpenRouterBackend struct {
+	client *OpenRouterClient
+}
+
+// NewOpenRouterBackend wraps client as a Backend.
+func NewOpenRouterBackend(client *OpenRouterClient) *OpenRouterBackend {
+	return &OpenRouterBackend{client: client}
+}
+
+// Name identifies this backend as "openrouter".
+func (b *OpenRouterBackend) Name() string {
+	return "openrouter"
+}
+
+// Generate delegates to OpenRouterClient.GenerateResponseWithSystem.
+func (b *OpenRouterBackend) Generate(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	return b.client.GenerateResponseWithSystem(ctx, systemPrompt, userMessage)
+}
+
+// GenerateStream delegates to OpenRouterClient.GenerateStreamWithSystem,
+// which consumes OpenRouter's real SSE stream: true response.
+func (b *OpenRouterBackend) GenerateStream(ctx context.Context, systemPrompt, userMessage string) (<-chan string, error) {
+	return b.client.GenerateStreamWithSystem(ctx, systemPrompt, userMessage)
+}
+
+// HealthCheck makes a minimal completion request to confirm the API key and
+// endpoint are reachable. OpenRouter has no dedicated health endpoint.
+func (b *OpenRouterBackend) HealthCheck(ctx context.Context) error {
+	_, err := b.client.GenerateResponse(ctx, "ping")
+	if err != nil {
+		return fmt.Errorf("openrouter health check failed: %w", err)
+	}
+	return nil
+}
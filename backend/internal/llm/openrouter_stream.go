@@ -0,0 +1,216 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// StreamEventType discriminates StreamEvent's payload.
+type StreamEventType int
+
+const (
+	// StreamEventDelta carries a content token fragment.
+	StreamEventDelta StreamEventType = iota
+	// StreamEventToolCall carries one incremental tool-call argument
+	// fragment.
+	StreamEventToolCall
+	// StreamEventDone is the terminal event closing the stream, carrying
+	// the assembled ChatResponse (content, usage if requested, finish
+	// reason).
+	StreamEventDone
+)
+
+// StreamEvent is one event StreamChannel emits as an OpenRouter stream
+// arrives: a content delta, a tool-call fragment, or the terminal event
+// carrying the fully assembled ChatResponse. Exactly one of Delta/ToolCall/
+// Response is meaningful, selected by Type.
+type StreamEvent struct {
+	Type     StreamEventType
+	Delta    string
+	ToolCall *ToolCallFragment
+	Response *ChatResponse
+}
+
+// ToolCallFragment is one incremental piece of a tool call the model is
+// streaming back. Index identifies which tool call a fragment belongs to
+// when a response makes more than one, since fragments for different calls
+// can interleave across chunks.
+type ToolCallFragment struct {
+	Index     int
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// GenerateResponseStream issues a stream: true request and invokes onDelta
+// for each content fragment as it arrives, assembling and returning the full
+// ChatResponse - including aggregated Usage, when req.StreamOptions asks
+// for it - once the stream completes. Unlike GenerateStreamWithSystem's
+// <-chan string, this blocks until the stream ends, so callers that want
+// the finished turn in hand (e.g. to persist it) after acting on its
+// deltas don't need to reassemble one from a channel themselves. Returns
+// whatever error onDelta returns, stopping the stream early.
+func (c *OpenRouterClient) GenerateResponseStream(ctx context.Context, req ChatRequest, onDelta func(delta string) error) (*ChatResponse, error) {
+	req.Stream = true
+	resp, err := c.openStream(ctx, "/chat/completions", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	response := &ChatResponse{}
+	finishReason := ""
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		data := strings.TrimPrefix(line, "data: ")
+		if data == streamDoneMarker {
+			break
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			log.Printf("Failed to decode OpenRouter stream chunk: %v", err)
+			continue
+		}
+
+		response.ID = chunk.ID
+		response.Model = chunk.Model
+		if chunk.Usage != nil {
+			response.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if reason := chunk.Choices[0].FinishReason; reason != "" {
+			finishReason = reason
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+		content.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, fmt.Errorf("onDelta: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("OpenRouter stream read failed: %w", err)
+	}
+
+	response.Choices = []ChatChoice{{
+		Message:      ChatMessage{Role: "assistant", Content: content.String()},
+		FinishReason: finishReason,
+	}}
+	return response, nil
+}
+
+// StreamChannel is GenerateResponseStream's channel-based counterpart: it
+// returns a channel of StreamEvents - content deltas, tool-call fragments,
+// and a terminal event carrying the assembled ChatResponse - for callers
+// that want to interleave streaming with other channel-driven work (e.g. an
+// SSE handler forwarding events to a browser) instead of blocking on a
+// callback. The initial request is validated synchronously, same as
+// GenerateStreamWithSystem, so auth/4xx failures return before the channel
+// does.
+func (c *OpenRouterClient) StreamChannel(ctx context.Context, req ChatRequest) (<-chan StreamEvent, error) {
+	req.Stream = true
+	resp, err := c.openStream(ctx, "/chat/completions", req)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		var content strings.Builder
+		response := &ChatResponse{}
+
+		emit := func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			data := strings.TrimPrefix(line, "data: ")
+			if data == streamDoneMarker {
+				break
+			}
+
+			var chunk chatCompletionChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				log.Printf("Failed to decode OpenRouter stream chunk: %v", err)
+				continue
+			}
+
+			response.ID = chunk.ID
+			response.Model = chunk.Model
+			if chunk.Usage != nil {
+				response.Usage = *chunk.Usage
+			}
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+
+			finishReason := chunk.Choices[0].FinishReason
+			delta := chunk.Choices[0].Delta
+
+			for _, tc := range delta.ToolCalls {
+				fragment := &ToolCallFragment{
+					Index:     tc.Index,
+					ID:        tc.ID,
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				}
+				if !emit(StreamEvent{Type: StreamEventToolCall, ToolCall: fragment}) {
+					return
+				}
+			}
+
+			if delta.Content != "" {
+				content.WriteString(delta.Content)
+				if !emit(StreamEvent{Type: StreamEventDelta, Delta: delta.Content}) {
+					return
+				}
+			}
+
+			if finishReason != "" {
+				response.Choices = []ChatChoice{{
+					Message:      ChatMessage{Role: "assistant", Content: content.String()},
+					FinishReason: finishReason,
+				}}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Printf("OpenRouter stream read failed: %v", err)
+		}
+
+		if len(response.Choices) == 0 {
+			response.Choices = []ChatChoice{{Message: ChatMessage{Role: "assistant", Content: content.String()}}}
+		}
+		emit(StreamEvent{Type: StreamEventDone, Response: response})
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newStreamTestServer returns an httptest.Server that replays body verbatim
+// as the SSE response to any POST, and a client pointed at it.
+func newStreamTestServer(t *testing.T, body string) (*httptest.Server, *OpenRouterClient) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewOpenRouterClient("test-api-key")
+	client.baseURL = server.URL
+	return server, client
+}
+
+func TestGenerateResponseStream_AssemblesContentAndUsage(t *testing.T) {
+	body := `data: {"id":"r1","model":"m","choices":[{"delta":{"content":"Hel"}}]}
+data: {"id":"r1","model":"m","choices":[{"delta":{"content":"lo"}}]}
+data: {"id":"r1","model":"m","choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"total_tokens":7}}
+data: [DONE]
+`
+	_, client := newStreamTestServer(t, body)
+
+	var deltas []string
+	response, err := client.GenerateResponseStream(context.Background(), ChatRequest{}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateResponseStream() error = %v, want nil", err)
+	}
+	if got := deltas[0] + deltas[1]; got != "Hello" {
+		t.Errorf("deltas joined = %q, want %q", got, "Hello")
+	}
+	if len(response.Choices) != 1 || response.Choices[0].Message.Content != "Hello" {
+		t.Errorf("response.Choices = %+v, want content %q", response.Choices, "Hello")
+	}
+	if response.Choices[0].FinishReason != "stop" {
+		t.Errorf("response.Choices[0].FinishReason = %q, want %q", response.Choices[0].FinishReason, "stop")
+	}
+	if response.Usage.TotalTokens != 7 {
+		t.Errorf("response.Usage.TotalTokens = %d, want 7", response.Usage.TotalTokens)
+	}
+}
+
+func TestGenerateResponseStream_StopsOnOnDeltaError(t *testing.T) {
+	body := `data: {"choices":[{"delta":{"content":"a"}}]}
+data: {"choices":[{"delta":{"content":"b"}}]}
+data: [DONE]
+`
+	_, client := newStreamTestServer(t, body)
+
+	boom := fmt.Errorf("boom")
+	_, err := client.GenerateResponseStream(context.Background(), ChatRequest{}, func(delta string) error {
+		return boom
+	})
+	if err == nil {
+		t.Fatal("expected GenerateResponseStream() to propagate onDelta's error")
+	}
+}
+
+func TestStreamChannel_EmitsDeltasToolCallsAndDoneEvent(t *testing.T) {
+	body := `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call-1","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"x\"}"}}]}}]}
+data: {"choices":[{"delta":{"content":"done"},"finish_reason":"tool_calls"}]}
+data: [DONE]
+`
+	_, client := newStreamTestServer(t, body)
+
+	events, err := client.StreamChannel(context.Background(), ChatRequest{})
+	if err != nil {
+		t.Fatalf("StreamChannel() error = %v, want nil", err)
+	}
+
+	var toolCallCount, deltaCount int
+	var final *ChatResponse
+	for event := range events {
+		switch event.Type {
+		case StreamEventToolCall:
+			toolCallCount++
+			if event.ToolCall.Name != "lookup" && event.ToolCall.Name != "" {
+				t.Errorf("unexpected tool call name %q", event.ToolCall.Name)
+			}
+		case StreamEventDelta:
+			deltaCount++
+		case StreamEventDone:
+			final = event.Response
+		}
+	}
+
+	if toolCallCount != 2 {
+		t.Errorf("toolCallCount = %d, want 2", toolCallCount)
+	}
+	if deltaCount != 1 {
+		t.Errorf("deltaCount = %d, want 1", deltaCount)
+	}
+	if final == nil || len(final.Choices) != 1 || final.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("final response = %+v, want a finish_reason of tool_calls", final)
+	}
+}
@@ -0,0 +1,60 @@
+package llm
+
+import "sync"
+
+// ModelPrice is the USD cost per million prompt/completion tokens for a
+// model, used by EstimateCostUSD to turn a completion's token counts into
+// an estimated spend without calling a live billing API.
+type ModelPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// defaultModelPrices seeds the package's price table with OpenRouter's
+// published per-model rates for what this app actually routes to. It's
+// deliberately small - unlisted models estimate zero cost rather than
+// erroring, since this telemetry is informational and shouldn't block a
+// response. Code can add models or correct rates at runtime via
+// SetPriceTable; nothing in this package loads prices from config.
+var defaultModelPrices = map[string]ModelPrice{
+	"openai/gpt-4o-mini":                   {PromptPerMillion: 0.15, CompletionPerMillion: 0.60},
+	"openai/gpt-4o":                        {PromptPerMillion: 2.50, CompletionPerMillion: 10.00},
+	"anthropic/claude-3.5-sonnet":          {PromptPerMillion: 3.00, CompletionPerMillion: 15.00},
+	"anthropic/claude-3-5-sonnet-20241022": {PromptPerMillion: 3.00, CompletionPerMillion: 15.00},
+	"mock":                                 {PromptPerMillion: 0, CompletionPerMillion: 0},
+}
+
+var (
+	priceTableMutex sync.RWMutex
+	priceTable      = cloneModelPrices(defaultModelPrices)
+)
+
+func cloneModelPrices(prices map[string]ModelPrice) map[string]ModelPrice {
+	clone := make(map[string]ModelPrice, len(prices))
+	for model, price := range prices {
+		clone[model] = price
+	}
+	return clone
+}
+
+// SetPriceTable replaces the table EstimateCostUSD reads from, so rates can
+// be updated or a model added at runtime as pricing changes.
+func SetPriceTable(prices map[string]ModelPrice) {
+	priceTableMutex.Lock()
+	defer priceTableMutex.Unlock()
+	priceTable = cloneModelPrices(prices)
+}
+
+// EstimateCostUSD estimates the USD cost of a completion from model and its
+// token counts. A model absent from the price table estimates 0 rather
+// than erroring.
+func EstimateCostUSD(model string, promptTokens, completionTokens int) float64 {
+	priceTableMutex.RLock()
+	price, ok := priceTable[model]
+	priceTableMutex.RUnlock()
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1_000_000*price.PromptPerMillion +
+		float64(completionTokens)/1_000_000*price.CompletionPerMillion
+}
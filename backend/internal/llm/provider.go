@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Provider is the ChatRequest-based counterpart to Backend: where Backend
+// flattens a call down to (systemPrompt, userMessage) and always answers
+// with whichever model it was constructed for, Provider takes the full
+// ChatRequest (model override, temperature, JSON mode) that Router needs in
+// order to honor a TaskProfile's PreferredModels/RequiresJSON. Router is the
+// only caller that needs this richer shape; everything upstream of it
+// (ConversationChain, BackendRouter) keeps using Backend.
+type Provider interface {
+	// Name identifies the provider for logging, metrics, circuit breaker
+	// keys, and usage attribution (e.g. "openrouter", "anthropic").
+	Name() string
+
+	// Chat produces a response for req, using req.Model if set or the
+	// provider's own default model otherwise.
+	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+}
+
+// backendProvider adapts a Backend to Provider for the four backends
+// (Anthropic, OpenAI, Ollama, gRPC) that only speak Backend's simpler
+// (systemPrompt, userMessage) shape. OpenRouterClient implements Provider
+// directly instead, since ChatRequest is already its native wire format.
+type backendProvider struct {
+	Backend
+}
+
+// AsProvider wraps backend so it can be registered with Router.AddProvider.
+func AsProvider(backend Backend) Provider {
+	return backendProvider{Backend: backend}
+}
+
+// Chat flattens req.Messages into a system prompt and user message and
+// delegates to the wrapped Backend. req.Model is ignored, since Backend has
+// no per-call model override - callers that need a specific model from one
+// of these providers should configure it on the Backend itself (e.g.
+// AnthropicBackend.SetModel) before registering it with Router.
+func (p backendProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	systemPrompt, userMessage := flattenMessages(req.Messages)
+	return p.Backend.Generate(ctx, systemPrompt, userMessage)
+}
+
+// flattenMessages joins every "system" message into systemPrompt and every
+// other message into userMessage, in the order they appear, so a Provider
+// built from a Backend sees the same transcript a multi-message ChatRequest
+// caller intended even though Backend only takes two strings.
+func flattenMessages(messages []ChatMessage) (systemPrompt, userMessage string) {
+	var system, user []string
+	for _, message := range messages {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+		} else {
+			user = append(user, message.Content)
+		}
+	}
+	return strings.Join(system, "\n"), strings.Join(user, "\n")
+}
+
+// TaskProfile describes what a single Router.Chat call needs from a model,
+// so callers stop hardcoding a specific model string (the way
+// extractConversationElements used to hardcode "anthropic/claude-3.5-sonnet")
+// and instead describe their requirements, letting Router pick a
+// provider/model pair that satisfies them.
+type TaskProfile struct {
+	// Latency is a soft preference for how quickly the call should return.
+	// Router does not read it: it is not enforced as a deadline (the
+	// caller's ctx does that) and it does not change the order in which
+	// candidates are tried.
+	Latency time.Duration
+
+	// MaxCostUSD caps the estimated cost of a single completion. Candidates
+	// whose CostAware.EstimateCost exceeds it are skipped entirely. Zero
+	// means no cap.
+	MaxCostUSD float64
+
+	// PreferredModels lists model identifiers in priority order. Router
+	// tries candidates serving these models first, in the order given,
+	// before falling back to every other registered candidate's default
+	// model.
+	PreferredModels []string
+
+	// RequiresJSON marks the call as needing a structured JSON response.
+	// Candidates that report they don't support it via CostAware are
+	// skipped.
+	RequiresJSON bool
+}
+
+// RoutingCost is a provider's static estimate of what serving model would
+// cost/take, used to order and filter candidates under a TaskProfile
+// without a live pricing API call.
+type RoutingCost struct {
+	CostPerCallUSD float64
+	Latency        time.Duration
+	SupportsJSON   bool
+}
+
+// CostAware is implemented by Providers that can estimate RoutingCost for a
+// model from a static table. Providers that don't implement it always pass
+// TaskProfile's MaxCostUSD/RequiresJSON filters.
+type CostAware interface {
+	EstimateCost(model string) RoutingCost
+}
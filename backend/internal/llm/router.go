@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// BackendRouter tries a priority-ordered list of Backends and fails over to
+// the next one on a transport error or rate limit, so a single provider
+// outage doesn't take the whole conversation pipeline down. Crisis-sensitive
+// personas can be pinned to a specific backend (e.g. a higher-quality model)
+// via PinPersona, bypassing the fallback order entirely.
+type BackendRouter struct {
+	backends       []Backend
+	personaPinning map[string]string // persona name -> backend Name()
+}
+
+// NewBackendRouter creates a router trying backends in the given priority
+// order.
+func NewBackendRouter(backends ...Backend) *BackendRouter {
+	return &BackendRouter{
+		backends:       backends,
+		personaPinning: make(map[string]string),
+	}
+}
+
+// PinPersona forces persona to always use the backend with the given name,
+// regardless of priority order. Generate/GenerateStream return an error if
+// no registered backend has that name.
+func (r *BackendRouter) PinPersona(persona, backendName string) {
+	r.personaPinning[strings.ToLower(persona)] = backendName
+}
+
+// Generate tries backends in order (or uses the one pinned to persona) until
+// one succeeds, returning the last error if they all fail.
+func (r *BackendRouter) Generate(ctx context.Context, persona, systemPrompt, userMessage string) (*ChatResponse, string, error) {
+	if pinned, ok := r.personaPinning[strings.ToLower(persona)]; ok {
+		backend, err := r.backendByName(pinned)
+		if err != nil {
+			return nil, "", err
+		}
+		response, err := backend.Generate(ctx, systemPrompt, userMessage)
+		return response, backend.Name(), err
+	}
+
+	var lastErr error
+	for _, backend := range r.backends {
+		response, err := backend.Generate(ctx, systemPrompt, userMessage)
+		if err == nil {
+			return response, backend.Name(), nil
+		}
+
+		if !isFailoverEligible(err) {
+			return nil, backend.Name(), err
+		}
+
+		log.Printf("Backend %s failed, trying next: %v", backend.Name(), err)
+		lastErr = err
+	}
+
+	return nil, "", fmt.Errorf("all backends failed, last error: %w", lastErr)
+}
+
+// GenerateStream is Generate's streaming counterpart, with the same
+// pinning and fallback-order semantics.
+func (r *BackendRouter) GenerateStream(ctx context.Context, persona, systemPrompt, userMessage string) (<-chan string, string, error) {
+	if pinned, ok := r.personaPinning[strings.ToLower(persona)]; ok {
+		backend, err := r.backendByName(pinned)
+		if err != nil {
+			return nil, "", err
+		}
+		deltas, err := backend.GenerateStream(ctx, systemPrompt, userMessage)
+		return deltas, backend.Name(), err
+	}
+
+	var lastErr error
+	for _, backend := range r.backends {
+		deltas, err := backend.GenerateStream(ctx, systemPrompt, userMessage)
+		if err == nil {
+			return deltas, backend.Name(), nil
+		}
+
+		if !isFailoverEligible(err) {
+			return nil, backend.Name(), err
+		}
+
+		log.Printf("Backend %s failed to start stream, trying next: %v", backend.Name(), err)
+		lastErr = err
+	}
+
+	return nil, "", fmt.Errorf("all backends failed, last error: %w", lastErr)
+}
+
+func (r *BackendRouter) backendByName(name string) (Backend, error) {
+	for _, backend := range r.backends {
+		if backend.Name() == name {
+			return backend, nil
+		}
+	}
+	return nil, fmt.Errorf("no backend registered with name %q", name)
+}
+
+// isFailoverEligible reports whether err looks like a transport-level or
+// rate-limit failure worth trying the next backend for, rather than a
+// request-shape problem every backend would reject the same way.
+func isFailoverEligible(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{"rate limit", "429", "503", "502", "connection refused", "timeout", "unavailable"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+
+	return false
+}
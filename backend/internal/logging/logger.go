@@ -0,0 +1,62 @@
+// Package logging provides a single, correlation-ID-aware structured
+// logger built on log/slog, replacing the ad-hoc log.Printf("[MOCK S3] ...")
+// style scattered across this codebase. A logger is carried through
+// context.Context (modeled on net/http's own context-value conventions) so
+// any function downstream of a Lambda entry point logs with the same
+// request_id/user_id/persona/session_id fields without having to thread
+// them through every call signature.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"innerworld-backend/internal/config"
+)
+
+// ctxKey is an unexported type so logging's context key can never collide
+// with a key set by another package.
+type ctxKey struct{}
+
+// New builds the process-wide logger for cfg: JSON to stdout in production
+// (so CloudWatch Insights can query individual fields), human-readable text
+// in development, and Debug-level records enabled whenever cfg.Debug is
+// set. High-volume Debug records are thinned by cfg.Logging.DebugSampleEvery
+// via a SamplingHandler.
+func New(cfg *config.Config) *slog.Logger {
+	level := slog.LevelInfo
+	if cfg.Debug {
+		level = slog.LevelDebug
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if cfg.IsProduction() {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	handler = NewSamplingHandler(handler, cfg.Logging.DebugSampleEvery)
+
+	return slog.New(handler)
+}
+
+// ContextWithLogger returns a copy of ctx carrying logger, retrievable via
+// FromContext.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the logger attached to ctx by ContextWithLogger, or
+// slog.Default() if ctx carries none - so code that forgets to propagate
+// the context-scoped logger degrades to an unadorned default rather than
+// panicking.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
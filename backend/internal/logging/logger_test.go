@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"innerworld-backend/internal/config"
+)
+
+func TestContextWithLogger_RoundTrips(t *testing.T) {
+	logger, _ := NewRecorder()
+	ctx := ContextWithLogger(context.Background(), logger)
+
+	if got := FromContext(ctx); got != logger {
+		t.Errorf("FromContext() = %p, want %p", got, logger)
+	}
+}
+
+func TestFromContext_DefaultsWhenUnset(t *testing.T) {
+	if got := FromContext(context.Background()); got != slog.Default() {
+		t.Errorf("FromContext(empty ctx) = %p, want slog.Default() (%p)", got, slog.Default())
+	}
+}
+
+func TestNew_ProductionUsesJSONAndInfoLevel(t *testing.T) {
+	cfg := &config.Config{Environment: "production", Debug: false}
+	logger := New(cfg)
+
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected Debug level disabled in production without Debug flag")
+	}
+	if !logger.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("expected Info level enabled")
+	}
+
+	sampling, ok := logger.Handler().(*SamplingHandler)
+	if !ok {
+		t.Fatalf("logger.Handler() = %T, want *SamplingHandler", logger.Handler())
+	}
+	if _, ok := sampling.next.(*slog.JSONHandler); !ok {
+		t.Errorf("wrapped handler = %T, want *slog.JSONHandler in production", sampling.next)
+	}
+}
+
+func TestNew_DebugFlagEnablesDebugLevel(t *testing.T) {
+	cfg := &config.Config{Environment: "development", Debug: true}
+	logger := New(cfg)
+
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected Debug level enabled when cfg.Debug is true")
+	}
+}
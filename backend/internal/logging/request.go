@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithRequestAttrs attaches requestID/userID/persona/sessionID to logger as
+// structured attrs (any left blank are omitted rather than logged as empty
+// strings) and seeds ctx so every FromContext(ctx) call downstream - across
+// S3/Neptune calls, health probes, whatever the request touches - logs the
+// same correlation fields automatically.
+func WithRequestAttrs(ctx context.Context, logger *slog.Logger, requestID, userID, persona, sessionID string) context.Context {
+	var attrs []any
+	if requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+	if userID != "" {
+		attrs = append(attrs, "user_id", userID)
+	}
+	if persona != "" {
+		attrs = append(attrs, "persona", persona)
+	}
+	if sessionID != "" {
+		attrs = append(attrs, "session_id", sessionID)
+	}
+
+	if len(attrs) > 0 {
+		logger = logger.With(attrs...)
+	}
+	return ContextWithLogger(ctx, logger)
+}
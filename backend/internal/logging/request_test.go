@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithRequestAttrs_AttachesNonEmptyFields(t *testing.T) {
+	logger, recorder := NewRecorder()
+	ctx := WithRequestAttrs(context.Background(), logger, "req-1", "user-1", "courage", "session-1")
+
+	FromContext(ctx).Info("test message")
+
+	for key, value := range map[string]string{
+		"request_id": "req-1",
+		"user_id":    "user-1",
+		"persona":    "courage",
+		"session_id": "session-1",
+	} {
+		if !recorder.ContainsAttr(key, value) {
+			t.Errorf("expected record to carry %s=%q", key, value)
+		}
+	}
+}
+
+func TestWithRequestAttrs_OmitsBlankFields(t *testing.T) {
+	logger, recorder := NewRecorder()
+	ctx := WithRequestAttrs(context.Background(), logger, "req-1", "", "", "")
+
+	FromContext(ctx).Info("test message")
+
+	if recorder.ContainsAttr("user_id", "") {
+		t.Error("expected blank user_id to be omitted, not logged as empty string")
+	}
+	if !recorder.ContainsAttr("request_id", "req-1") {
+		t.Error("expected request_id to still be attached")
+	}
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// SamplingHandler wraps another slog.Handler and forwards only 1 of every N
+// Debug-level records it sees, so a hot path logging at Debug per-iteration
+// doesn't flood CloudWatch. Info level and above always pass through
+// unsampled.
+type SamplingHandler struct {
+	next    slog.Handler
+	every   int
+	counter atomic.Uint64
+}
+
+// NewSamplingHandler wraps next, keeping 1 of every `every` Debug records.
+// every <= 1 disables sampling (every Debug record passes through).
+func NewSamplingHandler(next slog.Handler, every int) *SamplingHandler {
+	if every < 1 {
+		every = 1
+	}
+	return &SamplingHandler{next: next, every: every}
+}
+
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.every > 1 && record.Level < slog.LevelInfo {
+		n := h.counter.Add(1)
+		if n%uint64(h.every) != 0 {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), every: h.every}
+}
+
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), every: h.every}
+}
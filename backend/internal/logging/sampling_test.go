@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestSamplingHandler_KeepsEveryInfoRecord(t *testing.T) {
+	_, recorder := NewRecorder()
+	handler := NewSamplingHandler(recorder, 3)
+	logger := slog.New(handler)
+
+	for i := 0; i < 6; i++ {
+		logger.Info("info message")
+	}
+
+	if got := len(recorder.Records()); got != 6 {
+		t.Errorf("got %d Info records, want 6 (Info should never be sampled)", got)
+	}
+}
+
+func TestSamplingHandler_ThinsDebugRecords(t *testing.T) {
+	_, recorder := NewRecorder()
+	handler := NewSamplingHandler(recorder, 3)
+	logger := slog.New(handler)
+
+	for i := 0; i < 9; i++ {
+		logger.Debug("debug message")
+	}
+
+	if got := len(recorder.Records()); got != 3 {
+		t.Errorf("got %d Debug records, want 3 (1 of every 3 kept)", got)
+	}
+}
+
+func TestNewSamplingHandler_NonPositiveEveryDisablesSampling(t *testing.T) {
+	_, recorder := NewRecorder()
+	handler := NewSamplingHandler(recorder, 0)
+	logger := slog.New(handler)
+
+	for i := 0; i < 4; i++ {
+		logger.Debug("debug message")
+	}
+
+	if got := len(recorder.Records()); got != 4 {
+		t.Errorf("got %d Debug records, want 4 (every <= 1 disables sampling)", got)
+	}
+}
+
+func TestSamplingHandler_Enabled_DelegatesToNext(t *testing.T) {
+	base := slog.NewJSONHandler(nil, &slog.HandlerOptions{Level: slog.LevelWarn})
+	handler := NewSamplingHandler(base, 1)
+
+	if handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected Debug disabled when wrapped handler's level is Warn")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelError) {
+		t.Error("expected Error enabled")
+	}
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Recorder captures slog records in memory, for tests that assert on what
+// was logged instead of scraping stdout. Multiple Recorder values returned
+// from WithAttrs share the same underlying store, so records logged through
+// a logger.With(...) logger land in that store carrying its attrs; records
+// from other loggers do not get them.
+type Recorder struct {
+	store *recorderStore
+	attrs []slog.Attr
+}
+
+type recorderStore struct {
+	mutex   sync.Mutex
+	records []slog.Record
+}
+
+// NewRecorder builds a slog.Logger whose records are captured into the
+// returned Recorder rather than written anywhere:
+//
+//	logger, recorder := logging.NewRecorder()
+//	ctx := logging.ContextWithLogger(context.Background(), logger)
+//	...
+//	if !recorder.ContainsAttr("user_id", "abc123") { t.Fatal(...) }
+func NewRecorder() (*slog.Logger, *Recorder) {
+	r := &Recorder{store: &recorderStore{}}
+	return slog.New(r), r
+}
+
+func (r *Recorder) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+func (r *Recorder) Handle(ctx context.Context, record slog.Record) error {
+	full := record.Clone()
+	if len(r.attrs) > 0 {
+		full.AddAttrs(r.attrs...)
+	}
+	r.store.mutex.Lock()
+	r.store.records = append(r.store.records, full)
+	r.store.mutex.Unlock()
+	return nil
+}
+
+func (r *Recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	combined := make([]slog.Attr, 0, len(r.attrs)+len(attrs))
+	combined = append(combined, r.attrs...)
+	combined = append(combined, attrs...)
+	return &Recorder{store: r.store, attrs: combined}
+}
+
+func (r *Recorder) WithGroup(name string) slog.Handler {
+	// Groups aren't modeled by this simple test recorder - assertions care
+	// about attr key presence, not nesting.
+	return r
+}
+
+// Records returns a snapshot of every record captured so far.
+func (r *Recorder) Records() []slog.Record {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+	out := make([]slog.Record, len(r.store.records))
+	copy(out, r.store.records)
+	return out
+}
+
+// Messages returns every captured record's message, in the order recorded.
+func (r *Recorder) Messages() []string {
+	records := r.Records()
+	messages := make([]string, len(records))
+	for i, record := range records {
+		messages[i] = record.Message
+	}
+	return messages
+}
+
+// ContainsAttr reports whether any captured record carries the attr
+// key=value, including attrs attached via logger.With(...).
+func (r *Recorder) ContainsAttr(key string, value any) bool {
+	for _, record := range r.Records() {
+		found := false
+		record.Attrs(func(a slog.Attr) bool {
+			if a.Key == key && a.Value.Any() == value {
+				found = true
+				return false
+			}
+			return true
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestRecorder_CapturesMessageAndAttrs(t *testing.T) {
+	logger, recorder := NewRecorder()
+	logger.Info("hello", "key", "value")
+
+	messages := recorder.Messages()
+	if len(messages) != 1 || messages[0] != "hello" {
+		t.Errorf("Messages() = %v, want [\"hello\"]", messages)
+	}
+	if !recorder.ContainsAttr("key", "value") {
+		t.Error("expected recorder to contain key=value")
+	}
+}
+
+func TestRecorder_WithAttrsPersistAcrossDerivedLoggers(t *testing.T) {
+	logger, recorder := NewRecorder()
+	derived := logger.With("user_id", "u-1")
+	derived.Info("did a thing")
+
+	if !recorder.ContainsAttr("user_id", "u-1") {
+		t.Error("expected attrs from logger.With(...) to be captured on the record")
+	}
+}
+
+func TestRecorder_MultipleDerivedLoggersDontCrossContaminate(t *testing.T) {
+	logger, recorder := NewRecorder()
+	a := logger.With("scope", "a")
+	b := logger.With("scope", "b")
+
+	a.Info("from a")
+	b.Info("from b")
+
+	records := recorder.Records()
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	scopeOf := func(r slog.Record) string {
+		var scope string
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "scope" {
+				scope = a.Value.String()
+				return false
+			}
+			return true
+		})
+		return scope
+	}
+
+	if scopeOf(records[0]) != "a" || scopeOf(records[1]) != "b" {
+		t.Errorf("scopes = %q, %q, want \"a\", \"b\"", scopeOf(records[0]), scopeOf(records[1]))
+	}
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// emfNamespace is the CloudWatch namespace embedded metrics are published
+// under.
+const emfNamespace = "InnerWorld/Conversation"
+
+// CloudWatchEMFSink writes each ConversationMetrics as a CloudWatch Embedded
+// Metric Format log line to stdout. Lambda ships stdout to CloudWatch Logs,
+// where the EMF agent extracts the metrics without a separate PutMetricData
+// call, so this needs no AWS SDK client of its own.
+type CloudWatchEMFSink struct{}
+
+// NewCloudWatchEMFSink creates an EMF sink.
+func NewCloudWatchEMFSink() *CloudWatchEMFSink {
+	return &CloudWatchEMFSink{}
+}
+
+// emfDocument mirrors the shape CloudWatch expects: an `_aws` metadata block
+// describing which top-level keys are metrics, alongside those keys and any
+// dimension fields.
+type emfDocument struct {
+	AWS struct {
+		Timestamp         int64 `json:"Timestamp"`
+		CloudWatchMetrics []struct {
+			Namespace  string              `json:"Namespace"`
+			Dimensions [][]string          `json:"Dimensions"`
+			Metrics    []map[string]string `json:"Metrics"`
+		} `json:"CloudWatchMetrics"`
+	} `json:"_aws"`
+	Persona          string  `json:"Persona"`
+	Model            string  `json:"Model"`
+	SafetyCheckMs    int64   `json:"SafetyCheckMs"`
+	ContextLoadMs    int64   `json:"ContextLoadMs"`
+	LLMLatencyMs     int64   `json:"LLMLatencyMs"`
+	OutputSafetyMs   int64   `json:"OutputSafetyMs"`
+	StorageLatencyMs int64   `json:"StorageLatencyMs"`
+	TotalMs          int64   `json:"TotalMs"`
+	PromptTokens     int     `json:"PromptTokens"`
+	CompletionTokens int     `json:"CompletionTokens"`
+	EstimatedCostUSD float64 `json:"EstimatedCostUsd"`
+	SessionID        string  `json:"SessionId"`
+}
+
+// Record emits m as a single EMF log line. A marshal failure is logged and
+// otherwise ignored; metrics are best-effort and must never fail the
+// conversation they describe.
+func (s *CloudWatchEMFSink) Record(ctx context.Context, m ConversationMetrics) {
+	doc := emfDocument{
+		Persona:          m.PersonaID,
+		Model:            m.ModelID,
+		SafetyCheckMs:    m.SafetyCheckMs,
+		ContextLoadMs:    m.ContextLoadMs,
+		LLMLatencyMs:     m.LLMLatencyMs,
+		OutputSafetyMs:   m.OutputSafetyMs,
+		StorageLatencyMs: m.StorageLatencyMs,
+		TotalMs:          m.TotalMs,
+		PromptTokens:     m.PromptTokens,
+		CompletionTokens: m.CompletionTokens,
+		EstimatedCostUSD: m.EstimatedCostUSD,
+		SessionID:        m.SessionID,
+	}
+	doc.AWS.Timestamp = time.Now().UnixMilli()
+	doc.AWS.CloudWatchMetrics = []struct {
+		Namespace  string              `json:"Namespace"`
+		Dimensions [][]string          `json:"Dimensions"`
+		Metrics    []map[string]string `json:"Metrics"`
+	}{
+		{
+			Namespace:  emfNamespace,
+			Dimensions: [][]string{{"Persona", "Model"}},
+			Metrics: []map[string]string{
+				{"Name": "SafetyCheckMs", "Unit": "Milliseconds"},
+				{"Name": "ContextLoadMs", "Unit": "Milliseconds"},
+				{"Name": "LLMLatencyMs", "Unit": "Milliseconds"},
+				{"Name": "OutputSafetyMs", "Unit": "Milliseconds"},
+				{"Name": "StorageLatencyMs", "Unit": "Milliseconds"},
+				{"Name": "TotalMs", "Unit": "Milliseconds"},
+				{"Name": "PromptTokens", "Unit": "Count"},
+				{"Name": "CompletionTokens", "Unit": "Count"},
+				{"Name": "EstimatedCostUsd", "Unit": "None"},
+			},
+		},
+	}
+
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		log.Printf("failed to marshal EMF metrics: %v", err)
+		return
+	}
+
+	fmt.Println(string(payload))
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PrometheusSink accumulates ConversationMetrics in memory, keyed by persona,
+// and serves them in Prometheus text exposition format. It has no dependency
+// on a Prometheus client library so it can be reused by the Lambda build
+// (which has no long-lived process to scrape) as well as by any standalone
+// HTTP deployment that adds a /metrics route.
+type PrometheusSink struct {
+	mutex sync.Mutex
+	stats map[string]*personaStats // keyed by persona
+}
+
+type personaStats struct {
+	count            int64
+	safetyCheckMs    int64
+	contextLoadMs    int64
+	llmLatencyMs     int64
+	outputSafetyMs   int64
+	storageLatencyMs int64
+	totalMs          int64
+	promptTokens     int64
+	completionTokens int64
+	estimatedCostUSD float64
+	safetyBlocked    int64
+}
+
+// NewPrometheusSink creates an empty Prometheus sink.
+func NewPrometheusSink() *PrometheusSink {
+	return &PrometheusSink{stats: make(map[string]*personaStats)}
+}
+
+// Record adds m to the running totals for m.PersonaID.
+func (s *PrometheusSink) Record(ctx context.Context, m ConversationMetrics) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stat, ok := s.stats[m.PersonaID]
+	if !ok {
+		stat = &personaStats{}
+		s.stats[m.PersonaID] = stat
+	}
+
+	stat.count++
+	stat.safetyCheckMs += m.SafetyCheckMs
+	stat.contextLoadMs += m.ContextLoadMs
+	stat.llmLatencyMs += m.LLMLatencyMs
+	stat.outputSafetyMs += m.OutputSafetyMs
+	stat.storageLatencyMs += m.StorageLatencyMs
+	stat.totalMs += m.TotalMs
+	stat.promptTokens += int64(m.PromptTokens)
+	stat.completionTokens += int64(m.CompletionTokens)
+	stat.estimatedCostUSD += m.EstimatedCostUSD
+	if !m.SafetyPassed {
+		stat.safetyBlocked++
+	}
+}
+
+// ServeHTTP writes the accumulated counters in Prometheus text exposition
+// format, suitable for mounting as a scrape endpoint.
+func (s *PrometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	personas := make([]string, 0, len(s.stats))
+	for persona := range s.stats {
+		personas = append(personas, persona)
+	}
+	sort.Strings(personas)
+
+	var b strings.Builder
+	writeHelp(&b, "innerworld_conversations_total", "Total conversations processed, by persona")
+	writeHelp(&b, "innerworld_conversation_safety_blocked_total", "Conversations blocked by safety moderation, by persona")
+	writeHelp(&b, "innerworld_conversation_total_ms_sum", "Sum of end-to-end conversation latency in milliseconds, by persona")
+	writeHelp(&b, "innerworld_conversation_llm_latency_ms_sum", "Sum of LLM call latency in milliseconds, by persona")
+	writeHelp(&b, "innerworld_conversation_prompt_tokens_total", "Total prompt tokens consumed, by persona")
+	writeHelp(&b, "innerworld_conversation_completion_tokens_total", "Total completion tokens generated, by persona")
+	writeHelp(&b, "innerworld_conversation_estimated_cost_usd_sum", "Sum of estimated LLM spend in USD, by persona")
+
+	for _, persona := range personas {
+		stat := s.stats[persona]
+		label := fmt.Sprintf(`persona="%s"`, persona)
+		fmt.Fprintf(&b, "innerworld_conversations_total{%s} %d\n", label, stat.count)
+		fmt.Fprintf(&b, "innerworld_conversation_safety_blocked_total{%s} %d\n", label, stat.safetyBlocked)
+		fmt.Fprintf(&b, "innerworld_conversation_total_ms_sum{%s} %d\n", label, stat.totalMs)
+		fmt.Fprintf(&b, "innerworld_conversation_llm_latency_ms_sum{%s} %d\n", label, stat.llmLatencyMs)
+		fmt.Fprintf(&b, "innerworld_conversation_prompt_tokens_total{%s} %d\n", label, stat.promptTokens)
+		fmt.Fprintf(&b, "innerworld_conversation_completion_tokens_total{%s} %d\n", label, stat.completionTokens)
+		fmt.Fprintf(&b, "innerworld_conversation_estimated_cost_usd_sum{%s} %g\n", label, stat.estimatedCostUSD)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
+
+func writeHelp(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
@@ -0,0 +1,235 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets mirrors client_golang's DefBuckets, covering sub-10ms
+// calls (embedding cache hits) through multi-second ones (LLM completions)
+// with the same bucket boundaries operators scraping other Go services
+// already expect.
+var defaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// counterVec is a prometheus_client-style counter, one int64 per distinct
+// label value tuple.
+type counterVec struct {
+	name, help string
+	labelNames []string
+
+	mutex  sync.Mutex
+	counts map[string]int64
+}
+
+func newCounterVec(name, help string, labelNames ...string) *counterVec {
+	return &counterVec{name: name, help: help, labelNames: labelNames, counts: make(map[string]int64)}
+}
+
+// Inc increments the counter for labelValues, given positionally in the
+// same order as labelNames.
+func (c *counterVec) Inc(labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts[labelKey(labelValues)]++
+}
+
+func (c *counterVec) writeTo(b *strings.Builder) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	writeHelp(b, c.name, c.help)
+	keys := sortedKeys(c.counts)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%s{%s} %d\n", c.name, labelString(c.labelNames, key), c.counts[key])
+	}
+}
+
+// histogramVec is a prometheus_client-style histogram, tracking cumulative
+// bucket counts, a sum, and a count per distinct label value tuple.
+type histogramVec struct {
+	name, help string
+	labelNames []string
+	buckets    []float64
+
+	mutex sync.Mutex
+	stats map[string]*histogramStats
+}
+
+type histogramStats struct {
+	bucketCounts []int64
+	sum          float64
+	count        int64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labelNames ...string) *histogramVec {
+	return &histogramVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		buckets:    buckets,
+		stats:      make(map[string]*histogramStats),
+	}
+}
+
+// Observe records value (in the histogram's declared unit, seconds for
+// every histogram this package defines) against labelValues.
+func (h *histogramVec) Observe(value float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := labelKey(labelValues)
+	stat, ok := h.stats[key]
+	if !ok {
+		stat = &histogramStats{bucketCounts: make([]int64, len(h.buckets))}
+		h.stats[key] = stat
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			stat.bucketCounts[i]++
+		}
+	}
+	stat.sum += value
+	stat.count++
+}
+
+// ObserveDuration is a convenience for the common case of timing a call:
+// Observe(time.Since(start).Seconds(), labelValues...).
+func (h *histogramVec) ObserveDuration(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *histogramVec) writeTo(b *strings.Builder) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	keys := sortedKeys(h.stats)
+	for _, key := range keys {
+		stat := h.stats[key]
+		labels := labelString(h.labelNames, key)
+
+		// Observe already counts each value in every bucket whose bound it
+		// fits under, so bucketCounts is cumulative as stored.
+		for i, bound := range h.buckets {
+			fmt.Fprintf(b, "%s_bucket{%sle=\"%s\"} %d\n", h.name, leLabelPrefix(labels), formatBucketBound(bound), stat.bucketCounts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%sle=\"+Inf\"} %d\n", h.name, leLabelPrefix(labels), stat.count)
+		fmt.Fprintf(b, "%s_sum{%s} %g\n", h.name, labels, stat.sum)
+		fmt.Fprintf(b, "%s_count{%s} %d\n", h.name, labels, stat.count)
+	}
+}
+
+// leLabelPrefix prepends labels (if any) to the "le" bucket bound label, so
+// a label-less histogram still emits valid `{le="..."}` instead of a
+// dangling leading comma.
+func leLabelPrefix(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return labels + ","
+}
+
+func formatBucketBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
+
+// labelKey joins labelValues into a map key. Values aren't expected to
+// contain NUL, so it's a safe separator for this package's small,
+// internally-controlled label set (persona names, model IDs, operation
+// names).
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\x00")
+}
+
+func labelString(labelNames []string, key string) string {
+	if key == "" {
+		return ""
+	}
+	values := strings.Split(key, "\x00")
+	parts := make([]string, len(labelNames))
+	for i, name := range labelNames {
+		parts[i] = fmt.Sprintf(`%s="%s"`, name, values[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Registered metrics for the conversation pipeline. Each is a package-level
+// singleton rather than a constructed Registry, matching how callers already
+// reach NoopSink/PrometheusSink as values rather than threading a registry
+// handle through every layer.
+var (
+	// ConversationRequestsTotal counts ConversationChain.ProcessConversation
+	// calls by persona and outcome ("ok", "blocked", "error").
+	ConversationRequestsTotal = newCounterVec(
+		"conversation_requests_total",
+		"Total conversation requests processed, by persona and status",
+		"persona", "status",
+	)
+
+	// SafetyCheckFailuresTotal counts safety.SafetyModerator verdicts that
+	// disallowed a message, by direction ("input" or "output").
+	SafetyCheckFailuresTotal = newCounterVec(
+		"safety_check_failures_total",
+		"Total messages blocked by safety moderation, by direction",
+		"direction",
+	)
+
+	// LLMRequestDuration times llm.OpenRouterClient.GenerateResponseWithSystem
+	// calls (GenerateResponse included), by model; Chat and the streaming
+	// calls are not timed.
+	LLMRequestDuration = newHistogramVec(
+		"llm_request_duration_seconds",
+		"LLM chat completion request latency in seconds, by model",
+		defaultLatencyBuckets,
+		"model",
+	)
+
+	// DynamoDBOperationDuration times storage.AWSDynamoDBClient calls
+	// (including internal retries), by operation.
+	DynamoDBOperationDuration = newHistogramVec(
+		"dynamodb_operation_duration_seconds",
+		"DynamoDB operation latency in seconds, by operation",
+		defaultLatencyBuckets,
+		"op",
+	)
+
+	// NeptuneContextLoadDuration times GraphRAG context retrieval from
+	// Neptune (graph.NeptuneClient.QuerySimilarNodes/GetNeighbors).
+	NeptuneContextLoadDuration = newHistogramVec(
+		"neptune_context_load_duration_seconds",
+		"Neptune GraphRAG context load latency in seconds",
+		defaultLatencyBuckets,
+	)
+)
+
+// Handler serves every metric registered above in Prometheus text
+// exposition format, for mounting as a /metrics route in the Lambda
+// local-dev server during load tests.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		ConversationRequestsTotal.writeTo(&b)
+		SafetyCheckFailuresTotal.writeTo(&b)
+		LLMRequestDuration.writeTo(&b)
+		DynamoDBOperationDuration.writeTo(&b)
+		NeptuneContextLoadDuration.writeTo(&b)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(b.String()))
+	})
+}
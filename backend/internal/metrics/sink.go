@@ -0,0 +1,39 @@
+// Package metrics defines a pluggable sink for per-conversation telemetry,
+// with CloudWatch EMF and Prometheus implementations that plug into
+// workflow.ConversationChain without it knowing which backend is in use.
+package metrics
+
+import "context"
+
+// ConversationMetrics captures the per-request timing and cost data recorded
+// on every ProcessConversation call, so SRE dashboards can break down where
+// latency and spend go and attribute both to a persona.
+type ConversationMetrics struct {
+	SessionID        string
+	PersonaID        string
+	ModelID          string
+	SafetyCheckMs    int64
+	ContextLoadMs    int64
+	LLMLatencyMs     int64
+	OutputSafetyMs   int64
+	StorageLatencyMs int64
+	TotalMs          int64
+	PromptTokens     int
+	CompletionTokens int
+	EstimatedCostUSD float64
+	SafetyPassed     bool
+}
+
+// Sink records ConversationMetrics to a monitoring backend. Implementations
+// should not block the conversation on a slow or unavailable backend; they
+// should log and drop instead.
+type Sink interface {
+	Record(ctx context.Context, m ConversationMetrics)
+}
+
+// NoopSink discards every metric. It's the default for ConversationChain so
+// callers that never configure a Sink pay no cost and see no behavior change.
+type NoopSink struct{}
+
+// Record does nothing.
+func (NoopSink) Record(ctx context.Context, m ConversationMetrics) {}
@@ -0,0 +1,201 @@
+package personas
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// baseTemplateFile holds shared defaults that individual persona files can
+// override a subset of fields on top of (system_prompt, tone, boundaries, examples).
+const baseTemplateFile = "_base.yaml"
+
+// NewPersonaLoaderFromDir creates a persona loader that reads persona
+// definitions from YAML files in dir and hot-reloads them as files change,
+// so product managers can add personas (e.g. "mindfulness coach", "study
+// buddy") via config commits instead of Go code changes. Personas are merged
+// on top of _base.yaml, if present, so a file only needs to specify the
+// fields it wants to override.
+func NewPersonaLoaderFromDir(dir string) (*PersonaLoader, error) {
+	loader := &PersonaLoader{
+		personas: make(map[string]*PersonaConfig),
+	}
+	loader.loadDefaultPersonas()
+
+	if err := loader.loadPersonaDir(dir); err != nil {
+		return nil, fmt.Errorf("failed to load personas from %s: %w", dir, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start persona file watcher: %w", err)
+	}
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("failed to watch persona directory %s: %w", dir, err)
+	}
+
+	loader.dir = dir
+	loader.watcher = watcher
+	loader.done = make(chan struct{})
+
+	go loader.watchForChanges()
+
+	log.Printf("Loaded personas from %s with hot-reload enabled", dir)
+	return loader, nil
+}
+
+// Close stops the persona file watcher. No-op for loaders created with
+// NewPersonaLoader, which don't watch a directory.
+func (p *PersonaLoader) Close() error {
+	if p.watcher == nil {
+		return nil
+	}
+	close(p.done)
+	return p.watcher.Close()
+}
+
+// watchForChanges reloads persona files as fsnotify reports them changing,
+// so the service picks up edits without a restart.
+func (p *PersonaLoader) watchForChanges() {
+	for {
+		select {
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 || !isYAMLFile(event.Name) {
+				continue
+			}
+
+			log.Printf("Persona config changed: %s, reloading", event.Name)
+			if err := p.reloadPersonaFile(event.Name); err != nil {
+				log.Printf("Failed to hot-reload persona file %s: %v", event.Name, err)
+			}
+
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Persona file watcher error: %v", err)
+
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// loadPersonaDir reads every YAML file in dir, merging each on top of the
+// base template (_base.yaml) if one is present.
+func (p *PersonaLoader) loadPersonaDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	base := readBaseTemplate(dir, entries)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == baseTemplateFile || !isYAMLFile(entry.Name()) {
+			continue
+		}
+
+		persona, err := loadPersonaFile(filepath.Join(dir, entry.Name()), base)
+		if err != nil {
+			log.Printf("Skipping invalid persona file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		p.personas[personaKey(persona, entry.Name())] = persona
+	}
+
+	return nil
+}
+
+// reloadPersonaFile re-reads a single changed YAML file and merges it into
+// the in-memory persona map without restarting the service.
+func (p *PersonaLoader) reloadPersonaFile(path string) error {
+	if filepath.Base(path) == baseTemplateFile {
+		// The shared template changed - reload everyone so overrides stay consistent.
+		return p.loadPersonaDir(p.dir)
+	}
+
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return err
+	}
+
+	persona, err := loadPersonaFile(path, readBaseTemplate(p.dir, entries))
+	if err != nil {
+		return err
+	}
+
+	name := personaKey(persona, filepath.Base(path))
+
+	p.mutex.Lock()
+	p.personas[name] = persona
+	p.mutex.Unlock()
+
+	log.Printf("Hot-reloaded persona '%s' from %s", name, path)
+	return nil
+}
+
+// readBaseTemplate loads _base.yaml from dir, if present.
+func readBaseTemplate(dir string, entries []os.DirEntry) *PersonaConfig {
+	for _, entry := range entries {
+		if entry.Name() != baseTemplateFile {
+			continue
+		}
+		base, err := loadPersonaFile(filepath.Join(dir, entry.Name()), nil)
+		if err != nil {
+			log.Printf("Failed to load base persona template: %v", err)
+			return nil
+		}
+		return base
+	}
+	return nil
+}
+
+// loadPersonaFile parses a YAML persona definition, merging it on top of
+// base. yaml.Unmarshal only overwrites fields present in the file, so a
+// persona only needs to declare the overrides it cares about.
+func loadPersonaFile(path string, base *PersonaConfig) (*PersonaConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	persona := &PersonaConfig{}
+	if base != nil {
+		clone := *base
+		persona = &clone
+	}
+
+	if err := yaml.Unmarshal(data, persona); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	return persona, nil
+}
+
+// personaKey derives the map key for a loaded persona: its declared name,
+// falling back to the filename when the file doesn't set one.
+func personaKey(persona *PersonaConfig, filename string) string {
+	if persona.Name != "" {
+		return strings.ToLower(persona.Name)
+	}
+	return strings.ToLower(strings.TrimSuffix(filename, filepath.Ext(filename)))
+}
+
+func isYAMLFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
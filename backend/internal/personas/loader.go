@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"innerworld-backend/internal/llm"
 )
 
 // PersonaConfig defines the structure for persona configuration
@@ -18,8 +23,23 @@ type PersonaConfig struct {
 
 // PersonaLoader handles loading persona configurations
 type PersonaLoader struct {
-	// Phase 2: Use in-memory defaults, Phase 4+: Load from S3/DynamoDB
+	// Phase 2: in-memory defaults, Phase 4+: YAML directory via NewPersonaLoaderFromDir
 	personas map[string]*PersonaConfig
+	mutex    sync.RWMutex
+
+	// dir/watcher/done are only set when the loader was created with
+	// NewPersonaLoaderFromDir and hot-reloads files as they change.
+	dir     string
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+
+	// llmClient powers GetPromptStarters; nil until SetLLMClient is called.
+	llmClient *llm.OpenRouterClient
+}
+
+// SetLLMClient configures the LLM client used to generate prompt starters.
+func (p *PersonaLoader) SetLLMClient(client *llm.OpenRouterClient) {
+	p.llmClient = client
 }
 
 // NewPersonaLoader creates a new persona loader with default configurations
@@ -38,6 +58,9 @@ func NewPersonaLoader() *PersonaLoader {
 func (p *PersonaLoader) LoadPersona(personaName string) (*PersonaConfig, error) {
 	personaName = strings.ToLower(personaName)
 
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	persona, exists := p.personas[personaName]
 	if !exists {
 		// Return default persona if specific one not found
@@ -51,6 +74,9 @@ func (p *PersonaLoader) LoadPersona(personaName string) (*PersonaConfig, error)
 
 // GetAvailablePersonas returns list of available persona names
 func (p *PersonaLoader) GetAvailablePersonas() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	names := make([]string, 0, len(p.personas))
 	for name := range p.personas {
 		if name != "default" { // Exclude internal default
@@ -88,8 +114,19 @@ Focus on validation, gentle questions, and encouraging self-reflection.`,
 	log.Printf("Loaded default persona configuration (Phase 2: template only)")
 }
 
-// FormatPersonaPrompt creates the full system prompt with user context
+// FormatPersonaPrompt creates the full system prompt with user context. It
+// has no GraphRAG-retrieved context; callers with a workflow.ContextRetriever
+// should use FormatPersonaPromptWithContext instead.
 func (p *PersonaLoader) FormatPersonaPrompt(personaName string, userContext map[string]interface{}) (string, error) {
+	return p.FormatPersonaPromptWithContext(personaName, userContext, nil)
+}
+
+// FormatPersonaPromptWithContext is FormatPersonaPrompt with retrievedContext
+// injected as a proper context block (one bullet per snippet) instead of the
+// comma-joined theme summary used when no retrieval layer is configured.
+// retrievedContext is expected to already be ordered most-relevant-first and
+// within whatever token budget the caller enforces.
+func (p *PersonaLoader) FormatPersonaPromptWithContext(personaName string, userContext map[string]interface{}, retrievedContext []string) (string, error) {
 	persona, err := p.LoadPersona(personaName)
 	if err != nil {
 		return "", fmt.Errorf("failed to load persona: %w", err)
@@ -97,8 +134,17 @@ func (p *PersonaLoader) FormatPersonaPrompt(personaName string, userContext map[
 
 	prompt := persona.SystemPrompt
 
-	// Add context if available (Phase 2: basic context, Phase 3+: rich GraphRAG context)
-	if len(userContext) > 0 {
+	switch {
+	case len(retrievedContext) > 0:
+		prompt += "\n\nRelevant context from past conversations:\n"
+		for _, snippet := range retrievedContext {
+			prompt += "- " + snippet + "\n"
+		}
+		prompt += "Reference this context naturally in your responses when relevant, but don't force it."
+
+	case len(userContext) > 0:
+		// Phase 2 fallback: basic comma-joined theme summary when no
+		// GraphRAG retrieval layer is wired up.
 		prompt += fmt.Sprintf("\n\nUser Context: The user has previously discussed themes around %v. "+
 			"Reference this context naturally in your responses when relevant, but don't force it.",
 			extractContextSummary(userContext))
@@ -0,0 +1,96 @@
+package personas
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// defaultPromptStarterLimit is used when callers don't specify how many
+// starters they want.
+const defaultPromptStarterLimit = 5
+
+// maxPromptStarterLimit bounds how many starters a single call can request,
+// so a caller passing an unchecked query-string value can't force an
+// oversized generation prompt.
+const maxPromptStarterLimit = 10
+
+// GetPromptStarters generates opener prompt suggestions tailored to persona
+// and the user's context, via a dedicated meta-prompt built on top of the
+// same FormatPersonaPrompt context injection used for the main conversation,
+// plus the persona's tone and examples. Requires an LLM client configured
+// with SetLLMClient. limit must be between 1 and maxPromptStarterLimit; 0
+// falls back to defaultPromptStarterLimit.
+func (p *PersonaLoader) GetPromptStarters(ctx context.Context, personaName string, userContext map[string]interface{}, limit int) ([]string, error) {
+	if p.llmClient == nil {
+		return nil, fmt.Errorf("prompt starters require an LLM client, call SetLLMClient first")
+	}
+	if limit == 0 {
+		limit = defaultPromptStarterLimit
+	}
+	if limit < 1 || limit > maxPromptStarterLimit {
+		return nil, fmt.Errorf("prompt starter limit must be between 1 and %d, got %d", maxPromptStarterLimit, limit)
+	}
+
+	persona, err := p.LoadPersona(personaName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persona: %w", err)
+	}
+
+	personaPrompt, err := p.FormatPersonaPrompt(personaName, userContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format persona prompt: %w", err)
+	}
+
+	metaPrompt := buildPromptStarterMetaPrompt(persona, personaPrompt, limit)
+
+	response, err := p.llmClient.GenerateResponse(ctx, metaPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate prompt starters: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("no prompt starters returned")
+	}
+
+	return parsePromptStarters(response.Choices[0].Message.Content), nil
+}
+
+// buildPromptStarterMetaPrompt asks the LLM to role-play the persona's
+// author rather than the persona itself, producing short openers a teen
+// could tap instead of a reply in character. personaPrompt is the same
+// FormatPersonaPrompt output (system prompt plus context injection) used to
+// ground the main conversation, so starters reflect whatever context/
+// boundaries a real reply to this user would see.
+func buildPromptStarterMetaPrompt(persona *PersonaConfig, personaPrompt string, limit int) string {
+	var prompt strings.Builder
+
+	fmt.Fprintf(&prompt, "You are helping design opener prompts for the %q persona in a teen wellness app.\n", persona.Name)
+	fmt.Fprintf(&prompt, "Persona tone: %s\n", persona.Tone)
+	fmt.Fprintf(&prompt, "Persona prompt (system prompt plus current user context):\n%s\n", personaPrompt)
+
+	if len(persona.Examples) > 0 {
+		prompt.WriteString("\nExample responses from this persona:\n")
+		for _, example := range persona.Examples {
+			prompt.WriteString("- " + example + "\n")
+		}
+	}
+
+	fmt.Fprintf(&prompt, "\nWrite %d short, first-person opener prompts a teen could tap to start a conversation "+
+		"with this persona. One per line, no numbering or extra commentary.", limit)
+
+	return prompt.String()
+}
+
+// parsePromptStarters splits the LLM's line-per-starter response into a
+// clean slice, stripping any numbering or bullet markers it added anyway.
+func parsePromptStarters(raw string) []string {
+	var starters []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*0123456789.) "))
+		if line == "" {
+			continue
+		}
+		starters = append(starters, line)
+	}
+	return starters
+}
@@ -0,0 +1,186 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBulkheadFull is returned when a Bulkhead's semaphore is saturated and
+// its wait queue is also at MaxQueue, or a queued call's MaxWait elapses
+// before a slot frees up. A caller's ctx that ends first yields ctx.Err()
+// instead.
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// BulkheadConfig configures a Bulkhead's concurrency cap and wait queue.
+type BulkheadConfig struct {
+	MaxConcurrent int           // semaphore size: calls allowed in flight at once
+	MaxQueue      int           // additional callers allowed to wait for a slot before rejecting
+	MaxWait       time.Duration // how long a queued caller waits for a slot; 0 waits until ctx is done
+}
+
+// DefaultBulkheadConfig provides sensible defaults for a downstream
+// dependency called from a Lambda handler, where the container already caps
+// overall concurrency - the bulkhead mainly exists so one slow dependency
+// can't consume every goroutine that healthy dependencies also need.
+func DefaultBulkheadConfig() BulkheadConfig {
+	return BulkheadConfig{
+		MaxConcurrent: 10,
+		MaxQueue:      20,
+		MaxWait:       5 * time.Second,
+	}
+}
+
+// BulkheadStats is a point-in-time snapshot of a Bulkhead's gauges, for
+// Prometheus/CloudWatch EMF exposition.
+type BulkheadStats struct {
+	InFlight     int
+	QueueDepth   int
+	RejectsTotal int64
+}
+
+// Bulkhead caps concurrent in-flight calls to a downstream dependency with a
+// semaphore of size MaxConcurrent, queuing up to MaxQueue more waiters
+// before rejecting with ErrBulkheadFull. This keeps one slow dependency
+// (e.g. an OpenRouter latency spike) from consuming every goroutine/
+// connection the Lambda container has, starving calls to other, healthy
+// dependencies. Safe for concurrent use by multiple goroutines.
+type Bulkhead struct {
+	config BulkheadConfig
+	slots  chan struct{}
+
+	mutex        sync.Mutex
+	inFlight     int
+	queueDepth   int
+	rejectsTotal int64
+}
+
+// NewBulkhead creates a bulkhead with the given config.
+func NewBulkhead(config BulkheadConfig) *Bulkhead {
+	return &Bulkhead{
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// Execute runs fn once a concurrency slot is available, rejecting with
+// ErrBulkheadFull if the wait queue is already at MaxQueue or the wait times
+// out before one frees up.
+func (bh *Bulkhead) Execute(ctx context.Context, fn func() error) error {
+	if err := bh.acquire(ctx); err != nil {
+		return err
+	}
+	defer bh.release()
+
+	return fn()
+}
+
+func (bh *Bulkhead) acquire(ctx context.Context) error {
+	bh.mutex.Lock()
+	if bh.queueDepth >= bh.config.MaxQueue {
+		bh.rejectsTotal++
+		bh.mutex.Unlock()
+		return &ClassifiedError{Kind: KindCircuitOpen, Cause: fmt.Errorf("%w: queue already at capacity (%d)", ErrBulkheadFull, bh.config.MaxQueue)}
+	}
+	bh.queueDepth++
+	bh.mutex.Unlock()
+
+	defer func() {
+		bh.mutex.Lock()
+		bh.queueDepth--
+		bh.mutex.Unlock()
+	}()
+
+	waitCtx := ctx
+	if bh.config.MaxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, bh.config.MaxWait)
+		defer cancel()
+	}
+
+	select {
+	case bh.slots <- struct{}{}:
+		bh.mutex.Lock()
+		bh.inFlight++
+		bh.mutex.Unlock()
+		return nil
+	case <-waitCtx.Done():
+		bh.mutex.Lock()
+		bh.rejectsTotal++
+		bh.mutex.Unlock()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &ClassifiedError{Kind: KindCircuitOpen, Cause: fmt.Errorf("%w: timed out after %v waiting for a slot", ErrBulkheadFull, bh.config.MaxWait)}
+	}
+}
+
+func (bh *Bulkhead) release() {
+	<-bh.slots
+	bh.mutex.Lock()
+	bh.inFlight--
+	bh.mutex.Unlock()
+}
+
+// Stats returns a point-in-time copy of this bulkhead's gauges.
+func (bh *Bulkhead) Stats() BulkheadStats {
+	bh.mutex.Lock()
+	defer bh.mutex.Unlock()
+	return BulkheadStats{
+		InFlight:     bh.inFlight,
+		QueueDepth:   bh.queueDepth,
+		RejectsTotal: bh.rejectsTotal,
+	}
+}
+
+// BulkheadRegistry holds one Bulkhead per logical downstream endpoint, the
+// same keying convention Registry uses for circuit breakers (e.g.
+// "openrouter", "dynamodb:UserContext"), so one dependency's concurrency cap
+// doesn't also throttle calls to every other dependency.
+type BulkheadRegistry struct {
+	mutex     sync.Mutex
+	config    BulkheadConfig
+	bulkheads map[string]*Bulkhead
+}
+
+// NewBulkheadRegistry creates a registry that lazily creates a bulkhead with
+// config the first time each endpoint name is used.
+func NewBulkheadRegistry(config BulkheadConfig) *BulkheadRegistry {
+	return &BulkheadRegistry{
+		config:    config,
+		bulkheads: make(map[string]*Bulkhead),
+	}
+}
+
+// Get returns the bulkhead for endpoint, creating one on first use.
+func (r *BulkheadRegistry) Get(endpoint string) *Bulkhead {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	bulkhead, ok := r.bulkheads[endpoint]
+	if !ok {
+		bulkhead = NewBulkhead(r.config)
+		r.bulkheads[endpoint] = bulkhead
+	}
+	return bulkhead
+}
+
+// Execute runs fn through the bulkhead registered for endpoint.
+func (r *BulkheadRegistry) Execute(ctx context.Context, endpoint string, fn func() error) error {
+	return r.Get(endpoint).Execute(ctx, fn)
+}
+
+// Snapshot returns each endpoint's bulkhead stats, keyed by endpoint name,
+// for Prometheus/CloudWatch EMF exposition.
+func (r *BulkheadRegistry) Snapshot() map[string]BulkheadStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshot := make(map[string]BulkheadStats, len(r.bulkheads))
+	for endpoint, bulkhead := range r.bulkheads {
+		snapshot[endpoint] = bulkhead.Stats()
+	}
+	return snapshot
+}
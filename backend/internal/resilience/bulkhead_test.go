@@ -0,0 +1,167 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBulkheadLimitsConcurrency(t *testing.T) {
+	bh := NewBulkhead(BulkheadConfig{MaxConcurrent: 2, MaxQueue: 10, MaxWait: time.Second})
+
+	var mu sync.Mutex
+	inFlight, maxObserved := 0, 0
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bh.Execute(context.Background(), func() error {
+				mu.Lock()
+				inFlight++
+				if inFlight > maxObserved {
+					maxObserved = inFlight
+				}
+				mu.Unlock()
+
+				<-release
+
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+
+	// Give every goroutine a chance to reach acquire() before releasing.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if maxObserved > 2 {
+		t.Fatalf("expected at most 2 concurrent calls (MaxConcurrent), observed %d", maxObserved)
+	}
+}
+
+func TestBulkheadRejectsWhenQueueFull(t *testing.T) {
+	bh := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Second})
+
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go bh.Execute(context.Background(), func() error {
+		close(holding)
+		<-release
+		return nil
+	})
+	<-holding
+
+	queued := make(chan struct{})
+	go func() {
+		bh.Execute(context.Background(), func() error {
+			return nil
+		})
+		close(queued)
+	}()
+	time.Sleep(20 * time.Millisecond) // let the second call take the one queue slot
+
+	err := bh.Execute(context.Background(), func() error {
+		t.Fatal("fn must not run once MaxQueue is saturated")
+		return nil
+	})
+	if !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull once the queue is at MaxQueue, got %v", err)
+	}
+
+	close(release)
+	<-queued
+}
+
+func TestBulkheadMaxWaitTimesOut(t *testing.T) {
+	bh := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: 10 * time.Millisecond})
+
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go bh.Execute(context.Background(), func() error {
+		close(holding)
+		<-release
+		return nil
+	})
+	<-holding
+	defer close(release)
+
+	start := time.Now()
+	err := bh.Execute(context.Background(), func() error { return nil })
+	if !errors.Is(err, ErrBulkheadFull) {
+		t.Fatalf("expected ErrBulkheadFull after MaxWait elapses, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Fatalf("expected to wait at least MaxWait before rejecting, only waited %v", elapsed)
+	}
+}
+
+func TestBulkheadContextCancellationDuringWait(t *testing.T) {
+	bh := NewBulkhead(BulkheadConfig{MaxConcurrent: 1, MaxQueue: 1, MaxWait: time.Minute})
+
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go bh.Execute(context.Background(), func() error {
+		close(holding)
+		<-release
+		return nil
+	})
+	<-holding
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := bh.Execute(ctx, func() error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's context deadline to win, got %v", err)
+	}
+}
+
+func TestBulkheadStats(t *testing.T) {
+	bh := NewBulkhead(BulkheadConfig{MaxConcurrent: 2, MaxQueue: 5, MaxWait: time.Second})
+
+	holding := make(chan struct{})
+	release := make(chan struct{})
+	go bh.Execute(context.Background(), func() error {
+		close(holding)
+		<-release
+		return nil
+	})
+	<-holding
+	defer close(release)
+
+	stats := bh.Stats()
+	if stats.InFlight != 1 {
+		t.Errorf("expected InFlight=1, got %d", stats.InFlight)
+	}
+}
+
+func TestBulkheadRegistryReusesBulkheadPerEndpoint(t *testing.T) {
+	registry := NewBulkheadRegistry(DefaultBulkheadConfig())
+
+	a := registry.Get("openrouter")
+	b := registry.Get("openrouter")
+	c := registry.Get("dynamodb:UserContext")
+
+	if a != b {
+		t.Fatal("expected the same endpoint name to return the same Bulkhead instance")
+	}
+	if a == c {
+		t.Fatal("expected different endpoint names to get independent Bulkhead instances")
+	}
+
+	registry.Execute(context.Background(), "openrouter", func() error { return nil })
+	snapshot := registry.Snapshot()
+	if _, ok := snapshot["openrouter"]; !ok {
+		t.Fatal("expected a snapshot entry for the endpoint that was used")
+	}
+}
@@ -0,0 +1,241 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// CircuitState represents the circuit breaker state
+type CircuitState int32
+
+const (
+	CircuitClosed   CircuitState = iota // Normal operation
+	CircuitOpen                         // Failing, reject requests
+	CircuitHalfOpen                     // Testing if service recovered
+)
+
+// String renders the state the way it shows up in log lines and metrics labels.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerConfig configures a CircuitBreaker's trip threshold, sliding
+// failure window, and half-open probing.
+type BreakerConfig struct {
+	MaxFailures       int           // failures within FailureWindow before the breaker trips open
+	FailureWindow     time.Duration // sliding window the failure count is measured over
+	ResetTimeout      time.Duration // how long the breaker stays open before allowing a probe
+	MaxHalfOpenProbes int           // concurrent calls allowed through while half-open
+	SuccessesToClose  int           // consecutive half-open successes required to close; 0 behaves as 1
+}
+
+// DefaultBreakerConfig provides sensible defaults for a downstream HTTP/AWS
+// dependency in a Lambda handler.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		MaxFailures:       5,
+		FailureWindow:     30 * time.Second,
+		ResetTimeout:      30 * time.Second,
+		MaxHalfOpenProbes: 1,
+		SuccessesToClose:  1,
+	}
+}
+
+// BreakerStats is a point-in-time snapshot of a CircuitBreaker's counters,
+// for Prometheus/CloudWatch EMF exposition.
+type BreakerStats struct {
+	State        CircuitState
+	OpensTotal   int64
+	RejectsTotal int64
+	ProbesTotal  int64
+	LastSuccess  time.Time // zero if the breaker has never recorded a success
+	FailureRate  float64   // failures / (failures+successes) within FailureWindow, 0 if no calls recorded
+}
+
+// CircuitBreaker is a thread-safe circuit breaker for a single downstream
+// dependency. Failures are tracked in a sliding window (not a monotonic
+// count that only resets on success), so a dependency that fails
+// occasionally but not within MaxFailures in any FailureWindow never trips.
+// Safe for concurrent use by multiple goroutines, unlike the bare
+// unsynchronized counter this replaced.
+type CircuitBreaker struct {
+	config BreakerConfig
+
+	mutex             sync.Mutex
+	state             CircuitState
+	failureTimes      []time.Time // recent failures still inside the window
+	successTimes      []time.Time // recent successes still inside the window, for FailureRate
+	openedAt          time.Time
+	halfOpenProbes    int
+	halfOpenSuccesses int
+	lastSuccess       time.Time
+
+	opensTotal   int64
+	rejectsTotal int64
+	probesTotal  int64
+}
+
+// NewCircuitBreaker creates a breaker in the closed state.
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		state:  CircuitClosed,
+	}
+}
+
+// Execute runs fn through the breaker: rejecting it outright if the circuit
+// is open or (while half-open) at its MaxHalfOpenProbes limit, and otherwise
+// recording the outcome against the sliding failure window.
+func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
+	if err := cb.beforeCall(); err != nil {
+		return err
+	}
+
+	err := fn()
+	cb.afterCall(err)
+	return err
+}
+
+func (cb *CircuitBreaker) beforeCall() error {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) > cb.config.ResetTimeout {
+		cb.state = CircuitHalfOpen
+		cb.halfOpenProbes = 0
+		log.Printf("Circuit breaker transitioning to half-open state")
+	}
+
+	switch cb.state {
+	case CircuitOpen:
+		cb.rejectsTotal++
+		return &ClassifiedError{Kind: KindCircuitOpen, Cause: fmt.Errorf("circuit breaker is open, rejecting request")}
+	case CircuitHalfOpen:
+		if cb.halfOpenProbes >= cb.config.MaxHalfOpenProbes {
+			cb.rejectsTotal++
+			return &ClassifiedError{Kind: KindCircuitOpen, Cause: fmt.Errorf("circuit breaker is half-open and at its probe limit, rejecting request")}
+		}
+		cb.halfOpenProbes++
+		cb.probesTotal++
+	}
+
+	return nil
+}
+
+func (cb *CircuitBreaker) afterCall(err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if cb.state == CircuitHalfOpen {
+		cb.halfOpenProbes--
+	}
+
+	if err != nil {
+		cb.onFailure()
+		return
+	}
+	cb.onSuccess()
+}
+
+// onFailure records a failure against the sliding window and trips the
+// breaker open if the window is now at MaxFailures, or immediately if the
+// failing call was a half-open probe.
+func (cb *CircuitBreaker) onFailure() {
+	now := time.Now()
+	cb.failureTimes = append(pruneWindow(cb.failureTimes, now, cb.config.FailureWindow), now)
+
+	if cb.state == CircuitHalfOpen {
+		cb.trip(now)
+		return
+	}
+
+	if len(cb.failureTimes) >= cb.config.MaxFailures {
+		cb.trip(now)
+	}
+}
+
+func (cb *CircuitBreaker) trip(now time.Time) {
+	if cb.state != CircuitOpen {
+		cb.opensTotal++
+		log.Printf("Circuit breaker opened after %d failures in the last %v", len(cb.failureTimes), cb.config.FailureWindow)
+	}
+	cb.state = CircuitOpen
+	cb.openedAt = now
+	cb.halfOpenSuccesses = 0
+}
+
+func (cb *CircuitBreaker) onSuccess() {
+	now := time.Now()
+	cb.lastSuccess = now
+	cb.successTimes = append(pruneWindow(cb.successTimes, now, cb.config.FailureWindow), now)
+
+	if cb.state != CircuitHalfOpen {
+		return
+	}
+
+	cb.halfOpenSuccesses++
+	successesNeeded := cb.config.SuccessesToClose
+	if successesNeeded < 1 {
+		successesNeeded = 1
+	}
+	if cb.halfOpenSuccesses >= successesNeeded {
+		cb.state = CircuitClosed
+		cb.failureTimes = nil
+		cb.halfOpenSuccesses = 0
+		log.Printf("Circuit breaker closed - service recovered")
+	}
+}
+
+// pruneWindow drops failure timestamps older than window, keeping the slice
+// in a monotonic, already-sorted order since failures are always appended.
+func pruneWindow(times []time.Time, now time.Time, window time.Duration) []time.Time {
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// GetState returns the current circuit breaker state.
+func (cb *CircuitBreaker) GetState() CircuitState {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.state
+}
+
+// Stats returns a point-in-time copy of this breaker's counters, including
+// the rolling failure rate over the last FailureWindow (0 if no calls have
+// been recorded in that window).
+func (cb *CircuitBreaker) Stats() BreakerStats {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := time.Now()
+	failures := pruneWindow(cb.failureTimes, now, cb.config.FailureWindow)
+	successes := pruneWindow(cb.successTimes, now, cb.config.FailureWindow)
+
+	var failureRate float64
+	if total := len(failures) + len(successes); total > 0 {
+		failureRate = float64(len(failures)) / float64(total)
+	}
+
+	return BreakerStats{
+		State:        cb.state,
+		OpensTotal:   cb.opensTotal,
+		RejectsTotal: cb.rejectsTotal,
+		ProbesTotal:  cb.probesTotal,
+		LastSuccess:  cb.lastSuccess,
+		FailureRate:  failureRate,
+	}
+}
@@ -0,0 +1,156 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTripsAfterMaxFailuresInWindow(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		MaxFailures:       3,
+		FailureWindow:     time.Minute,
+		ResetTimeout:      time.Minute,
+		MaxHalfOpenProbes: 1,
+		SuccessesToClose:  1,
+	})
+
+	failing := errors.New("downstream failure")
+	for i := 0; i < 2; i++ {
+		if err := cb.Execute(context.Background(), func() error { return failing }); !errors.Is(err, failing) {
+			t.Fatalf("attempt %d: expected the wrapped failure, got %v", i, err)
+		}
+	}
+
+	if state := cb.GetState(); state != CircuitClosed {
+		t.Fatalf("expected breaker to stay closed below MaxFailures, got %v", state)
+	}
+
+	if err := cb.Execute(context.Background(), func() error { return failing }); !errors.Is(err, failing) {
+		t.Fatalf("expected the 3rd failure's own error, got %v", err)
+	}
+
+	if state := cb.GetState(); state != CircuitOpen {
+		t.Fatalf("expected breaker to open after MaxFailures within FailureWindow, got %v", state)
+	}
+
+	var classified *ClassifiedError
+	err := cb.Execute(context.Background(), func() error {
+		t.Fatal("fn must not run while the breaker is open")
+		return nil
+	})
+	if !errors.As(err, &classified) || classified.Kind != KindCircuitOpen {
+		t.Fatalf("expected a KindCircuitOpen ClassifiedError while open, got %v", err)
+	}
+}
+
+func TestCircuitBreakerSlidingWindowForgivesOldFailures(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		MaxFailures:   3,
+		FailureWindow: 20 * time.Millisecond,
+		ResetTimeout:  time.Minute,
+	})
+
+	failing := errors.New("downstream failure")
+	cb.Execute(context.Background(), func() error { return failing })
+	cb.Execute(context.Background(), func() error { return failing })
+
+	time.Sleep(30 * time.Millisecond)
+
+	// Both earlier failures have aged out of the window, so this third
+	// failure alone must not trip the breaker.
+	cb.Execute(context.Background(), func() error { return failing })
+
+	if state := cb.GetState(); state != CircuitClosed {
+		t.Fatalf("expected failures outside FailureWindow to be forgiven, got %v", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeLimit(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		MaxFailures:       1,
+		FailureWindow:     time.Minute,
+		ResetTimeout:      10 * time.Millisecond,
+		MaxHalfOpenProbes: 1,
+		SuccessesToClose:  1,
+	})
+
+	cb.Execute(context.Background(), func() error { return errors.New("boom") })
+	if state := cb.GetState(); state != CircuitOpen {
+		t.Fatalf("expected breaker to open after the first failure, got %v", state)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	probeErr := make(chan error, 1)
+	go func() {
+		probeErr <- cb.Execute(context.Background(), func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// A second call while the first probe is still in flight must be
+	// rejected outright rather than also being let through.
+	var classified *ClassifiedError
+	if err := cb.Execute(context.Background(), func() error {
+		t.Fatal("a second half-open probe must not run while MaxHalfOpenProbes is already in use")
+		return nil
+	}); !errors.As(err, &classified) || classified.Kind != KindCircuitOpen {
+		t.Fatalf("expected the second probe to be rejected as circuit-open, got %v", err)
+	}
+
+	close(release)
+	if err := <-probeErr; err != nil {
+		t.Fatalf("expected the in-flight probe to succeed, got %v", err)
+	}
+
+	if state := cb.GetState(); state != CircuitClosed {
+		t.Fatalf("expected a successful probe (SuccessesToClose=1) to close the breaker, got %v", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopensImmediately(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerConfig{
+		MaxFailures:       1,
+		FailureWindow:     time.Minute,
+		ResetTimeout:      10 * time.Millisecond,
+		MaxHalfOpenProbes: 1,
+	})
+
+	cb.Execute(context.Background(), func() error { return errors.New("boom") })
+	time.Sleep(15 * time.Millisecond)
+
+	cb.Execute(context.Background(), func() error { return errors.New("still failing") })
+
+	if state := cb.GetState(); state != CircuitOpen {
+		t.Fatalf("expected a failed half-open probe to trip the breaker back open immediately, got %v", state)
+	}
+}
+
+func TestCircuitBreakerConcurrentAccess(t *testing.T) {
+	cb := NewCircuitBreaker(DefaultBreakerConfig())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cb.Execute(context.Background(), func() error {
+				if i%2 == 0 {
+					return errors.New("induced failure")
+				}
+				return nil
+			})
+			cb.Stats()
+			cb.GetState()
+		}(i)
+	}
+	wg.Wait()
+}
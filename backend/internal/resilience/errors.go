@@ -0,0 +1,171 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrorKind classifies a failure so RetryWithBackoff (and any future
+// caller) can make a structured decision instead of substring-scanning
+// err.Error() for things like "rate limit" or "429".
+type ErrorKind int
+
+const (
+	KindUnknown          ErrorKind = iota
+	KindTransient                  // network blip, 5xx, timeout - safe to retry
+	KindThrottled                  // rate limited - retry, honoring RetryAfter
+	KindPermissionDenied           // auth/permission failure - never retry
+	KindInvalidInput               // request shape rejected - every attempt would fail the same way
+	KindCircuitOpen                // rejected locally by a CircuitBreaker, not by the remote service
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindTransient:
+		return "transient"
+	case KindThrottled:
+		return "throttled"
+	case KindPermissionDenied:
+		return "permission_denied"
+	case KindInvalidInput:
+		return "invalid_input"
+	case KindCircuitOpen:
+		return "circuit_open"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifiedError wraps Cause with a Kind callers can switch on via
+// errors.As, plus RetryAfter when the failure carried an explicit
+// retry-after hint (AWS throttling responses, HTTP 429/503 with
+// Retry-After).
+type ClassifiedError struct {
+	Kind       ErrorKind
+	RetryAfter time.Duration
+	Cause      error
+}
+
+func (e *ClassifiedError) Error() string {
+	if e.Cause == nil {
+		return e.Kind.String()
+	}
+	return fmt.Sprintf("%s: %v", e.Kind, e.Cause)
+}
+
+// Unwrap exposes Cause to errors.Is/errors.As.
+func (e *ClassifiedError) Unwrap() error { return e.Cause }
+
+// Retryable reports whether Kind is ever worth retrying. InvalidInput and
+// PermissionDenied never are; CircuitOpen is handled by the breaker's own
+// ResetTimeout rather than RetryWithBackoff, so it isn't either.
+func (e *ClassifiedError) Retryable() bool {
+	switch e.Kind {
+	case KindTransient, KindThrottled:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClassifyAWSError converts an aws-sdk-go-v2/smithy-go error into a
+// ClassifiedError, using the HTTP status and Retry-After-style headers from
+// a smithy-go transport ResponseError where present, and the service's
+// smithy.APIError code as the authoritative signal when it names a
+// recognized exception.
+func ClassifyAWSError(err error) *ClassifiedError {
+	if err == nil {
+		return nil
+	}
+
+	classified := &ClassifiedError{Kind: KindUnknown, Cause: err}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		classified.RetryAfter = retryAfterFromHeader(respErr.Response.Header)
+		classified.Kind = classifyHTTPStatus(respErr.Response.StatusCode)
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if kind, ok := classifyAWSErrorCode(apiErr.ErrorCode()); ok {
+			classified.Kind = kind
+		} else if classified.Kind == KindUnknown {
+			if apiErr.ErrorFault() == smithy.FaultServer {
+				classified.Kind = KindTransient
+			} else {
+				classified.Kind = KindInvalidInput
+			}
+		}
+	}
+
+	if classified.Kind == KindUnknown {
+		classified.Kind = KindTransient
+	}
+
+	return classified
+}
+
+func classifyAWSErrorCode(code string) (ErrorKind, bool) {
+	switch code {
+	case "ThrottlingException", "ProvisionedThroughputExceededException", "RequestLimitExceeded", "TooManyRequestsException":
+		return KindThrottled, true
+	case "AccessDeniedException", "UnauthorizedException", "AccessDenied":
+		return KindPermissionDenied, true
+	case "ValidationException", "ConditionalCheckFailedException", "ResourceNotFoundException":
+		return KindInvalidInput, true
+	case "ServiceUnavailableException", "InternalServerError", "RequestTimeout":
+		return KindTransient, true
+	default:
+		return KindUnknown, false
+	}
+}
+
+// ClassifyHTTPError converts a non-2xx response from a hand-rolled HTTP
+// client (OpenRouter, Anthropic) into a ClassifiedError, the same way
+// ClassifyAWSError does for AWS responses.
+func ClassifyHTTPError(statusCode int, header http.Header, cause error) *ClassifiedError {
+	return &ClassifiedError{
+		Kind:       classifyHTTPStatus(statusCode),
+		RetryAfter: retryAfterFromHeader(header),
+		Cause:      cause,
+	}
+}
+
+func classifyHTTPStatus(statusCode int) ErrorKind {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return KindThrottled
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return KindPermissionDenied
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return KindInvalidInput
+	case statusCode >= 500:
+		return KindTransient
+	default:
+		return KindUnknown
+	}
+}
+
+// retryAfterFromHeader parses a standard HTTP Retry-After header in its
+// seconds form (the only form AWS and OpenRouter both emit in practice).
+func retryAfterFromHeader(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	raw := header.Get("Retry-After")
+	if raw == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,151 @@
+package resilience
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestClassifiedErrorRetryable(t *testing.T) {
+	testCases := []struct {
+		kind      ErrorKind
+		retryable bool
+	}{
+		{KindTransient, true},
+		{KindThrottled, true},
+		{KindPermissionDenied, false},
+		{KindInvalidInput, false},
+		{KindCircuitOpen, false},
+		{KindUnknown, false},
+	}
+
+	for _, tc := range testCases {
+		err := &ClassifiedError{Kind: tc.kind, Cause: errors.New("boom")}
+		if err.Retryable() != tc.retryable {
+			t.Errorf("Kind %v: expected Retryable()=%v, got %v", tc.kind, tc.retryable, err.Retryable())
+		}
+	}
+}
+
+func TestClassifiedErrorUnwrap(t *testing.T) {
+	cause := errors.New("root cause")
+	err := &ClassifiedError{Kind: KindTransient, Cause: cause}
+
+	if !errors.Is(err, cause) {
+		t.Fatal("expected errors.Is to find Cause through Unwrap")
+	}
+
+	var classified *ClassifiedError
+	if !errors.As(err, &classified) || classified != err {
+		t.Fatal("expected errors.As to match the ClassifiedError itself")
+	}
+}
+
+func TestClassifyAWSErrorByCode(t *testing.T) {
+	testCases := []struct {
+		code string
+		kind ErrorKind
+	}{
+		{"ThrottlingException", KindThrottled},
+		{"ProvisionedThroughputExceededException", KindThrottled},
+		{"AccessDeniedException", KindPermissionDenied},
+		{"ValidationException", KindInvalidInput},
+		{"ConditionalCheckFailedException", KindInvalidInput},
+		{"ServiceUnavailableException", KindTransient},
+	}
+
+	for _, tc := range testCases {
+		classified := ClassifyAWSError(&fakeAPIError{code: tc.code, fault: smithy.FaultUnknown})
+		if classified.Kind != tc.kind {
+			t.Errorf("code %s: expected Kind %v, got %v", tc.code, tc.kind, classified.Kind)
+		}
+	}
+}
+
+func TestClassifyAWSErrorUnrecognizedCodeFallsBackToFault(t *testing.T) {
+	serverFault := ClassifyAWSError(&fakeAPIError{code: "SomeNewException", fault: smithy.FaultServer})
+	if serverFault.Kind != KindTransient {
+		t.Errorf("expected an unrecognized server-fault code to classify as transient, got %v", serverFault.Kind)
+	}
+
+	clientFault := ClassifyAWSError(&fakeAPIError{code: "SomeNewException", fault: smithy.FaultClient})
+	if clientFault.Kind != KindInvalidInput {
+		t.Errorf("expected an unrecognized client-fault code to classify as invalid input, got %v", clientFault.Kind)
+	}
+}
+
+func TestClassifyAWSErrorNil(t *testing.T) {
+	if ClassifyAWSError(nil) != nil {
+		t.Fatal("expected ClassifyAWSError(nil) to return nil")
+	}
+}
+
+func TestClassifyAWSErrorRetryAfterFromResponseHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+
+	respErr := &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{
+			Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+		},
+	}
+
+	classified := ClassifyAWSError(respErr)
+	if classified.Kind != KindThrottled {
+		t.Errorf("expected a 429 response to classify as throttled, got %v", classified.Kind)
+	}
+	if classified.RetryAfter != 7*time.Second {
+		t.Errorf("expected RetryAfter to be parsed from the header, got %v", classified.RetryAfter)
+	}
+}
+
+func TestClassifyHTTPError(t *testing.T) {
+	testCases := []struct {
+		status int
+		kind   ErrorKind
+	}{
+		{http.StatusTooManyRequests, KindThrottled},
+		{http.StatusUnauthorized, KindPermissionDenied},
+		{http.StatusForbidden, KindPermissionDenied},
+		{http.StatusBadRequest, KindInvalidInput},
+		{http.StatusUnprocessableEntity, KindInvalidInput},
+		{http.StatusInternalServerError, KindTransient},
+		{http.StatusBadGateway, KindTransient},
+		{http.StatusOK, KindUnknown},
+	}
+
+	for _, tc := range testCases {
+		classified := ClassifyHTTPError(tc.status, nil, errors.New("non-2xx"))
+		if classified.Kind != tc.kind {
+			t.Errorf("status %d: expected Kind %v, got %v", tc.status, tc.kind, classified.Kind)
+		}
+	}
+}
+
+func TestRetryAfterFromHeaderMissingOrInvalid(t *testing.T) {
+	if got := ClassifyHTTPError(500, nil, nil).RetryAfter; got != 0 {
+		t.Errorf("expected no RetryAfter with a nil header, got %v", got)
+	}
+
+	header := http.Header{}
+	header.Set("Retry-After", "not-a-number")
+	if got := ClassifyHTTPError(500, header, nil).RetryAfter; got != 0 {
+		t.Errorf("expected a non-numeric Retry-After to be ignored, got %v", got)
+	}
+}
+
+// fakeAPIError implements smithy.APIError for exercising ClassifyAWSError's
+// error-code branch without depending on a real AWS service error type.
+type fakeAPIError struct {
+	code  string
+	fault smithy.ErrorFault
+}
+
+func (e *fakeAPIError) Error() string                 { return e.code }
+func (e *fakeAPIError) ErrorCode() string             { return e.code }
+func (e *fakeAPIError) ErrorMessage() string          { return e.code }
+func (e *fakeAPIError) ErrorFault() smithy.ErrorFault { return e.fault }
@@ -0,0 +1,72 @@
+package resilience
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrometheusText renders the registry's per-endpoint opens_total,
+// rejects_total, probes_total counters and current state gauge in
+// Prometheus text exposition format. The Lambda build has no scrape target,
+// and nothing in this package serves or logs the text; it is only returned
+// to the caller.
+func (r *Registry) PrometheusText() string {
+	snapshot := r.Snapshot()
+
+	endpoints := make([]string, 0, len(snapshot))
+	for endpoint := range snapshot {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	var b strings.Builder
+	writeBreakerHelp(&b, "innerworld_circuit_breaker_opens_total", "Number of times the breaker has tripped open, by endpoint", "counter")
+	writeBreakerHelp(&b, "innerworld_circuit_breaker_rejects_total", "Number of calls rejected while open or at the half-open probe limit, by endpoint", "counter")
+	writeBreakerHelp(&b, "innerworld_circuit_breaker_probes_total", "Number of half-open probe calls let through, by endpoint", "counter")
+	writeBreakerHelp(&b, "innerworld_circuit_breaker_state", "Current breaker state (0=closed, 1=open, 2=half_open), by endpoint", "gauge")
+
+	for _, endpoint := range endpoints {
+		stats := snapshot[endpoint]
+		label := fmt.Sprintf(`endpoint="%s"`, endpoint)
+		fmt.Fprintf(&b, "innerworld_circuit_breaker_opens_total{%s} %d\n", label, stats.OpensTotal)
+		fmt.Fprintf(&b, "innerworld_circuit_breaker_rejects_total{%s} %d\n", label, stats.RejectsTotal)
+		fmt.Fprintf(&b, "innerworld_circuit_breaker_probes_total{%s} %d\n", label, stats.ProbesTotal)
+		fmt.Fprintf(&b, "innerworld_circuit_breaker_state{%s} %d\n", label, stats.State)
+	}
+
+	return b.String()
+}
+
+func writeBreakerHelp(b *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// PrometheusText renders the registry's per-endpoint in_flight,
+// queue_depth, and rejects_total gauges/counter in Prometheus text
+// exposition format, the same way Registry.PrometheusText does for
+// breakers.
+func (r *BulkheadRegistry) PrometheusText() string {
+	snapshot := r.Snapshot()
+
+	endpoints := make([]string, 0, len(snapshot))
+	for endpoint := range snapshot {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	var b strings.Builder
+	writeBreakerHelp(&b, "innerworld_bulkhead_in_flight", "Calls currently holding a concurrency slot, by endpoint", "gauge")
+	writeBreakerHelp(&b, "innerworld_bulkhead_queue_depth", "Calls currently waiting for a concurrency slot, by endpoint", "gauge")
+	writeBreakerHelp(&b, "innerworld_bulkhead_rejects_total", "Calls rejected because the queue was full or the wait for a slot ended (MaxWait or the caller's context), by endpoint", "counter")
+
+	for _, endpoint := range endpoints {
+		stats := snapshot[endpoint]
+		label := fmt.Sprintf(`endpoint="%s"`, endpoint)
+		fmt.Fprintf(&b, "innerworld_bulkhead_in_flight{%s} %d\n", label, stats.InFlight)
+		fmt.Fprintf(&b, "innerworld_bulkhead_queue_depth{%s} %d\n", label, stats.QueueDepth)
+		fmt.Fprintf(&b, "innerworld_bulkhead_rejects_total{%s} %d\n", label, stats.RejectsTotal)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+)
+
+// Registry holds one CircuitBreaker per logical downstream endpoint (e.g.
+// "openrouter", "dynamodb:UserContext", "neptune:gremlin"), so a single
+// struggling dependency trips only its own breaker instead of rejecting
+// calls to every other dependency the way one process-wide breaker would.
+type Registry struct {
+	mutex    sync.Mutex
+	config   BreakerConfig
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry creates a registry that lazily creates a breaker with config
+// the first time each endpoint name is used.
+func NewRegistry(config BreakerConfig) *Registry {
+	return &Registry{
+		config:   config,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Get returns the breaker for endpoint, creating one on first use.
+func (r *Registry) Get(endpoint string) *CircuitBreaker {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	breaker, ok := r.breakers[endpoint]
+	if !ok {
+		breaker = NewCircuitBreaker(r.config)
+		r.breakers[endpoint] = breaker
+	}
+	return breaker
+}
+
+// Execute runs fn through the breaker registered for endpoint.
+func (r *Registry) Execute(ctx context.Context, endpoint string, fn func() error) error {
+	return r.Get(endpoint).Execute(ctx, fn)
+}
+
+// Snapshot returns each endpoint's breaker stats, keyed by endpoint name,
+// for Prometheus/CloudWatch EMF exposition.
+func (r *Registry) Snapshot() map[string]BreakerStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	snapshot := make(map[string]BreakerStats, len(r.breakers))
+	for endpoint, breaker := range r.breakers {
+		snapshot[endpoint] = breaker.Stats()
+	}
+	return snapshot
+}
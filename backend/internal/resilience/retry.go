@@ -2,18 +2,42 @@ package resilience
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
+	"strings"
 	"time"
 )
 
+// JitterMode selects how RetryWithBackoff randomizes delay between
+// attempts, to avoid many concurrent Lambda invocations retrying a failed
+// endpoint in lockstep.
+type JitterMode int
+
+const (
+	// JitterNone computes a deterministic delay from InitialDelay and
+	// BackoffMultiplier, with no randomization.
+	JitterNone JitterMode = iota
+	// JitterFull picks uniformly from [0, cappedExponentialDelay), per the
+	// AWS-recommended "full jitter" strategy.
+	JitterFull
+	// JitterDecorrelated picks uniformly from [InitialDelay, prevDelay*3),
+	// per AWS's "decorrelated jitter" strategy - it needs no attempt
+	// counter, so backoff still grows even if attempts are spaced out by
+	// other causes (e.g. a shared rate limit across invocations).
+	JitterDecorrelated
+)
+
 // RetryConfig defines retry behavior
 type RetryConfig struct {
 	MaxAttempts       int           // Maximum number of retry attempts
 	InitialDelay      time.Duration // Initial delay before first retry
 	MaxDelay          time.Duration // Maximum delay between retries
-	BackoffMultiplier float64       // Multiplier for exponential backoff
+	BackoffMultiplier float64       // Multiplier for exponential backoff (JitterNone only)
+	JitterMode        JitterMode    // How to randomize delay between attempts
+	TotalBudget       time.Duration // Overall deadline for every attempt + sleep combined; 0 disables budget enforcement
 }
 
 // DefaultRetryConfig provides sensible defaults for Lambda functions
@@ -23,6 +47,7 @@ func DefaultRetryConfig() RetryConfig {
 		InitialDelay:      1 * time.Second,
 		MaxDelay:          30 * time.Second,
 		BackoffMultiplier: 2.0,
+		JitterMode:        JitterFull,
 	}
 }
 
@@ -32,14 +57,48 @@ type RetryableFunc[T any] func(ctx context.Context, attempt int) (T, error)
 // IsRetryableError determines if an error should trigger a retry
 type IsRetryableError func(error) bool
 
-// RetryWithBackoff executes a function with exponential backoff retry logic
+// ErrBudgetExhausted is returned (wrapped) when config.TotalBudget runs out
+// before MaxAttempts does, rather than MaxAttempts being reached or a
+// non-retryable error occurring.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryWithBackoff executes a function with jittered exponential backoff.
+// When an attempt fails with a *ClassifiedError, its Kind takes precedence
+// over isRetryable (InvalidInput/PermissionDenied/CircuitOpen never retry
+// regardless of what isRetryable would say) and its RetryAfter, if set,
+// overrides the computed backoff delay. isRetryable is still consulted for
+// plain errors, so existing callers built around the stringly-typed
+// predicates keep working unchanged.
+//
+// If config.TotalBudget is non-zero, each attempt runs under a
+// context.WithTimeout derived from the budget remaining (not the caller's
+// ctx deadline, which is left untouched), and RetryWithBackoff returns
+// ErrBudgetExhausted instead of sleeping past it.
 func RetryWithBackoff[T any](ctx context.Context, config RetryConfig, isRetryable IsRetryableError, fn RetryableFunc[T]) (T, error) {
 	var lastErr error
 	var result T
 
+	var deadline time.Time
+	if config.TotalBudget > 0 {
+		deadline = time.Now().Add(config.TotalBudget)
+	}
+
+	prevDelay := config.InitialDelay
+
 	for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
+		attemptCtx := ctx
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return result, fmt.Errorf("%w: no time remaining before attempt %d", ErrBudgetExhausted, attempt)
+			}
+			var cancel context.CancelFunc
+			attemptCtx, cancel = context.WithTimeout(ctx, remaining)
+			defer cancel()
+		}
+
 		// Execute the function
-		result, err := fn(ctx, attempt)
+		result, err := fn(attemptCtx, attempt)
 		if err == nil {
 			// Success!
 			if attempt > 1 {
@@ -50,8 +109,15 @@ func RetryWithBackoff[T any](ctx context.Context, config RetryConfig, isRetryabl
 
 		lastErr = err
 
-		// Check if we should retry this error
-		if !isRetryable(err) {
+		var classified *ClassifiedError
+		var retryAfter time.Duration
+		if errors.As(err, &classified) {
+			if !classified.Retryable() {
+				log.Printf("Non-retryable error (%s) on attempt %d: %v", classified.Kind, attempt, err)
+				return result, fmt.Errorf("non-retryable error: %w", err)
+			}
+			retryAfter = classified.RetryAfter
+		} else if !isRetryable(err) {
 			log.Printf("Non-retryable error on attempt %d: %v", attempt, err)
 			return result, fmt.Errorf("non-retryable error: %w", err)
 		}
@@ -61,10 +127,20 @@ func RetryWithBackoff[T any](ctx context.Context, config RetryConfig, isRetryabl
 			break
 		}
 
-		// Calculate delay with exponential backoff
-		delay := time.Duration(float64(config.InitialDelay) * math.Pow(config.BackoffMultiplier, float64(attempt-1)))
-		if delay > config.MaxDelay {
-			delay = config.MaxDelay
+		delay := nextDelay(config, attempt, prevDelay)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		prevDelay = delay
+
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return result, fmt.Errorf("%w: last error: %v", ErrBudgetExhausted, lastErr)
+			}
+			if delay > remaining {
+				delay = remaining
+			}
 		}
 
 		log.Printf("Attempt %d failed, retrying in %v: %v", attempt, delay, err)
@@ -82,6 +158,43 @@ func RetryWithBackoff[T any](ctx context.Context, config RetryConfig, isRetryabl
 	return result, fmt.Errorf("retry exhausted after %d attempts, last error: %w", config.MaxAttempts, lastErr)
 }
 
+// nextDelay computes the delay before the next attempt per config.JitterMode.
+func nextDelay(config RetryConfig, attempt int, prevDelay time.Duration) time.Duration {
+	cappedExponential := time.Duration(float64(config.InitialDelay) * math.Pow(2, float64(attempt-1)))
+	if cappedExponential > config.MaxDelay {
+		cappedExponential = config.MaxDelay
+	}
+
+	switch config.JitterMode {
+	case JitterFull:
+		if cappedExponential <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(cappedExponential)))
+
+	case JitterDecorrelated:
+		upper := int64(prevDelay)*3 - int64(config.InitialDelay)
+		if upper <= 0 {
+			upper = int64(config.InitialDelay)
+			if upper <= 0 {
+				return 0
+			}
+		}
+		delay := config.InitialDelay + time.Duration(rand.Int63n(upper))
+		if delay > config.MaxDelay {
+			delay = config.MaxDelay
+		}
+		return delay
+
+	default: // JitterNone
+		delay := time.Duration(float64(config.InitialDelay) * math.Pow(config.BackoffMultiplier, float64(attempt-1)))
+		if delay > config.MaxDelay {
+			delay = config.MaxDelay
+		}
+		return delay
+	}
+}
+
 // Common retry predicates
 
 // DefaultRetryableErrors returns true for common transient errors
@@ -151,85 +264,44 @@ func OpenRouterRetryableErrors(err error) bool {
 		contains(errStr, "504") // Gateway Timeout
 }
 
-// contains is a simple string contains helper
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || contains(s[1:], substr))
-}
-
-// CircuitBreaker implements the circuit breaker pattern for failing services
-type CircuitBreaker struct {
-	maxFailures  int
-	resetTimeout time.Duration
-	failureCount int
-	lastFailTime time.Time
-	state        CircuitState
-}
-
-// CircuitState represents the circuit breaker state
-type CircuitState int
-
-const (
-	CircuitClosed   CircuitState = iota // Normal operation
-	CircuitOpen                         // Failing, reject requests
-	CircuitHalfOpen                     // Testing if service recovered
-)
-
-// NewCircuitBreaker creates a new circuit breaker
-func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		maxFailures:  maxFailures,
-		resetTimeout: resetTimeout,
-		state:        CircuitClosed,
-	}
-}
-
-// Execute runs a function through the circuit breaker
-func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
-	// Check if we should attempt to reset the circuit
-	if cb.state == CircuitOpen && time.Since(cb.lastFailTime) > cb.resetTimeout {
-		cb.state = CircuitHalfOpen
-		log.Printf("Circuit breaker transitioning to half-open state")
-	}
-
-	// Reject requests if circuit is open
-	if cb.state == CircuitOpen {
-		return fmt.Errorf("circuit breaker is open, rejecting request")
-	}
-
-	// Execute the function
-	err := fn()
-
-	if err != nil {
-		cb.onFailure()
-		return err
+// NeptuneRetryableErrors checks for Gremlin/Neptune-specific retryable errors
+func NeptuneRetryableErrors(err error) bool {
+	if DefaultRetryableErrors(err) {
+		return true
 	}
 
-	cb.onSuccess()
-	return nil
-}
-
-// onFailure handles function execution failure
-func (cb *CircuitBreaker) onFailure() {
-	cb.failureCount++
-	cb.lastFailTime = time.Now()
-
-	if cb.failureCount >= cb.maxFailures {
-		cb.state = CircuitOpen
-		log.Printf("Circuit breaker opened after %d failures", cb.failureCount)
-	}
+	errStr := err.Error()
+	return contains(errStr, "ConcurrentModificationException") ||
+		contains(errStr, "ReadOnlyViolationException") ||
+		contains(errStr, "no active connection")
 }
 
-// onSuccess handles function execution success
-func (cb *CircuitBreaker) onSuccess() {
-	cb.failureCount = 0
-
-	if cb.state == CircuitHalfOpen {
-		cb.state = CircuitClosed
-		log.Printf("Circuit breaker closed - service recovered")
-	}
+// contains reports whether substr occurs anywhere in s.
+func contains(s, substr string) bool {
+	return strings.Contains(s, substr)
 }
 
-// GetState returns the current circuit breaker state
-func (cb *CircuitBreaker) GetState() CircuitState {
-	return cb.state
+// CircuitBreaker and Registry live in circuit_breaker.go and registry.go.
+// Bulkhead and BulkheadRegistry live in bulkhead.go.
+
+// Wrap composes retry -> circuit breaker -> bulkhead around fn, in that
+// order: retry is outermost, so each attempt re-enters a fresh breaker/
+// bulkhead check instead of retrying inside a single breaker call; bulkhead
+// is innermost, so a rejected slot acquisition is exactly what the breaker
+// (and then retry) see as that attempt's error. A single call like
+// OpenRouter's chat completion can therefore be protected end-to-end with
+// one composition: resilience.Wrap(ctx, breakers.Get("openrouter"),
+// bulkheads.Get("openrouter"), resilience.DefaultRetryConfig(), isRetryable, fn).
+func Wrap[T any](ctx context.Context, cb *CircuitBreaker, bh *Bulkhead, retryConfig RetryConfig, isRetryable IsRetryableError, fn RetryableFunc[T]) (T, error) {
+	return RetryWithBackoff(ctx, retryConfig, isRetryable, func(ctx context.Context, attempt int) (T, error) {
+		var result T
+		err := cb.Execute(ctx, func() error {
+			return bh.Execute(ctx, func() error {
+				var innerErr error
+				result, innerErr = fn(ctx, attempt)
+				return innerErr
+			})
+		})
+		return result, err
+	})
 }
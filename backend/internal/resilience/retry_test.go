@@ -0,0 +1,221 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextDelayJitterFullStaysWithinBounds(t *testing.T) {
+	config := RetryConfig{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		JitterMode:   JitterFull,
+	}
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		capExp := time.Duration(1) << uint(attempt-1) * config.InitialDelay
+		if capExp > config.MaxDelay {
+			capExp = config.MaxDelay
+		}
+		for i := 0; i < 50; i++ {
+			delay := nextDelay(config, attempt, 0)
+			if delay < 0 || delay >= capExp {
+				t.Fatalf("attempt %d: delay %v out of [0, %v)", attempt, delay, capExp)
+			}
+		}
+	}
+}
+
+func TestNextDelayJitterFullCapsAtMaxDelay(t *testing.T) {
+	config := RetryConfig{
+		InitialDelay: time.Second,
+		MaxDelay:     2 * time.Second,
+		JitterMode:   JitterFull,
+	}
+
+	// attempt 10 would be InitialDelay*2^9 uncapped, far past MaxDelay.
+	for i := 0; i < 50; i++ {
+		delay := nextDelay(config, 10, 0)
+		if delay < 0 || delay >= config.MaxDelay {
+			t.Fatalf("expected delay capped below MaxDelay, got %v", delay)
+		}
+	}
+}
+
+func TestNextDelayJitterDecorrelatedStaysWithinBounds(t *testing.T) {
+	config := RetryConfig{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		JitterMode:   JitterDecorrelated,
+	}
+
+	prev := config.InitialDelay
+	for attempt := 1; attempt <= 5; attempt++ {
+		delay := nextDelay(config, attempt, prev)
+		if delay < config.InitialDelay || delay > config.MaxDelay {
+			t.Fatalf("attempt %d: delay %v out of [%v, %v]", attempt, delay, config.InitialDelay, config.MaxDelay)
+		}
+		prev = delay
+	}
+}
+
+func TestNextDelayJitterNoneIsDeterministic(t *testing.T) {
+	config := RetryConfig{
+		InitialDelay:      100 * time.Millisecond,
+		MaxDelay:          time.Second,
+		BackoffMultiplier: 2.0,
+		JitterMode:        JitterNone,
+	}
+
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second}, // capped at MaxDelay
+	}
+
+	for _, tc := range testCases {
+		if got := nextDelay(config, tc.attempt, 0); got != tc.expected {
+			t.Errorf("attempt %d: expected %v, got %v", tc.attempt, tc.expected, got)
+		}
+	}
+}
+
+func TestRetryWithBackoffSucceedsAfterRetries(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		JitterMode:   JitterNone,
+	}
+
+	attempts := 0
+	result, err := RetryWithBackoff(context.Background(), config, DefaultRetryableErrors, func(ctx context.Context, attempt int) (string, error) {
+		attempts++
+		if attempt < 3 {
+			return "", errors.New("timeout talking to downstream")
+		}
+		return "ok", nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected eventual success, got error: %v", err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected result 'ok', got %q", result)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected exactly 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryWithBackoffStopsOnNonRetryableClassifiedError(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:  5,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     10 * time.Millisecond,
+		JitterMode:   JitterNone,
+	}
+
+	attempts := 0
+	_, err := RetryWithBackoff(context.Background(), config, DefaultRetryableErrors, func(ctx context.Context, attempt int) (string, error) {
+		attempts++
+		return "", &ClassifiedError{Kind: KindInvalidInput, Cause: errors.New("bad request")}
+	})
+
+	if err == nil {
+		t.Fatal("expected a non-retryable error to be returned")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retryable error, got %d", attempts)
+	}
+}
+
+func TestRetryWithBackoffHonorsClassifiedRetryAfter(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Second,
+		JitterMode:   JitterNone,
+	}
+
+	start := time.Now()
+	_, err := RetryWithBackoff(context.Background(), config, DefaultRetryableErrors, func(ctx context.Context, attempt int) (string, error) {
+		if attempt == 1 {
+			return "", &ClassifiedError{Kind: KindThrottled, RetryAfter: 30 * time.Millisecond, Cause: errors.New("throttled")}
+		}
+		return "ok", nil
+	})
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if elapsed < 30*time.Millisecond {
+		t.Fatalf("expected RetryAfter to override the tiny computed backoff, waited only %v", elapsed)
+	}
+}
+
+func TestRetryWithBackoffBudgetExhausted(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:  10,
+		InitialDelay: 50 * time.Millisecond,
+		MaxDelay:     time.Second,
+		JitterMode:   JitterNone,
+		TotalBudget:  10 * time.Millisecond,
+	}
+
+	_, err := RetryWithBackoff(context.Background(), config, DefaultRetryableErrors, func(ctx context.Context, attempt int) (string, error) {
+		return "", errors.New("timeout")
+	})
+
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted once TotalBudget ran out before MaxAttempts, got %v", err)
+	}
+}
+
+func TestRetryWithBackoffExhaustsAllAttempts(t *testing.T) {
+	config := RetryConfig{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+		JitterMode:   JitterNone,
+	}
+
+	attempts := 0
+	_, err := RetryWithBackoff(context.Background(), config, DefaultRetryableErrors, func(ctx context.Context, attempt int) (string, error) {
+		attempts++
+		return "", errors.New("connection timeout")
+	})
+
+	if err == nil {
+		t.Fatal("expected an error once all attempts are exhausted")
+	}
+	if attempts != config.MaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", config.MaxAttempts, attempts)
+	}
+}
+
+func TestDefaultRetryableErrorsMatching(t *testing.T) {
+	testCases := []struct {
+		err       error
+		retryable bool
+	}{
+		{errors.New("connection refused"), true},
+		{errors.New("ThrottlingException: slow down"), true},
+		{errors.New("ValidationException: bad field"), false},
+		{nil, false},
+	}
+
+	for _, tc := range testCases {
+		if got := DefaultRetryableErrors(tc.err); got != tc.retryable {
+			t.Errorf("err %v: expected retryable=%v, got %v", tc.err, tc.retryable, got)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package retrieval
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// bm25K1 and bm25B are Okapi BM25's standard term-frequency-saturation and
+// length-normalization constants, per the request that introduced this
+// scorer.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// tokenPattern splits text into lowercase word tokens, stripping punctuation.
+var tokenPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+func tokenize(text string) []string {
+	return tokenPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// scoreBM25 ranks each of docs against query using Okapi BM25. idf is
+// computed lazily from docs itself - there's no corpus-wide term index, so
+// "idf" here means "how rare is this term across this user's own candidate
+// nodes", which is all the scope BM25 needs to rank within a single
+// retrieval call.
+func scoreBM25(query string, docs []string) []float64 {
+	queryTerms := tokenize(query)
+	docTermFreq := make([]map[string]int, len(docs))
+	docFreq := make(map[string]int)
+	totalTokens := 0
+
+	for i, doc := range docs {
+		tokens := tokenize(doc)
+		totalTokens += len(tokens)
+
+		termFreq := make(map[string]int, len(tokens))
+		for _, tok := range tokens {
+			termFreq[tok]++
+		}
+		docTermFreq[i] = termFreq
+
+		for tok := range termFreq {
+			docFreq[tok]++
+		}
+	}
+
+	n := len(docs)
+	scores := make([]float64, n)
+	if n == 0 || len(queryTerms) == 0 {
+		return scores
+	}
+	avgDocLen := float64(totalTokens) / float64(n)
+
+	for i, termFreq := range docTermFreq {
+		docLen := 0
+		for _, count := range termFreq {
+			docLen += count
+		}
+
+		var score float64
+		for _, term := range queryTerms {
+			freq := termFreq[term]
+			if freq == 0 {
+				continue
+			}
+			idf := math.Log(1 + (float64(n)-float64(docFreq[term])+0.5)/(float64(docFreq[term])+0.5))
+			numerator := float64(freq) * (bm25K1 + 1)
+			denominator := float64(freq) + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgDocLen)
+			score += idf * numerator / denominator
+		}
+		scores[i] = score
+	}
+
+	return scores
+}
@@ -0,0 +1,30 @@
+package retrieval
+
+import "testing"
+
+func TestScoreBM25RanksMatchingDocHigher(t *testing.T) {
+	docs := []string{
+		"Feeling anxious about the upcoming math exam",
+		"Enjoyed a quiet walk in the park with friends",
+	}
+	scores := scoreBM25("anxious about exam", docs)
+
+	if scores[0] <= scores[1] {
+		t.Errorf("Expected the matching doc to outscore the unrelated one, got %v", scores)
+	}
+}
+
+func TestScoreBM25NoQueryTerms(t *testing.T) {
+	scores := scoreBM25("", []string{"some content", "more content"})
+	for i, s := range scores {
+		if s != 0 {
+			t.Errorf("Expected zero score for an empty query, doc %d got %f", i, s)
+		}
+	}
+}
+
+func TestScoreBM25EmptyCorpus(t *testing.T) {
+	if scores := scoreBM25("anything", nil); len(scores) != 0 {
+		t.Errorf("Expected no scores for an empty corpus, got %v", scores)
+	}
+}
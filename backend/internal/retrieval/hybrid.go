@@ -0,0 +1,155 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/graph"
+)
+
+const (
+	// defaultCandidatePoolSize bounds how many of a user's nodes are pulled
+	// back for re-scoring. NeptuneClient has no "list all nodes" call, so
+	// this stands in for one: QuerySimilarNodes with a generous k returns
+	// effectively the user's whole node set, just already vector-ranked.
+	defaultCandidatePoolSize = 200
+
+	// defaultFusedSeeds is how many of the RRF-fused top results get
+	// expanded one hop through the graph.
+	defaultFusedSeeds = 5
+
+	// defaultNeighborsPerSeed bounds how many one-hop neighbors each seed
+	// contributes, so one highly-connected node can't crowd out the other
+	// seeds' context.
+	defaultNeighborsPerSeed = 3
+)
+
+// HybridRetriever implements Strategy by fusing two independent rankings of
+// a user's graph nodes - dense vector similarity and sparse BM25 keyword
+// match - with Reciprocal Rank Fusion, then expanding the fused top results
+// one hop through the graph so the context block isn't limited to nodes
+// that happen to embed or tokenize well on their own.
+type HybridRetriever struct {
+	embeddings    embeddings.Provider
+	neptuneClient graph.NeptuneClient
+
+	candidatePoolSize int
+	fusedSeeds        int
+	neighborsPerSeed  int
+}
+
+var _ Strategy = (*HybridRetriever)(nil)
+
+// NewHybridRetriever creates a retriever with the package's default pool
+// size and expansion bounds; use the Set* methods to override them.
+func NewHybridRetriever(embeddingsClient embeddings.Provider, neptuneClient graph.NeptuneClient) *HybridRetriever {
+	return &HybridRetriever{
+		embeddings:        embeddingsClient,
+		neptuneClient:     neptuneClient,
+		candidatePoolSize: defaultCandidatePoolSize,
+		fusedSeeds:        defaultFusedSeeds,
+		neighborsPerSeed:  defaultNeighborsPerSeed,
+	}
+}
+
+// SetCandidatePoolSize overrides how many vector-ranked nodes are pulled
+// back for BM25 re-scoring.
+func (r *HybridRetriever) SetCandidatePoolSize(size int) {
+	r.candidatePoolSize = size
+}
+
+// SetFusedSeeds overrides how many top fused results are expanded through
+// the graph.
+func (r *HybridRetriever) SetFusedSeeds(n int) {
+	r.fusedSeeds = n
+}
+
+// SetNeighborsPerSeed overrides how many one-hop neighbors each seed
+// contributes.
+func (r *HybridRetriever) SetNeighborsPerSeed(n int) {
+	r.neighborsPerSeed = n
+}
+
+// Retrieve embeds query, ranks userID's nodes by vector similarity and by
+// BM25 keyword match over the same candidate set, fuses the two rankings
+// with Reciprocal Rank Fusion, and expands the top-ranked seeds one hop
+// through the graph. Returned snippets are deduplicated and ordered
+// most-relevant-first.
+func (r *HybridRetriever) Retrieve(ctx context.Context, userID, query string) ([]string, error) {
+	embedded, err := r.embeddings.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query for hybrid retrieval: %w", err)
+	}
+
+	// QuerySimilarNodes already ranks userID's whole node set (bounded by
+	// candidatePoolSize) by vector similarity, so its output doubles as
+	// the BM25 candidate pool - the "same nodes" both rankings score.
+	nodes, err := r.neptuneClient.QuerySimilarNodes(ctx, userID, embedded.Embedding, r.candidatePoolSize, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar nodes for hybrid retrieval: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	vectorRanking := make([]int, len(nodes))
+	contents := make([]string, len(nodes))
+	for i, node := range nodes {
+		vectorRanking[i] = i
+		contents[i] = node.Content
+	}
+
+	bm25Scores := scoreBM25(query, contents)
+	bm25Ranking := make([]int, len(nodes))
+	for i := range bm25Ranking {
+		bm25Ranking[i] = i
+	}
+	sort.Slice(bm25Ranking, func(i, j int) bool {
+		return bm25Scores[bm25Ranking[i]] > bm25Scores[bm25Ranking[j]]
+	})
+
+	fused := fuseRankings(len(nodes), vectorRanking, bm25Ranking)
+	order := make([]int, len(nodes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return fused[order[i]] > fused[order[j]]
+	})
+
+	seedCount := r.fusedSeeds
+	if seedCount > len(order) {
+		seedCount = len(order)
+	}
+
+	seen := make(map[string]bool, len(nodes))
+	snippets := make([]string, 0, seedCount*(1+r.neighborsPerSeed))
+	for _, idx := range order[:seedCount] {
+		seed := nodes[idx]
+		if seed.Content != "" && !seen[seed.Content] {
+			seen[seed.Content] = true
+			snippets = append(snippets, seed.Content)
+		}
+
+		if seed.ID == "" {
+			continue
+		}
+		neighbors, err := r.neptuneClient.GetNeighbors(ctx, userID, seed.ID, r.neighborsPerSeed)
+		if err != nil {
+			log.Printf("hybrid retrieval: failed to expand neighbors of node %s: %v", seed.ID, err)
+			continue
+		}
+		for _, neighbor := range neighbors {
+			if neighbor.Content == "" || seen[neighbor.Content] {
+				continue
+			}
+			seen[neighbor.Content] = true
+			snippets = append(snippets, neighbor.Content)
+		}
+	}
+
+	return snippets, nil
+}
@@ -0,0 +1,122 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/graph"
+)
+
+// fakeEmbedder is a minimal embeddings.Provider returning a fixed vector per
+// known text, so vector similarity in these tests is deterministic instead
+// of depending on a real embeddings backend.
+type fakeEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (f *fakeEmbedder) GenerateEmbedding(ctx context.Context, text string) (*embeddings.EmbeddingResult, error) {
+	vec, ok := f.vectors[text]
+	if !ok {
+		vec = []float32{0, 0, 0}
+	}
+	return &embeddings.EmbeddingResult{Text: text, Embedding: vec}, nil
+}
+
+func (f *fakeEmbedder) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*embeddings.EmbeddingResult, error) {
+	results := make([]*embeddings.EmbeddingResult, len(texts))
+	for i, text := range texts {
+		result, err := f.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (f *fakeEmbedder) Dimension() int { return 3 }
+func (f *fakeEmbedder) Model() string  { return "fake-embedder" }
+
+func TestHybridRetrieverFusesAndExpandsNeighbors(t *testing.T) {
+	vectors := map[string][]float32{
+		"anxious exam":                   {1, 0, 0},
+		"feeling anxious about the exam": {1, 0, 0},
+		"quiet walk in the park":         {0, 1, 0},
+		"calming breathing exercise":     {0, 0, 1},
+	}
+	embedder := &fakeEmbedder{vectors: vectors}
+	neptune := graph.NewMockNeptuneClientWithEmbedder(embedder)
+
+	ctx := context.Background()
+	const userID = "user-1"
+
+	for _, content := range []string{
+		"feeling anxious about the exam",
+		"quiet walk in the park",
+		"calming breathing exercise",
+	} {
+		if err := neptune.CreateNode(ctx, userID, "Reflection", content); err != nil {
+			t.Fatalf("CreateNode(%q) failed: %v", content, err)
+		}
+	}
+
+	// Resolve the node IDs CreateNode assigned, then connect the seed
+	// doc to the neighbor doc so graph expansion has an edge to follow.
+	nodes, err := neptune.QuerySimilarNodes(ctx, userID, vectors["anxious exam"], 10, nil)
+	if err != nil {
+		t.Fatalf("QuerySimilarNodes failed: %v", err)
+	}
+	var seedID, neighborID string
+	for _, node := range nodes {
+		switch node.Content {
+		case "feeling anxious about the exam":
+			seedID = node.ID
+		case "calming breathing exercise":
+			neighborID = node.ID
+		}
+	}
+	if seedID == "" || neighborID == "" {
+		t.Fatalf("failed to resolve node IDs from %+v", nodes)
+	}
+	if err := neptune.CreateEdge(userID, seedID, "related_to", neighborID); err != nil {
+		t.Fatalf("CreateEdge failed: %v", err)
+	}
+
+	retriever := NewHybridRetriever(embedder, neptune)
+	retriever.SetFusedSeeds(1) // only the top fused result should be expanded
+
+	snippets, err := retriever.Retrieve(ctx, userID, "anxious exam")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+
+	if len(snippets) != 2 {
+		t.Fatalf("Expected the top seed plus its one-hop neighbor, got %v", snippets)
+	}
+	if snippets[0] != "feeling anxious about the exam" {
+		t.Errorf("Expected the vector+BM25-matching doc first, got %q", snippets[0])
+	}
+	if snippets[1] != "calming breathing exercise" {
+		t.Errorf("Expected the expanded neighbor included, got %q", snippets[1])
+	}
+	for _, s := range snippets {
+		if s == "quiet walk in the park" {
+			t.Errorf("Expected the unrelated, unconnected doc to be excluded, got %v", snippets)
+		}
+	}
+}
+
+func TestHybridRetrieverNoNodes(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float32{}}
+	neptune := graph.NewMockNeptuneClientWithEmbedder(embedder)
+	retriever := NewHybridRetriever(embedder, neptune)
+
+	snippets, err := retriever.Retrieve(context.Background(), "user-with-no-history", "anything")
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if len(snippets) != 0 {
+		t.Errorf("Expected no snippets for a user with no nodes, got %v", snippets)
+	}
+}
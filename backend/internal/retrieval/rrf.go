@@ -0,0 +1,23 @@
+package retrieval
+
+// rrfK is Reciprocal Rank Fusion's smoothing constant, per the original RRF
+// paper's default - large enough that two rankings placing an item near the
+// top agree more strongly than a single ranking placing it first.
+const rrfK = 60
+
+// fuseRankings combines one or more rankings of the same nItems candidates
+// (each ranking a permutation of indices 0..nItems-1, most-relevant-first)
+// into one score per item: score = sum over rankings of 1/(rrfK+rank+1).
+// An item missing from a ranking simply contributes nothing from it.
+func fuseRankings(nItems int, rankings ...[]int) []float64 {
+	scores := make([]float64, nItems)
+	for _, ranking := range rankings {
+		for rank, idx := range ranking {
+			if idx < 0 || idx >= nItems {
+				continue
+			}
+			scores[idx] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,25 @@
+package retrieval
+
+import "testing"
+
+func TestFuseRankingsAgreementWins(t *testing.T) {
+	// item 2 ranks first in both lists; item 0 ranks first in only one.
+	vectorRanking := []int{2, 0, 1}
+	bm25Ranking := []int{2, 1, 0}
+
+	scores := fuseRankings(3, vectorRanking, bm25Ranking)
+
+	if scores[2] <= scores[0] || scores[2] <= scores[1] {
+		t.Errorf("Expected the item ranked first by both lists to score highest, got %v", scores)
+	}
+}
+
+func TestFuseRankingsIgnoresOutOfRangeIndices(t *testing.T) {
+	scores := fuseRankings(2, []int{0, 5, 1})
+	if len(scores) != 2 {
+		t.Fatalf("Expected 2 scores, got %d", len(scores))
+	}
+	if scores[0] <= scores[1] {
+		t.Errorf("Expected item 0 (ranked first) to outscore item 1, got %v", scores)
+	}
+}
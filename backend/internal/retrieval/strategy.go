@@ -0,0 +1,15 @@
+// Package retrieval builds the GraphRAG context block injected into a
+// persona's system prompt: given a user's message, it finds the prior
+// graph nodes (reflections, themes, events) most relevant to answering it.
+package retrieval
+
+import "context"
+
+// Strategy retrieves context snippets relevant to userID's query, ordered
+// most-relevant-first. workflow.ContextRetriever (vector-only) and
+// HybridRetriever (vector + BM25 + graph expansion) both satisfy it, so a
+// caller can pick per-persona which tactic to use without depending on a
+// concrete type.
+type Strategy interface {
+	Retrieve(ctx context.Context, userID, query string) ([]string, error)
+}
@@ -0,0 +1,31 @@
+package safety
+
+import "context"
+
+// ChainedModerator runs a list of SafetyModerators in order and fails
+// closed: the first moderator that returns a blocking verdict (or an error)
+// wins, so adding a stricter layer can only make moderation more
+// conservative, never less.
+type ChainedModerator struct {
+	moderators []SafetyModerator
+}
+
+// NewChainedModerator chains moderators in the order given.
+func NewChainedModerator(moderators ...SafetyModerator) *ChainedModerator {
+	return &ChainedModerator{moderators: moderators}
+}
+
+// Evaluate runs each moderator in turn, stopping at the first block.
+func (c *ChainedModerator) Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error) {
+	for _, moderator := range c.moderators {
+		verdict, err := moderator.Evaluate(ctx, text, direction)
+		if err != nil {
+			return SafetyVerdict{}, err
+		}
+		if !verdict.Allowed {
+			return verdict, nil
+		}
+	}
+
+	return allowedVerdict(), nil
+}
@@ -0,0 +1,88 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubModerator returns a fixed verdict/error regardless of input, and
+// records whether Evaluate was called, so tests can assert a ChainedModerator
+// short-circuits instead of running every moderator.
+type stubModerator struct {
+	verdict SafetyVerdict
+	err     error
+	called  bool
+}
+
+func (s *stubModerator) Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error) {
+	s.called = true
+	return s.verdict, s.err
+}
+
+func TestChainedModeratorAllowsWhenEveryModeratorAllows(t *testing.T) {
+	a := &stubModerator{verdict: allowedVerdict()}
+	b := &stubModerator{verdict: allowedVerdict()}
+	chain := NewChainedModerator(a, b)
+
+	verdict, err := chain.Evaluate(context.Background(), "a perfectly fine message", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Allowed {
+		t.Fatal("expected the chain to allow when every moderator allows")
+	}
+	if !a.called || !b.called {
+		t.Fatal("expected every moderator to run when none of them block")
+	}
+}
+
+func TestChainedModeratorStopsAtFirstBlock(t *testing.T) {
+	blocked := SafetyVerdict{Allowed: false, Category: CategoryCrisis, Action: ActionCrisisResource, Reason: "keyword match"}
+	a := &stubModerator{verdict: blocked}
+	b := &stubModerator{verdict: allowedVerdict()}
+	chain := NewChainedModerator(a, b)
+
+	verdict, err := chain.Evaluate(context.Background(), "concerning text", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Allowed {
+		t.Fatal("expected the chain to block once the first moderator blocks")
+	}
+	if verdict.Reason != "keyword match" {
+		t.Errorf("expected the blocking moderator's verdict to be returned, got %+v", verdict)
+	}
+	if b.called {
+		t.Fatal("expected a later moderator to be short-circuited once an earlier one blocks")
+	}
+}
+
+func TestChainedModeratorFailsClosedOnModeratorError(t *testing.T) {
+	a := &stubModerator{err: errors.New("moderation API unreachable")}
+	b := &stubModerator{verdict: allowedVerdict()}
+	chain := NewChainedModerator(a, b)
+
+	verdict, err := chain.Evaluate(context.Background(), "some message", DirectionInbound)
+	if err == nil {
+		t.Fatal("expected an error from a failing moderator to propagate rather than be swallowed")
+	}
+	if verdict.Allowed {
+		t.Fatal("expected a moderator error to never produce an Allowed verdict")
+	}
+	if b.called {
+		t.Fatal("expected the chain to fail closed immediately on an error, without consulting later moderators")
+	}
+}
+
+func TestChainedModeratorEmptyChainAllows(t *testing.T) {
+	chain := NewChainedModerator()
+
+	verdict, err := chain.Evaluate(context.Background(), "anything", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Allowed {
+		t.Fatal("expected an empty chain to allow by default")
+	}
+}
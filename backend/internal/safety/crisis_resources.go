@@ -0,0 +1,29 @@
+package safety
+
+import "strings"
+
+// defaultRegion is used when a request doesn't specify one, or specifies one
+// we don't have a dedicated resource for.
+const defaultRegion = "US"
+
+// CrisisResources maps a region code to the crisis support line surfaced
+// when a moderator recommends ActionCrisisResource, so the message isn't a
+// single hardcoded US number.
+var CrisisResources = map[string]string{
+	"US": "calling 988 (Suicide & Crisis Lifeline)",
+	"CA": "calling or texting 988 (Suicide Crisis Helpline)",
+	"UK": "calling Samaritans free on 116 123",
+	"AU": "calling Lifeline on 13 11 14",
+}
+
+// CrisisResourceMessage returns a supportive message naming the crisis
+// resource for region, falling back to the US resource if region is empty
+// or unrecognized.
+func CrisisResourceMessage(region string) string {
+	resource, ok := CrisisResources[strings.ToUpper(region)]
+	if !ok {
+		resource = CrisisResources[defaultRegion]
+	}
+
+	return "Please consider talking to a trusted adult or " + resource + " if you need immediate support."
+}
@@ -0,0 +1,30 @@
+package safety
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrisisResourceMessageKnownRegion(t *testing.T) {
+	msg := CrisisResourceMessage("UK")
+	if !strings.Contains(msg, "Samaritans") {
+		t.Errorf("expected the UK resource message to mention Samaritans, got %q", msg)
+	}
+}
+
+func TestCrisisResourceMessageIsCaseInsensitive(t *testing.T) {
+	msg := CrisisResourceMessage("au")
+	if !strings.Contains(msg, "Lifeline") {
+		t.Errorf("expected a lowercase region code to still resolve, got %q", msg)
+	}
+}
+
+func TestCrisisResourceMessageFallsBackToUS(t *testing.T) {
+	testCases := []string{"", "ZZ", "not-a-region"}
+	for _, region := range testCases {
+		msg := CrisisResourceMessage(region)
+		if !strings.Contains(msg, "988") {
+			t.Errorf("region %q: expected fallback to the US 988 resource, got %q", region, msg)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+
+	"innerworld-backend/internal/embeddings"
+)
+
+// ReferencePhrase is a curated example used to detect semantically similar
+// crisis/self-harm content that keyword matching would miss.
+type ReferencePhrase struct {
+	Text     string
+	Category Category
+}
+
+// DefaultReferencePhrases seeds the embeddings moderator with a small set of
+// canonical crisis/self-harm statements; callers can pass their own list to
+// NewEmbeddingsModerator instead.
+var DefaultReferencePhrases = []ReferencePhrase{
+	{Text: "I want to kill myself", Category: CategorySelfHarm},
+	{Text: "I don't want to be alive anymore", Category: CategorySelfHarm},
+	{Text: "I want to cut myself", Category: CategorySelfHarm},
+	{Text: "I'm going to hurt someone", Category: CategoryViolence},
+	{Text: "I can't take this anymore, I want to end it", Category: CategoryCrisis},
+}
+
+// EmbeddingsModerator flags text that's semantically close to a curated set
+// of crisis/self-harm reference phrases, catching paraphrases the keyword
+// matcher misses. Reference embeddings are computed once, lazily, and cached.
+type EmbeddingsModerator struct {
+	client     embeddings.Provider
+	references []ReferencePhrase
+	threshold  float32
+
+	referenceEmbeddings []*embeddings.EmbeddingResult
+}
+
+// NewEmbeddingsModerator creates a moderator that flags text scoring above
+// threshold cosine similarity against any reference phrase. A nil references
+// slice falls back to DefaultReferencePhrases. client may be any
+// embeddings.Provider - the OpenAI-backed one or a local/offline one,
+// whichever config.EmbeddingsConfig selects.
+func NewEmbeddingsModerator(client embeddings.Provider, references []ReferencePhrase, threshold float32) *EmbeddingsModerator {
+	if references == nil {
+		references = DefaultReferencePhrases
+	}
+	return &EmbeddingsModerator{
+		client:     client,
+		references: references,
+		threshold:  threshold,
+	}
+}
+
+// Evaluate embeds text and flags it if it's closer than the configured
+// threshold to any reference phrase.
+func (e *EmbeddingsModerator) Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error) {
+	if err := e.ensureReferenceEmbeddings(ctx); err != nil {
+		return SafetyVerdict{}, err
+	}
+
+	query, err := e.client.GenerateEmbedding(ctx, text)
+	if err != nil {
+		return SafetyVerdict{}, fmt.Errorf("failed to embed text for moderation: %w", err)
+	}
+
+	best, score := embeddings.FindMostSimilar(query.Embedding, e.referenceEmbeddings)
+	if best == nil || score < e.threshold {
+		return allowedVerdict(), nil
+	}
+
+	category := e.categoryFor(best.Text)
+	return SafetyVerdict{
+		Allowed:    false,
+		Category:   category,
+		Confidence: float64(score),
+		Action:     actionForCategory(category),
+		Reason:     fmt.Sprintf("similar to reference phrase %q (score %.2f)", best.Text, score),
+	}, nil
+}
+
+func (e *EmbeddingsModerator) ensureReferenceEmbeddings(ctx context.Context) error {
+	if e.referenceEmbeddings != nil {
+		return nil
+	}
+
+	texts := make([]string, len(e.references))
+	for i, ref := range e.references {
+		texts[i] = ref.Text
+	}
+
+	results, err := e.client.GenerateBatchEmbeddings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed reference phrases: %w", err)
+	}
+
+	e.referenceEmbeddings = results
+	return nil
+}
+
+func (e *EmbeddingsModerator) categoryFor(text string) Category {
+	for _, ref := range e.references {
+		if ref.Text == text {
+			return ref.Category
+		}
+	}
+	return CategoryCrisis
+}
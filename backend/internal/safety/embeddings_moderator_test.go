@@ -0,0 +1,108 @@
+package safety
+
+import (
+	"context"
+	"testing"
+
+	"innerworld-backend/internal/embeddings"
+)
+
+// fakeEmbedder is a minimal embeddings.Provider returning a fixed vector per
+// known text, so cosine similarity in these tests is deterministic instead
+// of depending on a real embeddings backend.
+type fakeEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (f *fakeEmbedder) GenerateEmbedding(ctx context.Context, text string) (*embeddings.EmbeddingResult, error) {
+	vec, ok := f.vectors[text]
+	if !ok {
+		vec = []float32{0, 0, 1}
+	}
+	return &embeddings.EmbeddingResult{Text: text, Embedding: vec}, nil
+}
+
+func (f *fakeEmbedder) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*embeddings.EmbeddingResult, error) {
+	results := make([]*embeddings.EmbeddingResult, len(texts))
+	for i, text := range texts {
+		result, err := f.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (f *fakeEmbedder) Dimension() int { return 3 }
+func (f *fakeEmbedder) Model() string  { return "fake-embedder" }
+
+func TestEmbeddingsModeratorFlagsSimilarPhrase(t *testing.T) {
+	reference := ReferencePhrase{Text: "I want to kill myself", Category: CategorySelfHarm}
+	paraphrase := "I don't want to be here anymore"
+
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		reference.Text: {1, 0, 0},
+		paraphrase:     {1, 0, 0.01},
+	}}
+	mod := NewEmbeddingsModerator(embedder, []ReferencePhrase{reference}, 0.9)
+
+	verdict, err := mod.Evaluate(context.Background(), paraphrase, DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Allowed {
+		t.Fatal("expected a close paraphrase to be flagged")
+	}
+	if verdict.Category != CategorySelfHarm {
+		t.Errorf("expected the matched reference's category to carry through, got %v", verdict.Category)
+	}
+}
+
+func TestEmbeddingsModeratorAllowsBelowThreshold(t *testing.T) {
+	reference := ReferencePhrase{Text: "I want to kill myself", Category: CategorySelfHarm}
+	unrelated := "what's a good recipe for banana bread"
+
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		reference.Text: {1, 0, 0},
+		unrelated:      {0, 1, 0},
+	}}
+	mod := NewEmbeddingsModerator(embedder, []ReferencePhrase{reference}, 0.9)
+
+	verdict, err := mod.Evaluate(context.Background(), unrelated, DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Allowed {
+		t.Fatalf("expected an unrelated message below threshold to be allowed, got %+v", verdict)
+	}
+}
+
+func TestEmbeddingsModeratorCachesReferenceEmbeddings(t *testing.T) {
+	reference := ReferencePhrase{Text: "I want to kill myself", Category: CategorySelfHarm}
+	embedder := &fakeEmbedder{vectors: map[string][]float32{reference.Text: {1, 0, 0}}}
+	mod := NewEmbeddingsModerator(embedder, []ReferencePhrase{reference}, 0.9)
+
+	ctx := context.Background()
+	if _, err := mod.Evaluate(ctx, "some message", DirectionInbound); err != nil {
+		t.Fatalf("unexpected error on first call: %v", err)
+	}
+	firstEmbeddings := mod.referenceEmbeddings
+
+	if _, err := mod.Evaluate(ctx, "another message", DirectionInbound); err != nil {
+		t.Fatalf("unexpected error on second call: %v", err)
+	}
+
+	if &mod.referenceEmbeddings[0] != &firstEmbeddings[0] {
+		t.Fatal("expected reference embeddings to be computed once and reused across calls")
+	}
+}
+
+func TestEmbeddingsModeratorDefaultsReferencesWhenNil(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float32{}}
+	mod := NewEmbeddingsModerator(embedder, nil, 0.9)
+
+	if len(mod.references) != len(DefaultReferencePhrases) {
+		t.Fatalf("expected a nil references slice to fall back to DefaultReferencePhrases, got %d entries", len(mod.references))
+	}
+}
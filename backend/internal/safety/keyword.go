@@ -0,0 +1,51 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// KeywordModerator is a fast, zero-dependency moderator that flags messages
+// containing a curated list of crisis/self-harm phrases. It makes no network
+// call, which makes it the cheapest of this package's moderators;
+// ConversationChain's default ChainedModerator holds it alone.
+type KeywordModerator struct {
+	keywords []string
+}
+
+// NewKeywordModerator creates a keyword moderator with the default crisis
+// phrase list.
+func NewKeywordModerator() *KeywordModerator {
+	return &KeywordModerator{
+		keywords: []string{
+			"kill myself", "end it all", "hurt myself", "self harm",
+			"suicide", "die", "cutting", "overdose",
+		},
+	}
+}
+
+// Evaluate flags text containing any configured keyword as a crisis concern.
+func (k *KeywordModerator) Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error) {
+	if strings.TrimSpace(text) == "" {
+		return SafetyVerdict{}, fmt.Errorf("empty message")
+	}
+	if len(text) > 2000 {
+		return SafetyVerdict{}, fmt.Errorf("message too long")
+	}
+
+	lower := strings.ToLower(text)
+	for _, keyword := range k.keywords {
+		if strings.Contains(lower, keyword) {
+			return SafetyVerdict{
+				Allowed:    false,
+				Category:   CategoryCrisis,
+				Confidence: 1.0,
+				Action:     ActionCrisisResource,
+				Reason:     fmt.Sprintf("matched keyword %q", keyword),
+			}, nil
+		}
+	}
+
+	return allowedVerdict(), nil
+}
@@ -0,0 +1,69 @@
+package safety
+
+import (
+	"context"
+	"testing"
+)
+
+func TestKeywordModeratorFlagsCrisisPhrase(t *testing.T) {
+	mod := NewKeywordModerator()
+
+	verdict, err := mod.Evaluate(context.Background(), "I want to kill myself", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Allowed {
+		t.Fatal("expected a crisis phrase to be blocked")
+	}
+	if verdict.Category != CategoryCrisis {
+		t.Errorf("expected CategoryCrisis, got %v", verdict.Category)
+	}
+	if verdict.Action != ActionCrisisResource {
+		t.Errorf("expected ActionCrisisResource, got %v", verdict.Action)
+	}
+}
+
+func TestKeywordModeratorMatchIsCaseInsensitive(t *testing.T) {
+	mod := NewKeywordModerator()
+
+	verdict, err := mod.Evaluate(context.Background(), "I keep thinking about SUICIDE lately", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Allowed {
+		t.Fatal("expected an uppercase keyword match to still be flagged")
+	}
+}
+
+func TestKeywordModeratorAllowsBenignMessage(t *testing.T) {
+	mod := NewKeywordModerator()
+
+	verdict, err := mod.Evaluate(context.Background(), "I had a pretty good day at school today", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Allowed {
+		t.Fatalf("expected a benign message to be allowed, got %+v", verdict)
+	}
+}
+
+func TestKeywordModeratorRejectsEmptyMessage(t *testing.T) {
+	mod := NewKeywordModerator()
+
+	if _, err := mod.Evaluate(context.Background(), "   ", DirectionInbound); err == nil {
+		t.Fatal("expected an error for an empty/whitespace-only message")
+	}
+}
+
+func TestKeywordModeratorRejectsOverlongMessage(t *testing.T) {
+	mod := NewKeywordModerator()
+
+	huge := make([]byte, 2001)
+	for i := range huge {
+		huge[i] = 'a'
+	}
+
+	if _, err := mod.Evaluate(context.Background(), string(huge), DirectionInbound); err == nil {
+		t.Fatal("expected an error for a message over the length cap")
+	}
+}
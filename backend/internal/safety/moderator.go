@@ -0,0 +1,64 @@
+// Package safety provides pluggable content moderation for conversations:
+// a SafetyModerator interface with keyword, HTTP moderation API, and
+// embeddings-similarity implementations that can be chained together.
+package safety
+
+import "context"
+
+// Direction indicates whether the text being evaluated came from the user
+// (inbound) or is about to be sent back by the AI (outbound).
+type Direction string
+
+const (
+	DirectionInbound  Direction = "inbound"
+	DirectionOutbound Direction = "outbound"
+)
+
+// Category classifies the kind of safety concern a moderator detected.
+type Category string
+
+const (
+	CategoryNone     Category = "none"
+	CategorySelfHarm Category = "self_harm"
+	CategoryViolence Category = "violence"
+	CategorySexual   Category = "sexual"
+	CategoryCrisis   Category = "crisis"
+)
+
+// Action is the recommended response to a blocked SafetyVerdict.
+type Action string
+
+const (
+	ActionAllow          Action = "allow"
+	ActionSoftRedirect   Action = "soft_redirect"
+	ActionCrisisResource Action = "crisis_resource"
+)
+
+// SafetyVerdict is the outcome of running a SafetyModerator over a message.
+type SafetyVerdict struct {
+	Allowed    bool
+	Category   Category
+	Confidence float64
+	Action     Action
+	Reason     string
+}
+
+// SafetyModerator evaluates a message for safety concerns. Implementations
+// should fail closed: when they can't tell, they should block rather than
+// allow, since ChainedModerator treats an error the same as a block.
+type SafetyModerator interface {
+	Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error)
+}
+
+// allowedVerdict is the shared "nothing concerning found" result.
+func allowedVerdict() SafetyVerdict {
+	return SafetyVerdict{Allowed: true, Category: CategoryNone, Action: ActionAllow}
+}
+
+// actionForCategory maps a detected category to the recommended action.
+func actionForCategory(category Category) Action {
+	if category == CategorySelfHarm || category == CategoryCrisis {
+		return ActionCrisisResource
+	}
+	return ActionSoftRedirect
+}
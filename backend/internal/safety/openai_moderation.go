@@ -0,0 +1,119 @@
+package safety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIModerator calls OpenAI's moderation endpoint (the omni-moderation
+// model family) to classify text across self-harm/violence/sexual categories.
+type OpenAIModerator struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAIModerator creates a moderator backed by the OpenAI moderation API.
+func NewOpenAIModerator(apiKey string) *OpenAIModerator {
+	return &OpenAIModerator{
+		apiKey:  apiKey,
+		baseURL: "https://api.openai.com/v1",
+		model:   "omni-moderation-latest",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type moderationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// Evaluate submits text to OpenAI's moderation endpoint and, when the result
+// is flagged, translates the category with the highest score into a
+// SafetyVerdict.
+func (o *OpenAIModerator) Evaluate(ctx context.Context, text string, direction Direction) (SafetyVerdict, error) {
+	payload, err := json.Marshal(moderationRequest{Input: text, Model: o.model})
+	if err != nil {
+		return SafetyVerdict{}, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/moderations", bytes.NewBuffer(payload))
+	if err != nil {
+		return SafetyVerdict{}, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+o.apiKey)
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return SafetyVerdict{}, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return SafetyVerdict{}, fmt.Errorf("moderation API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result moderationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return SafetyVerdict{}, fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+
+	if len(result.Results) == 0 || !result.Results[0].Flagged {
+		return allowedVerdict(), nil
+	}
+
+	category, confidence := topCategory(result.Results[0].CategoryScores)
+	return SafetyVerdict{
+		Allowed:    false,
+		Category:   category,
+		Confidence: confidence,
+		Action:     actionForCategory(category),
+		Reason:     "flagged by OpenAI moderation endpoint",
+	}, nil
+}
+
+// topCategory picks the highest-scoring category among all of scores
+// (per-category flags are not consulted) and maps it onto our own Category
+// taxonomy.
+func topCategory(scores map[string]float64) (Category, float64) {
+	best := CategoryCrisis
+	var bestScore float64
+	for name, score := range scores {
+		if score > bestScore {
+			bestScore = score
+			best = mapOpenAICategory(name)
+		}
+	}
+	return best, bestScore
+}
+
+func mapOpenAICategory(name string) Category {
+	switch {
+	case strings.HasPrefix(name, "self-harm"):
+		return CategorySelfHarm
+	case strings.HasPrefix(name, "violence"):
+		return CategoryViolence
+	case strings.HasPrefix(name, "sexual"):
+		return CategorySexual
+	default:
+		return CategoryCrisis
+	}
+}
@@ -0,0 +1,111 @@
+package safety
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMapOpenAICategory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected Category
+	}{
+		{"self-harm/intent", CategorySelfHarm},
+		{"violence/graphic", CategoryViolence},
+		{"sexual/minors", CategorySexual},
+		{"harassment", CategoryCrisis},
+	}
+
+	for _, tc := range testCases {
+		if got := mapOpenAICategory(tc.name); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestTopCategoryPicksHighestScore(t *testing.T) {
+	scores := map[string]float64{
+		"violence":  0.2,
+		"self-harm": 0.8,
+		"sexual":    0.1,
+	}
+
+	category, confidence := topCategory(scores)
+	if category != CategorySelfHarm {
+		t.Errorf("expected the highest-scoring category to win, got %v", category)
+	}
+	if confidence != 0.8 {
+		t.Errorf("expected confidence 0.8, got %v", confidence)
+	}
+}
+
+func TestOpenAIModeratorEvaluateFlagged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(moderationResponse{
+			Results: []struct {
+				Flagged        bool               `json:"flagged"`
+				CategoryScores map[string]float64 `json:"category_scores"`
+			}{
+				{Flagged: true, CategoryScores: map[string]float64{"self-harm/intent": 0.95}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	mod := NewOpenAIModerator("test-key")
+	mod.baseURL = server.URL
+
+	verdict, err := mod.Evaluate(context.Background(), "concerning text", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Allowed {
+		t.Fatal("expected a flagged response to produce a blocking verdict")
+	}
+	if verdict.Category != CategorySelfHarm {
+		t.Errorf("expected CategorySelfHarm, got %v", verdict.Category)
+	}
+}
+
+func TestOpenAIModeratorEvaluateNotFlagged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(moderationResponse{
+			Results: []struct {
+				Flagged        bool               `json:"flagged"`
+				CategoryScores map[string]float64 `json:"category_scores"`
+			}{
+				{Flagged: false},
+			},
+		})
+	}))
+	defer server.Close()
+
+	mod := NewOpenAIModerator("test-key")
+	mod.baseURL = server.URL
+
+	verdict, err := mod.Evaluate(context.Background(), "a benign message", DirectionInbound)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Allowed {
+		t.Fatalf("expected an unflagged response to be allowed, got %+v", verdict)
+	}
+}
+
+func TestOpenAIModeratorEvaluateNonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("upstream error"))
+	}))
+	defer server.Close()
+
+	mod := NewOpenAIModerator("test-key")
+	mod.baseURL = server.URL
+
+	if _, err := mod.Evaluate(context.Background(), "text", DirectionInbound); err == nil {
+		t.Fatal("expected a non-200 moderation response to surface as an error, not a silent allow")
+	}
+}
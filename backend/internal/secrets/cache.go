@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a resolved secret is reused before
+// CachingProvider resolves it again.
+const DefaultCacheTTL = 5 * time.Minute
+
+type cacheEntry struct {
+	value      string
+	resolvedAt time.Time
+}
+
+// CachingProvider wraps another Provider with a TTL cache, so a long-lived
+// container (or a Lambda warm start) doesn't re-resolve the same secret on
+// every request. StartBackgroundRefresh additionally re-resolves every
+// cached ref on a fixed interval, so a container that stays warm longer
+// than TTL still picks up a rotation without waiting for the next cache
+// miss.
+type CachingProvider struct {
+	inner Provider
+	ttl   time.Duration
+
+	mutex sync.RWMutex
+	cache map[string]cacheEntry
+}
+
+// NewCachingProvider wraps inner with a cache of the given ttl. A ttl <= 0
+// uses DefaultCacheTTL.
+func NewCachingProvider(inner Provider, ttl time.Duration) *CachingProvider {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &CachingProvider{
+		inner: inner,
+		ttl:   ttl,
+		cache: make(map[string]cacheEntry),
+	}
+}
+
+func (c *CachingProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	c.mutex.RLock()
+	entry, ok := c.cache[ref]
+	c.mutex.RUnlock()
+	if ok && time.Since(entry.resolvedAt) < c.ttl {
+		return entry.value, nil
+	}
+
+	value, err := c.inner.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	c.mutex.Lock()
+	c.cache[ref] = cacheEntry{value: value, resolvedAt: time.Now()}
+	c.mutex.Unlock()
+
+	return value, nil
+}
+
+// StartBackgroundRefresh re-resolves every ref currently in the cache every
+// interval, until ctx is done. A ref whose refresh fails (e.g. a transient
+// Secrets Manager outage) keeps its last-known-good value until the next
+// tick succeeds, rather than evicting it.
+func (c *CachingProvider) StartBackgroundRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refreshAll(ctx)
+			}
+		}
+	}()
+}
+
+func (c *CachingProvider) refreshAll(ctx context.Context) {
+	c.mutex.RLock()
+	refs := make([]string, 0, len(c.cache))
+	for ref := range c.cache {
+		refs = append(refs, ref)
+	}
+	c.mutex.RUnlock()
+
+	for _, ref := range refs {
+		value, err := c.inner.Resolve(ctx, ref)
+		if err != nil {
+			log.Printf("[secrets] background refresh failed for %s: %v", ref, err)
+			continue
+		}
+		c.mutex.Lock()
+		c.cache[ref] = cacheEntry{value: value, resolvedAt: time.Now()}
+		c.mutex.Unlock()
+	}
+}
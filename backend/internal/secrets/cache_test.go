@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider returns a value that increments on every Resolve call, so
+// tests can tell how many times the underlying Provider was actually hit.
+type countingProvider struct {
+	calls int32
+}
+
+func (p *countingProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	n := atomic.AddInt32(&p.calls, 1)
+	return fmt.Sprintf("%s-v%d", ref, n), nil
+}
+
+func TestCachingProvider_CachesWithinTTL(t *testing.T) {
+	inner := &countingProvider{}
+	cache := NewCachingProvider(inner, time.Hour)
+
+	first, err := cache.Resolve(context.Background(), "my-secret")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	second, err := cache.Resolve(context.Background(), "my-secret")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if first != second {
+		t.Errorf("expected cached value %q to be reused, got %q", first, second)
+	}
+	if atomic.LoadInt32(&inner.calls) != 1 {
+		t.Errorf("expected inner provider to be called once, got %d", inner.calls)
+	}
+}
+
+func TestCachingProvider_ReResolvesAfterTTL(t *testing.T) {
+	inner := &countingProvider{}
+	cache := NewCachingProvider(inner, 10*time.Millisecond)
+
+	first, err := cache.Resolve(context.Background(), "my-secret")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	second, err := cache.Resolve(context.Background(), "my-secret")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected value to be re-resolved after TTL expiry, got same value %q twice", first)
+	}
+	if atomic.LoadInt32(&inner.calls) != 2 {
+		t.Errorf("expected inner provider to be called twice, got %d", inner.calls)
+	}
+}
+
+func TestCachingProvider_StartBackgroundRefresh(t *testing.T) {
+	inner := &countingProvider{}
+	cache := NewCachingProvider(inner, time.Hour)
+
+	if _, err := cache.Resolve(context.Background(), "my-secret"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache.StartBackgroundRefresh(ctx, 5*time.Millisecond)
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for atomic.LoadInt32(&inner.calls) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&inner.calls) < 2 {
+		t.Fatalf("expected background refresh to re-resolve the cached ref, only saw %d calls", inner.calls)
+	}
+}
+
+func TestNewCachingProvider_NonPositiveTTLUsesDefault(t *testing.T) {
+	cache := NewCachingProvider(NewEnvProvider(), 0)
+	if cache.ttl != DefaultCacheTTL {
+		t.Errorf("ttl = %v, want DefaultCacheTTL (%v)", cache.ttl, DefaultCacheTTL)
+	}
+}
@@ -0,0 +1,30 @@
+// Package secrets resolves configuration values that may be a literal
+// string, an AWS Secrets Manager ARN, or an SSM Parameter Store path, so
+// config.LoadConfigWithResolver (and config.Manager's Reload) can pick up a
+// rotated OPENROUTER_API_KEY/OPENAI_API_KEY without a redeploy.
+// config.LoadConfig uses EnvProvider and reads the values as literals.
+package secrets
+
+import "context"
+
+// Provider resolves ref to its underlying secret value. ref is whatever was
+// read from an environment variable - a literal value, a
+// "arn:aws:secretsmanager:..." ARN, or an "ssm://..." path - and each
+// Provider implementation interprets it differently.
+type Provider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// EnvProvider returns ref unchanged. It's the default for any ref that
+// doesn't match a recognized secret-store prefix, so plain environment
+// variables keep working exactly as they did before this package existed.
+type EnvProvider struct{}
+
+// NewEnvProvider creates a Provider that treats ref as a literal value.
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{}
+}
+
+func (p *EnvProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	return ref, nil
+}
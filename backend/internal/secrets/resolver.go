@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	secretsManagerRefPrefix = "arn:aws:secretsmanager:"
+)
+
+// Resolver dispatches Resolve to the Provider matching ref's prefix: an AWS
+// Secrets Manager ARN, an "ssm://" SSM Parameter Store path, or - for
+// anything else - a plain literal value. This is the Provider
+// config.LoadConfigWithResolver is built around; callers don't need to know
+// which store a given env var's value actually lives in.
+type Resolver struct {
+	secretsManager Provider
+	ssm            Provider
+	env            Provider
+}
+
+// NewResolver creates a Resolver. secretsManager and/or ssm may be nil if
+// that backing store isn't configured in this environment; a ref matching a
+// nil provider's prefix resolves as an error rather than panicking.
+func NewResolver(secretsManager, ssm Provider) *Resolver {
+	return &Resolver{
+		secretsManager: secretsManager,
+		ssm:            ssm,
+		env:            NewEnvProvider(),
+	}
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretsManagerRefPrefix):
+		if r.secretsManager == nil {
+			return "", fmt.Errorf("secrets: no Secrets Manager provider configured to resolve %s", ref)
+		}
+		return r.secretsManager.Resolve(ctx, ref)
+	case strings.HasPrefix(ref, ssmRefPrefix):
+		if r.ssm == nil {
+			return "", fmt.Errorf("secrets: no SSM provider configured to resolve %s", ref)
+		}
+		return r.ssm.Resolve(ctx, ref)
+	default:
+		return r.env.Resolve(ctx, ref)
+	}
+}
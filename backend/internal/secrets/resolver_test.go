@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"context"
+	"testing"
+)
+
+type stubProvider struct {
+	value string
+	err   error
+}
+
+func (p *stubProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	return p.value, p.err
+}
+
+func TestResolver_DispatchesByPrefix(t *testing.T) {
+	sm := &stubProvider{value: "from-secrets-manager"}
+	ssmProvider := &stubProvider{value: "from-ssm"}
+	resolver := NewResolver(sm, ssmProvider)
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"secrets manager ARN", "arn:aws:secretsmanager:us-west-2:123456789012:secret:my-secret", "from-secrets-manager"},
+		{"ssm path", "ssm://my/param", "from-ssm"},
+		{"plain literal", "plain-value", "plain-value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.ref)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.ref, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver_MissingProviderErrors(t *testing.T) {
+	resolver := NewResolver(nil, nil)
+
+	if _, err := resolver.Resolve(context.Background(), "arn:aws:secretsmanager:us-west-2:123456789012:secret:my-secret"); err == nil {
+		t.Error("expected error resolving Secrets Manager ref with no provider configured")
+	}
+	if _, err := resolver.Resolve(context.Background(), "ssm://my/param"); err == nil {
+		t.Error("expected error resolving SSM ref with no provider configured")
+	}
+	if got, err := resolver.Resolve(context.Background(), "plain-value"); err != nil || got != "plain-value" {
+		t.Errorf("Resolve(plain-value) = (%q, %v), want (\"plain-value\", nil)", got, err)
+	}
+}
@@ -0,0 +1,30 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// SecretsManagerProvider resolves a "arn:aws:secretsmanager:..." ref via
+// AWS Secrets Manager's GetSecretValue.
+type SecretsManagerProvider struct {
+	client *secretsmanager.Client
+}
+
+// NewSecretsManagerProvider creates a Provider backed by client.
+func NewSecretsManagerProvider(client *secretsmanager.Client) *SecretsManagerProvider {
+	return &SecretsManagerProvider{client: client}
+}
+
+func (p *SecretsManagerProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: &ref})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", ref, err)
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", ref)
+	}
+	return *out.SecretString, nil
+}
@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ssmRefPrefix is stripped from ref before it's used as an SSM parameter
+// name.
+const ssmRefPrefix = "ssm://"
+
+// SSMProvider resolves an "ssm://path" ref via SSM Parameter Store's
+// GetParameter, decrypting SecureString parameters.
+type SSMProvider struct {
+	client *ssm.Client
+}
+
+// NewSSMProvider creates a Provider backed by client.
+func NewSSMProvider(client *ssm.Client) *SSMProvider {
+	return &SSMProvider{client: client}
+}
+
+func (p *SSMProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, ssmRefPrefix)
+	withDecryption := true
+
+	out, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: &withDecryption,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve SSM parameter %s: %w", name, err)
+	}
+	if out.Parameter == nil || out.Parameter.Value == nil {
+		return "", fmt.Errorf("SSM parameter %s has no value", name)
+	}
+	return *out.Parameter.Value, nil
+}
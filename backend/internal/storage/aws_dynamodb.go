@@ -0,0 +1,647 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"innerworld-backend/internal/config"
+	"innerworld-backend/internal/metrics"
+	"innerworld-backend/internal/resilience"
+	"innerworld-backend/internal/types"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+)
+
+// sessionIndexName is the GSI on LiveConversations keyed by session_id,
+// letting GetSessionMessages query a session's messages directly instead of
+// scanning the whole table (ConversationID, the table's partition key,
+// embeds the session's date and isn't known to most callers).
+const sessionIndexName = "SessionIndex"
+
+// callTimeout bounds one AWSDynamoDBClient method call, every retry and
+// page of it together, derived from the caller's context, so a stalled
+// connection can't hang a Lambda invocation past its own deadline.
+const callTimeout = 5 * time.Second
+
+// dynamoRetryConfig bounds how many times retry() retries a throttled or
+// transient DynamoDB call, with jittered exponential backoff so concurrent
+// Lambda invocations hitting the same hot partition don't retry in lockstep.
+func dynamoRetryConfig() resilience.RetryConfig {
+	return resilience.RetryConfig{
+		MaxAttempts:  4,
+		InitialDelay: 50 * time.Millisecond,
+		MaxDelay:     2 * time.Second,
+		JitterMode:   resilience.JitterFull,
+	}
+}
+
+// DynamoDBRetryStats is a point-in-time snapshot of AWSDynamoDBClient's
+// retry counters, for the health/metrics surface to expose.
+type DynamoDBRetryStats struct {
+	RetryTotal          int64
+	RetryExhaustedTotal int64
+}
+
+// retryStats accumulates AWSDynamoDBClient's retry counters across every
+// table operation, mirroring resilience.CircuitBreaker's Stats()/Snapshot()
+// pattern.
+type retryStats struct {
+	mu                  sync.Mutex
+	retryTotal          int64
+	retryExhaustedTotal int64
+}
+
+// AWSDynamoDBClient is the production DynamoDBClient backed by
+// aws-sdk-go-v2's service/dynamodb client, replacing MockDynamoDBClient once
+// real table infrastructure is provisioned.
+type AWSDynamoDBClient struct {
+	client *dynamodb.Client
+
+	liveConversationsTable     string
+	userContextCacheTable      string
+	processingCheckpointsTable string
+	embeddingsCacheTable       string
+	promptStarterCacheTable    string
+
+	retryConfig resilience.RetryConfig
+	stats       retryStats
+}
+
+// NewDynamoDBClient creates a production DynamoDBClient from cfg, resolving
+// AWS credentials via the default credential chain and pointing at
+// cfg.Endpoint when set, so the same client can target a local LocalStack
+// instance for development and tests.
+func NewDynamoDBClient(ctx context.Context, cfg config.DynamoDBConfig) (*AWSDynamoDBClient, error) {
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(cfg.Region))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = &cfg.Endpoint
+		}
+	})
+
+	return &AWSDynamoDBClient{
+		client:                     client,
+		liveConversationsTable:     cfg.LiveConversationsTable,
+		userContextCacheTable:      cfg.UserContextCacheTable,
+		processingCheckpointsTable: cfg.ProcessingCheckpointsTable,
+		embeddingsCacheTable:       cfg.EmbeddingsCacheTable,
+		promptStarterCacheTable:    cfg.PromptStarterCacheTable,
+		retryConfig:                dynamoRetryConfig(),
+	}, nil
+}
+
+// retry runs fn under op's name, retrying on throttling and transient
+// failures with c.retryConfig's jittered exponential backoff.
+// ConditionalCheckFailedException (optimistic-concurrency conflicts),
+// ValidationException, and context cancellation/deadline errors are never
+// retried - every attempt would fail the same way. Each retry and each
+// exhaustion is counted in c.stats; the whole call (every attempt together)
+// is timed under metrics.DynamoDBOperationDuration{op}.
+func (c *AWSDynamoDBClient) retry(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	start := time.Now()
+	defer metrics.DynamoDBOperationDuration.ObserveDuration(start, op)
+
+	lastAttempt := 0
+	_, err := resilience.RetryWithBackoff(ctx, c.retryConfig, c.isRetryable, func(ctx context.Context, attempt int) (struct{}, error) {
+		lastAttempt = attempt
+		if attempt > 1 {
+			c.stats.mu.Lock()
+			c.stats.retryTotal++
+			c.stats.mu.Unlock()
+		}
+		return struct{}{}, fn(ctx)
+	})
+
+	if err != nil && lastAttempt >= c.retryConfig.MaxAttempts {
+		c.stats.mu.Lock()
+		c.stats.retryExhaustedTotal++
+		c.stats.mu.Unlock()
+	}
+	return err
+}
+
+// isRetryable classifies a DynamoDB call's error: throttling and transient
+// service/network failures are retried; conditional-check failures,
+// validation errors, and context cancellation are terminal.
+func (c *AWSDynamoDBClient) isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if isConditionalCheckFailed(err) {
+		return false
+	}
+	return resilience.ClassifyAWSError(err).Retryable()
+}
+
+// RetryStats returns a point-in-time snapshot of this client's retry
+// counters across every table operation.
+func (c *AWSDynamoDBClient) RetryStats() DynamoDBRetryStats {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	return DynamoDBRetryStats{
+		RetryTotal:          c.stats.retryTotal,
+		RetryExhaustedTotal: c.stats.retryExhaustedTotal,
+	}
+}
+
+// StoreMessage stores a conversation message, optimistic-concurrency guarded
+// on (ConversationID, MessageID) via a DynamoDB conditional write.
+func (c *AWSDynamoDBClient) StoreMessage(ctx context.Context, item *types.LiveConversationItem, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if item.MessageID == "" {
+		item.MessageID = "msg_" + uuid.New().String()[:8]
+	}
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(24 * time.Hour).Unix()
+	}
+	item.Version = expectedVersion + 1
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message %s: %w", item.MessageID, err)
+	}
+
+	condition, values := versionCondition("version", expectedVersion)
+	err = c.retry(ctx, "store_message", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:                 &c.liveConversationsTable,
+			Item:                      av,
+			ConditionExpression:       &condition,
+			ExpressionAttributeValues: values,
+		})
+		return err
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrVersionMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store message %s: %w", item.MessageID, err)
+	}
+	return nil
+}
+
+// GetSessionMessages queries the SessionIndex GSI for every message in
+// sessionID, rather than scanning the whole LiveConversations table.
+func (c *AWSDynamoDBClient) GetSessionMessages(ctx context.Context, sessionID string) ([]types.LiveConversationItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var messages []types.LiveConversationItem
+	var exclusiveStartKey map[string]dynamodbtypes.AttributeValue
+
+	for {
+		var output *dynamodb.QueryOutput
+		err := c.retry(ctx, "get_session_messages", func(ctx context.Context) error {
+			var err error
+			output, err = c.client.Query(ctx, &dynamodb.QueryInput{
+				TableName:              &c.liveConversationsTable,
+				IndexName:              aws.String(sessionIndexName),
+				KeyConditionExpression: aws.String("session_id = :sessionID"),
+				ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+					":sessionID": &dynamodbtypes.AttributeValueMemberS{Value: sessionID},
+				},
+				ExclusiveStartKey: exclusiveStartKey,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query messages for session %s: %w", sessionID, err)
+		}
+
+		var page []types.LiveConversationItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal messages for session %s: %w", sessionID, err)
+		}
+		messages = append(messages, page...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		exclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return messages, nil
+}
+
+// DeleteSessionMessages removes every message belonging to sessionID,
+// looking them up via the SessionIndex GSI and batch-deleting by their
+// LiveConversations key (ConversationID, MessageID).
+func (c *AWSDynamoDBClient) DeleteSessionMessages(ctx context.Context, sessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	messages, err := c.GetSessionMessages(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to look up messages for session %s: %w", sessionID, err)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	const batchSize = 25
+	for start := 0; start < len(messages); start += batchSize {
+		end := start + batchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+
+		writeRequests := make([]dynamodbtypes.WriteRequest, 0, end-start)
+		for _, msg := range messages[start:end] {
+			writeRequests = append(writeRequests, dynamodbtypes.WriteRequest{
+				DeleteRequest: &dynamodbtypes.DeleteRequest{
+					Key: map[string]dynamodbtypes.AttributeValue{
+						"conversation_id": &dynamodbtypes.AttributeValueMemberS{Value: msg.ConversationID},
+						"message_id":      &dynamodbtypes.AttributeValueMemberS{Value: msg.MessageID},
+					},
+				},
+			})
+		}
+
+		err := c.retry(ctx, "delete_session_messages", func(ctx context.Context) error {
+			_, err := c.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]dynamodbtypes.WriteRequest{
+					c.liveConversationsTable: writeRequests,
+				},
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete messages for session %s: %w", sessionID, err)
+		}
+	}
+
+	return nil
+}
+
+// CacheUserContext stores the user's Neptune context, optimistic-concurrency
+// guarded on item.UserID via a DynamoDB conditional write.
+func (c *AWSDynamoDBClient) CacheUserContext(ctx context.Context, item *types.UserContextCacheItem, expectedVersion int64) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(1 * time.Hour).Unix()
+	}
+	item.LastUpdated = time.Now()
+	item.ResourceVersion = expectedVersion + 1
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context for user %s: %w", item.UserID, err)
+	}
+
+	condition, values := versionCondition("resource_version", expectedVersion)
+	err = c.retry(ctx, "cache_user_context", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:                 &c.userContextCacheTable,
+			Item:                      av,
+			ConditionExpression:       &condition,
+			ExpressionAttributeValues: values,
+		})
+		return err
+	})
+	if isConditionalCheckFailed(err) {
+		return ErrVersionMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cache context for user %s: %w", item.UserID, err)
+	}
+	return nil
+}
+
+// GetUserContext retrieves userID's cached context, returning an error if no
+// entry exists or its TTL has elapsed (matching MockDynamoDBClient).
+func (c *AWSDynamoDBClient) GetUserContext(ctx context.Context, userID string) (*types.UserContextCacheItem, error) {
+	item, err := c.GetUserContextForUpdate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("user context not found: %s", userID)
+	}
+	if time.Now().Unix() > item.TTL {
+		return nil, fmt.Errorf("user context expired: %s", userID)
+	}
+	return item, nil
+}
+
+// GetUserContextForUpdate retrieves userID's stored context item regardless
+// of whether its TTL has elapsed, returning (nil, nil) only when DynamoDB
+// has no item for userID at all. DynamoDB's TTL sweep can lag up to 48h
+// behind the timestamp an item expires at, so a "TTL-expired" item is still
+// present with a live ResourceVersion - GuaranteedUpdate needs that real
+// version, not GetUserContext's "expired means absent" simplification.
+func (c *AWSDynamoDBClient) GetUserContextForUpdate(ctx context.Context, userID string) (*types.UserContextCacheItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var output *dynamodb.GetItemOutput
+	err := c.retry(ctx, "get_user_context", func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: &c.userContextCacheTable,
+			Key: map[string]dynamodbtypes.AttributeValue{
+				"user_id": &dynamodbtypes.AttributeValueMemberS{Value: userID},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context for user %s: %w", userID, err)
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var item types.UserContextCacheItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal context for user %s: %w", userID, err)
+	}
+
+	return &item, nil
+}
+
+// RefreshUserContext updates userID's cached context with newContext,
+// unconditionally bumping ResourceVersion - callers that need optimistic
+// concurrency should go through GuaranteedUpdate instead.
+func (c *AWSDynamoDBClient) RefreshUserContext(ctx context.Context, userID string, newContext map[string]interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	current, err := c.GetUserContext(ctx, userID)
+	if err != nil {
+		current = &types.UserContextCacheItem{UserID: userID}
+	}
+
+	current.ContextData = newContext
+	current.LastUpdated = time.Now()
+	current.TTL = time.Now().Add(1 * time.Hour).Unix()
+	current.ResourceVersion++
+
+	av, err := attributevalue.MarshalMap(current)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context for user %s: %w", userID, err)
+	}
+
+	err = c.retry(ctx, "refresh_user_context", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: &c.userContextCacheTable,
+			Item:      av,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh context for user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// GetProcessingCheckpoint retrieves sessionID's checkpoint, returning (nil,
+// nil) rather than an error when none exists yet (matching
+// MockDynamoDBClient).
+func (c *AWSDynamoDBClient) GetProcessingCheckpoint(ctx context.Context, sessionID string) (*types.ProcessingCheckpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var output *dynamodb.GetItemOutput
+	err := c.retry(ctx, "get_processing_checkpoint", func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: &c.processingCheckpointsTable,
+			Key: map[string]dynamodbtypes.AttributeValue{
+				"session_id": &dynamodbtypes.AttributeValueMemberS{Value: sessionID},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint for session %s: %w", sessionID, err)
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var checkpoint types.ProcessingCheckpoint
+	if err := attributevalue.UnmarshalMap(output.Item, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint for session %s: %w", sessionID, err)
+	}
+	return &checkpoint, nil
+}
+
+// SaveProcessingCheckpoint persists checkpoint, overwriting any previous one
+// for the same session.
+func (c *AWSDynamoDBClient) SaveProcessingCheckpoint(ctx context.Context, checkpoint *types.ProcessingCheckpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if checkpoint.TTL == 0 {
+		checkpoint.TTL = time.Now().Add(24 * time.Hour).Unix()
+	}
+	checkpoint.UpdatedAt = time.Now()
+
+	av, err := attributevalue.MarshalMap(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint for session %s: %w", checkpoint.SessionID, err)
+	}
+
+	err = c.retry(ctx, "save_processing_checkpoint", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: &c.processingCheckpointsTable,
+			Item:      av,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint for session %s: %w", checkpoint.SessionID, err)
+	}
+	return nil
+}
+
+// GetEmbeddingCache retrieves cacheKey's cached embedding, returning (nil,
+// nil) rather than an error on a cache miss (matching MockDynamoDBClient
+// and GetProcessingCheckpoint).
+func (c *AWSDynamoDBClient) GetEmbeddingCache(ctx context.Context, cacheKey string) (*types.EmbeddingCacheItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var output *dynamodb.GetItemOutput
+	err := c.retry(ctx, "get_embedding_cache", func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: &c.embeddingsCacheTable,
+			Key: map[string]dynamodbtypes.AttributeValue{
+				"cache_key": &dynamodbtypes.AttributeValueMemberS{Value: cacheKey},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding cache entry %s: %w", cacheKey, err)
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var item types.EmbeddingCacheItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embedding cache entry %s: %w", cacheKey, err)
+	}
+	return &item, nil
+}
+
+// SaveEmbeddingCache persists item, overwriting any previous entry under the
+// same CacheKey.
+func (c *AWSDynamoDBClient) SaveEmbeddingCache(ctx context.Context, item *types.EmbeddingCacheItem) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(30 * 24 * time.Hour).Unix()
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding cache entry %s: %w", item.CacheKey, err)
+	}
+
+	err = c.retry(ctx, "save_embedding_cache", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: &c.embeddingsCacheTable,
+			Item:      av,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save embedding cache entry %s: %w", item.CacheKey, err)
+	}
+	return nil
+}
+
+// GetPromptStarterCache retrieves cacheKey's cached prompt starters,
+// returning (nil, nil) rather than an error on a cache miss (matching
+// GetEmbeddingCache).
+func (c *AWSDynamoDBClient) GetPromptStarterCache(ctx context.Context, cacheKey string) (*types.PromptStarterCacheItem, error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	var output *dynamodb.GetItemOutput
+	err := c.retry(ctx, "get_prompt_starter_cache", func(ctx context.Context) error {
+		var err error
+		output, err = c.client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: &c.promptStarterCacheTable,
+			Key: map[string]dynamodbtypes.AttributeValue{
+				"cache_key": &dynamodbtypes.AttributeValueMemberS{Value: cacheKey},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt starter cache entry %s: %w", cacheKey, err)
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var item types.PromptStarterCacheItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prompt starter cache entry %s: %w", cacheKey, err)
+	}
+	return &item, nil
+}
+
+// SavePromptStarterCache persists item, overwriting any previous entry
+// under the same CacheKey, with a short TTL so a user's suggestions
+// refresh soon after their context changes.
+func (c *AWSDynamoDBClient) SavePromptStarterCache(ctx context.Context, item *types.PromptStarterCacheItem) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(15 * time.Minute).Unix()
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prompt starter cache entry %s: %w", item.CacheKey, err)
+	}
+
+	err = c.retry(ctx, "save_prompt_starter_cache", func(ctx context.Context) error {
+		_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: &c.promptStarterCacheTable,
+			Item:      av,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save prompt starter cache entry %s: %w", item.CacheKey, err)
+	}
+	return nil
+}
+
+// HealthCheck verifies the LiveConversations table is reachable.
+func (c *AWSDynamoDBClient) HealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	err := c.retry(ctx, "health_check", func(ctx context.Context) error {
+		_, err := c.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+			TableName: &c.liveConversationsTable,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("DynamoDB health check failed: %w", err)
+	}
+	return nil
+}
+
+// versionCondition builds the ConditionExpression/ExpressionAttributeValues
+// pair enforcing expectedVersion against an item's stored value for
+// attrName (the item's optimistic-concurrency attribute - "version" for
+// LiveConversationItem, "resource_version" for UserContextCacheItem): a
+// brand new item (expectedVersion 0) must not already exist, otherwise its
+// stored value must still match what the caller last read.
+func versionCondition(attrName string, expectedVersion int64) (string, map[string]dynamodbtypes.AttributeValue) {
+	if expectedVersion == 0 {
+		return fmt.Sprintf("attribute_not_exists(%s)", attrName), nil
+	}
+	return fmt.Sprintf("%s = :expectedVersion", attrName), map[string]dynamodbtypes.AttributeValue{
+		":expectedVersion": &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", expectedVersion)},
+	}
+}
+
+// isConditionalCheckFailed reports whether err is DynamoDB's response to a
+// failed ConditionExpression on PutItem.
+func isConditionalCheckFailed(err error) bool {
+	if err == nil {
+		return false
+	}
+	var conditionalErr *dynamodbtypes.ConditionalCheckFailedException
+	if errors.As(err, &conditionalErr) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "ConditionalCheckFailedException"
+	}
+	return false
+}
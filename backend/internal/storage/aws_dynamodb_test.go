@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"innerworld-backend/internal/resilience"
+
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	smithy "github.com/aws/smithy-go"
+)
+
+// newTestDynamoDBClient builds an AWSDynamoDBClient with no real *dynamodb.Client,
+// for exercising retry()/isRetryable() directly against a fault-injecting fn
+// instead of standing up a DynamoDB endpoint.
+func newTestDynamoDBClient() *AWSDynamoDBClient {
+	return &AWSDynamoDBClient{
+		retryConfig: resilience.RetryConfig{
+			MaxAttempts:  3,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     5 * time.Millisecond,
+			JitterMode:   resilience.JitterFull,
+		},
+	}
+}
+
+// faultInjector returns fn that fails with err on its first failures calls,
+// then succeeds, counting how many times it was invoked - a minimal
+// fault-injecting fake for exercising retry()'s backoff/counter behavior.
+func faultInjector(failures int, err error) (fn func(ctx context.Context) error, calls *int) {
+	calls = new(int)
+	fn = func(ctx context.Context) error {
+		*calls++
+		if *calls <= failures {
+			return err
+		}
+		return nil
+	}
+	return fn, calls
+}
+
+func TestAWSDynamoDBClientRetrySucceedsAfterTransientFailures(t *testing.T) {
+	c := newTestDynamoDBClient()
+	fn, calls := faultInjector(2, &smithy.GenericAPIError{Code: "ThrottlingException"})
+
+	if err := c.retry(context.Background(), "test_op", fn); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("expected 3 calls (2 failures + 1 success), got %d", *calls)
+	}
+
+	stats := c.RetryStats()
+	if stats.RetryTotal != 2 {
+		t.Errorf("expected RetryTotal=2, got %d", stats.RetryTotal)
+	}
+	if stats.RetryExhaustedTotal != 0 {
+		t.Errorf("expected RetryExhaustedTotal=0 on eventual success, got %d", stats.RetryExhaustedTotal)
+	}
+}
+
+func TestAWSDynamoDBClientRetryExhaustsAndCounts(t *testing.T) {
+	c := newTestDynamoDBClient()
+	fn, calls := faultInjector(100, &smithy.GenericAPIError{Code: "ThrottlingException"})
+
+	if err := c.retry(context.Background(), "test_op", fn); err == nil {
+		t.Fatal("expected an error once every attempt is exhausted")
+	}
+	if *calls != c.retryConfig.MaxAttempts {
+		t.Fatalf("expected exactly MaxAttempts (%d) calls, got %d", c.retryConfig.MaxAttempts, *calls)
+	}
+
+	stats := c.RetryStats()
+	if stats.RetryTotal != int64(c.retryConfig.MaxAttempts-1) {
+		t.Errorf("expected RetryTotal=%d, got %d", c.retryConfig.MaxAttempts-1, stats.RetryTotal)
+	}
+	if stats.RetryExhaustedTotal != 1 {
+		t.Errorf("expected RetryExhaustedTotal=1, got %d", stats.RetryExhaustedTotal)
+	}
+}
+
+func TestAWSDynamoDBClientRetryDoesNotRetryConditionalCheckFailure(t *testing.T) {
+	c := newTestDynamoDBClient()
+	fn, calls := faultInjector(100, &dynamodbtypes.ConditionalCheckFailedException{})
+
+	err := c.retry(context.Background(), "test_op", fn)
+	if !isConditionalCheckFailed(err) {
+		t.Fatalf("expected the conditional-check failure to pass through, got %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected exactly 1 call for a non-retryable error, got %d", *calls)
+	}
+
+	stats := c.RetryStats()
+	if stats.RetryTotal != 0 {
+		t.Errorf("expected no retries counted for a non-retryable error, got %d", stats.RetryTotal)
+	}
+}
+
+func TestAWSDynamoDBClientIsRetryable(t *testing.T) {
+	c := newTestDynamoDBClient()
+
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"context canceled", context.Canceled, false},
+		{"context deadline exceeded", context.DeadlineExceeded, false},
+		{"conditional check failed", &dynamodbtypes.ConditionalCheckFailedException{}, false},
+		{"throttling exception", &smithy.GenericAPIError{Code: "ThrottlingException"}, true},
+		{"provisioned throughput exceeded", &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"}, true},
+		{"validation exception", &smithy.GenericAPIError{Code: "ValidationException"}, false},
+		{"plain error", errors.New("some i/o error"), true},
+	}
+
+	for _, tc := range testCases {
+		if got := c.isRetryable(tc.err); got != tc.retryable {
+			t.Errorf("%s: expected isRetryable=%v, got %v", tc.name, tc.retryable, got)
+		}
+	}
+}
+
+func TestVersionConditionNewItem(t *testing.T) {
+	condition, values := versionCondition("version", 0)
+	if condition != "attribute_not_exists(version)" {
+		t.Errorf("expected a brand-new item to require attribute_not_exists, got %q", condition)
+	}
+	if values != nil {
+		t.Errorf("expected no expression attribute values for a new item, got %v", values)
+	}
+}
+
+func TestVersionConditionExistingItem(t *testing.T) {
+	condition, values := versionCondition("resource_version", 5)
+	if condition != "resource_version = :expectedVersion" {
+		t.Errorf("expected an equality check against the expected version, got %q", condition)
+	}
+	av, ok := values[":expectedVersion"].(*dynamodbtypes.AttributeValueMemberN)
+	if !ok || av.Value != "5" {
+		t.Errorf("expected :expectedVersion to be N(5), got %#v", values[":expectedVersion"])
+	}
+}
+
+func TestIsConditionalCheckFailed(t *testing.T) {
+	if isConditionalCheckFailed(nil) {
+		t.Error("expected nil to not be a conditional check failure")
+	}
+	if !isConditionalCheckFailed(&dynamodbtypes.ConditionalCheckFailedException{}) {
+		t.Error("expected the typed exception to be recognized")
+	}
+	if !isConditionalCheckFailed(&smithy.GenericAPIError{Code: "ConditionalCheckFailedException"}) {
+		t.Error("expected a smithy APIError with the matching code to be recognized")
+	}
+	if isConditionalCheckFailed(errors.New("some other error")) {
+		t.Error("expected an unrelated error to not be recognized")
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"innerworld-backend/internal/types"
 	"log"
@@ -11,38 +12,112 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrVersionMismatch is returned by StoreMessage/CacheUserContext when the
+// caller's expectedVersion no longer matches the item's stored version -
+// DynamoDB's ConditionalCheckFailedException translated into a sentinel
+// every implementation returns, so callers can re-read, merge, and retry
+// via a bounded loop (see GuaranteedUpdate) instead of clobbering a
+// concurrent writer's update.
+var ErrVersionMismatch = errors.New("storage: version mismatch, re-read and retry")
+
+// ErrConflict wraps ErrVersionMismatch and is what GuaranteedUpdate returns
+// when its conditional write loses the race on every one of its
+// GuaranteedUpdateAttempts attempts - it carries the userID so callers can
+// log or surface which resource lost the race, and unwraps to
+// ErrVersionMismatch so existing errors.Is(err, ErrVersionMismatch) checks
+// keep working.
+type ErrConflict struct {
+	UserID string
+}
+
+func (e *ErrConflict) Error() string {
+	return fmt.Sprintf("storage: conflicting update for user %s did not converge, give up and re-read", e.UserID)
+}
+
+func (e *ErrConflict) Unwrap() error {
+	return ErrVersionMismatch
+}
+
 // DynamoDBClient interface for production and mock implementations
 type DynamoDBClient interface {
 	// LiveConversations operations
-	StoreMessage(ctx context.Context, item *types.LiveConversationItem) error
+	StoreMessage(ctx context.Context, item *types.LiveConversationItem, expectedVersion int64) error
 	GetSessionMessages(ctx context.Context, sessionID string) ([]types.LiveConversationItem, error)
 	DeleteSessionMessages(ctx context.Context, sessionID string) error
 
-	// UserContextCache operations
-	CacheUserContext(ctx context.Context, item *types.UserContextCacheItem) error
+	// UserContextCache operations. CacheUserContext is optimistic-concurrency
+	// guarded: expectedVersion must match the item currently stored for
+	// UserID (0 for a brand-new item), or it returns ErrVersionMismatch
+	// without writing. GetUserContext returns the item with its current
+	// ResourceVersion populated, so callers always have the token needed to
+	// write back.
+	// CacheUserContext(ctx, item, expectedVersion) translates to a DynamoDB
+	// conditional write on resource_version: attribute_not_exists(resource_version)
+	// when expectedVersion is 0, otherwise resource_version = :expected.
+	CacheUserContext(ctx context.Context, item *types.UserContextCacheItem, expectedVersion int64) error
 	GetUserContext(ctx context.Context, userID string) (*types.UserContextCacheItem, error)
+
+	// GetUserContextForUpdate retrieves userID's stored context item
+	// regardless of whether its TTL has elapsed, returning (nil, nil) only
+	// when no item exists at all - unlike GetUserContext, which treats a
+	// TTL-elapsed item as absent. DynamoDB doesn't delete expired items the
+	// instant their TTL passes (the real sweep can take up to 48h), so their
+	// ResourceVersion is still live and must be used for the conditional
+	// write; GuaranteedUpdate uses this instead of GetUserContext so it
+	// doesn't mistake "expired" for "missing" and retry a doomed
+	// attribute_not_exists write forever.
+	GetUserContextForUpdate(ctx context.Context, userID string) (*types.UserContextCacheItem, error)
+
 	RefreshUserContext(ctx context.Context, userID string, newContext map[string]interface{}) error
+
+	// ProcessingCheckpoint operations, for resumable session-end processing
+	GetProcessingCheckpoint(ctx context.Context, sessionID string) (*types.ProcessingCheckpoint, error)
+	SaveProcessingCheckpoint(ctx context.Context, checkpoint *types.ProcessingCheckpoint) error
+
+	// EmbeddingCache operations, backing embeddings.DynamoDBCacheStore.
+	// GetEmbeddingCache returns (nil, nil) rather than an error on a cache
+	// miss, matching GetProcessingCheckpoint.
+	GetEmbeddingCache(ctx context.Context, cacheKey string) (*types.EmbeddingCacheItem, error)
+	SaveEmbeddingCache(ctx context.Context, item *types.EmbeddingCacheItem) error
+
+	// PromptStarterCache operations, backing GET /prompt-starters. Same
+	// cache-miss convention as GetEmbeddingCache: (nil, nil), not an error.
+	GetPromptStarterCache(ctx context.Context, cacheKey string) (*types.PromptStarterCacheItem, error)
+	SavePromptStarterCache(ctx context.Context, item *types.PromptStarterCacheItem) error
+
+	// HealthCheck verifies the store is reachable (AWSDynamoDBClient checks
+	// the LiveConversations table only).
+	HealthCheck(ctx context.Context) error
 }
 
 // MockDynamoDBClient implements DynamoDBClient for testing
 type MockDynamoDBClient struct {
 	// In-memory storage for mock testing
-	conversations map[string][]types.LiveConversationItem // sessionID -> messages
-	contextCache  map[string]types.UserContextCacheItem   // userID -> context
-	mutex         sync.RWMutex
+	conversations  map[string][]types.LiveConversationItem // sessionID -> messages
+	contextCache   map[string]types.UserContextCacheItem   // userID -> context
+	checkpoints    map[string]types.ProcessingCheckpoint   // sessionID -> checkpoint
+	embeddingCache map[string]types.EmbeddingCacheItem     // cacheKey -> embedding
+	starterCache   map[string]types.PromptStarterCacheItem // cacheKey -> prompt starters
+	mutex          sync.RWMutex
 }
 
 // NewMockDynamoDBClient creates a new mock DynamoDB client
 func NewMockDynamoDBClient() *MockDynamoDBClient {
 	return &MockDynamoDBClient{
-		conversations: make(map[string][]types.LiveConversationItem),
-		contextCache:  make(map[string]types.UserContextCacheItem),
-		mutex:         sync.RWMutex{},
+		conversations:  make(map[string][]types.LiveConversationItem),
+		contextCache:   make(map[string]types.UserContextCacheItem),
+		checkpoints:    make(map[string]types.ProcessingCheckpoint),
+		embeddingCache: make(map[string]types.EmbeddingCacheItem),
+		starterCache:   make(map[string]types.PromptStarterCacheItem),
+		mutex:          sync.RWMutex{},
 	}
 }
 
-// StoreMessage stores a conversation message
-func (m *MockDynamoDBClient) StoreMessage(ctx context.Context, item *types.LiveConversationItem) error {
+// StoreMessage stores a conversation message, optimistic-concurrency guarded
+// on (SessionID, MessageID): expectedVersion must match the currently stored
+// message's Version (0 if none exists yet), or it returns
+// ErrVersionMismatch without writing.
+func (m *MockDynamoDBClient) StoreMessage(ctx context.Context, item *types.LiveConversationItem, expectedVersion int64) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -51,18 +126,34 @@ func (m *MockDynamoDBClient) StoreMessage(ctx context.Context, item *types.LiveC
 		item.MessageID = "msg_" + uuid.New().String()[:8]
 	}
 
+	sessionMessages := m.conversations[item.SessionID]
+
+	existingIndex := -1
+	var currentVersion int64
+	for i, existing := range sessionMessages {
+		if existing.MessageID == item.MessageID {
+			existingIndex = i
+			currentVersion = existing.Version
+			break
+		}
+	}
+	if currentVersion != expectedVersion {
+		return ErrVersionMismatch
+	}
+
 	// Set TTL to 24 hours from now (for testing)
 	if item.TTL == 0 {
 		item.TTL = time.Now().Add(24 * time.Hour).Unix()
 	}
+	item.Version = currentVersion + 1
 
-	// Add to in-memory storage
-	sessionMessages := m.conversations[item.SessionID]
-
-	// Set message sequence
-	item.MessageSequence = len(sessionMessages) + 1
-
-	m.conversations[item.SessionID] = append(sessionMessages, *item)
+	if existingIndex >= 0 {
+		sessionMessages[existingIndex] = *item
+	} else {
+		item.MessageSequence = len(sessionMessages) + 1
+		sessionMessages = append(sessionMessages, *item)
+	}
+	m.conversations[item.SessionID] = sessionMessages
 
 	log.Printf("MockDynamoDB: Stored message %s for session %s", item.MessageID, item.SessionID)
 	return nil
@@ -92,17 +183,29 @@ func (m *MockDynamoDBClient) DeleteSessionMessages(ctx context.Context, sessionI
 	return nil
 }
 
-// CacheUserContext stores user's Neptune context for fast access
-func (m *MockDynamoDBClient) CacheUserContext(ctx context.Context, item *types.UserContextCacheItem) error {
+// CacheUserContext stores user's Neptune context for fast access,
+// optimistic-concurrency guarded: expectedVersion must match item.UserID's
+// currently stored ResourceVersion (0 if no cache entry exists yet), or it
+// returns ErrVersionMismatch without writing.
+func (m *MockDynamoDBClient) CacheUserContext(ctx context.Context, item *types.UserContextCacheItem, expectedVersion int64) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	var currentVersion int64
+	if existing, exists := m.contextCache[item.UserID]; exists {
+		currentVersion = existing.ResourceVersion
+	}
+	if currentVersion != expectedVersion {
+		return ErrVersionMismatch
+	}
+
 	// Set TTL to 1 hour from now
 	if item.TTL == 0 {
 		item.TTL = time.Now().Add(1 * time.Hour).Unix()
 	}
 
 	item.LastUpdated = time.Now()
+	item.ResourceVersion = currentVersion + 1
 	m.contextCache[item.UserID] = *item
 
 	log.Printf("MockDynamoDB: Cached context for user %s", item.UserID)
@@ -111,11 +214,11 @@ func (m *MockDynamoDBClient) CacheUserContext(ctx context.Context, item *types.U
 
 // GetUserContext retrieves cached user context
 func (m *MockDynamoDBClient) GetUserContext(ctx context.Context, userID string) (*types.UserContextCacheItem, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	item, exists := m.contextCache[userID]
-	if !exists {
+	item, err := m.GetUserContextForUpdate(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
 		return nil, fmt.Errorf("user context not found: %s", userID)
 	}
 
@@ -125,9 +228,75 @@ func (m *MockDynamoDBClient) GetUserContext(ctx context.Context, userID string)
 	}
 
 	log.Printf("MockDynamoDB: Retrieved cached context for user %s", userID)
+	return item, nil
+}
+
+// GetUserContextForUpdate retrieves userID's stored context item regardless
+// of TTL expiration, returning (nil, nil) only when no item exists.
+func (m *MockDynamoDBClient) GetUserContextForUpdate(ctx context.Context, userID string) (*types.UserContextCacheItem, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	item, exists := m.contextCache[userID]
+	if !exists {
+		return nil, nil
+	}
 	return &item, nil
 }
 
+// GuaranteedUpdateAttempts bounds how many times GuaranteedUpdate re-reads
+// and retries before giving up, so a pathologically hot key can't spin a
+// caller forever.
+const GuaranteedUpdateAttempts = 5
+
+// GuaranteedUpdate loads userID's current cached context via
+// GetUserContextForUpdate (treating only a genuinely missing entry as a
+// fresh item at version 0 - an entry whose TTL has elapsed but that
+// DynamoDB hasn't swept yet still has its real ResourceVersion as the
+// expected version for the conditional write), passes it to tryUpdate to
+// produce the item to write, and retries the conditional write up to
+// GuaranteedUpdateAttempts times on ErrVersionMismatch. This is the
+// standard optimistic-concurrency retry loop for a key multiple Lambdas
+// (a login refresh, an active session, and session-end processing) can all
+// try to update concurrently, so a write started from a stale read never
+// silently clobbers one that landed in between.
+//
+// tryUpdate can itself fail - for a business-logic precondition that no
+// longer holds once the current item is re-read, not just a storage error -
+// in which case GuaranteedUpdate returns that error immediately without
+// retrying. If the conditional write keeps losing the race for
+// GuaranteedUpdateAttempts attempts, it gives up and returns *ErrConflict.
+func GuaranteedUpdate(ctx context.Context, client DynamoDBClient, userID string, tryUpdate func(current *types.UserContextCacheItem) (*types.UserContextCacheItem, error)) error {
+	for attempt := 0; attempt < GuaranteedUpdateAttempts; attempt++ {
+		var expectedVersion int64
+		current, err := client.GetUserContextForUpdate(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to read current context for user %s: %w", userID, err)
+		}
+		if current != nil {
+			expectedVersion = current.ResourceVersion
+		} else {
+			current = &types.UserContextCacheItem{UserID: userID}
+		}
+
+		next, err := tryUpdate(current)
+		if err != nil {
+			return err
+		}
+		next.UserID = userID
+
+		if err := client.CacheUserContext(ctx, next, expectedVersion); err != nil {
+			if errors.Is(err, ErrVersionMismatch) {
+				continue
+			}
+			return err
+		}
+		return nil
+	}
+
+	return &ErrConflict{UserID: userID}
+}
+
 // RefreshUserContext updates cached context with new Neptune data
 func (m *MockDynamoDBClient) RefreshUserContext(ctx context.Context, userID string, newContext map[string]interface{}) error {
 	m.mutex.Lock()
@@ -146,6 +315,7 @@ func (m *MockDynamoDBClient) RefreshUserContext(ctx context.Context, userID stri
 	item.ContextData = newContext
 	item.LastUpdated = time.Now()
 	item.TTL = time.Now().Add(1 * time.Hour).Unix() // Reset TTL
+	item.ResourceVersion++
 
 	m.contextCache[userID] = item
 
@@ -153,6 +323,103 @@ func (m *MockDynamoDBClient) RefreshUserContext(ctx context.Context, userID stri
 	return nil
 }
 
+// GetProcessingCheckpoint retrieves sessionID's checkpoint, returning (nil,
+// nil) rather than an error when none exists yet - that's the normal state
+// for a session's first processing attempt, not a failure.
+func (m *MockDynamoDBClient) GetProcessingCheckpoint(ctx context.Context, sessionID string) (*types.ProcessingCheckpoint, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	checkpoint, exists := m.checkpoints[sessionID]
+	if !exists {
+		return nil, nil
+	}
+
+	log.Printf("MockDynamoDB: Retrieved processing checkpoint for session %s", sessionID)
+	return &checkpoint, nil
+}
+
+// SaveProcessingCheckpoint persists checkpoint, overwriting any previous one
+// for the same session.
+func (m *MockDynamoDBClient) SaveProcessingCheckpoint(ctx context.Context, checkpoint *types.ProcessingCheckpoint) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if checkpoint.TTL == 0 {
+		checkpoint.TTL = time.Now().Add(24 * time.Hour).Unix()
+	}
+	checkpoint.UpdatedAt = time.Now()
+	m.checkpoints[checkpoint.SessionID] = *checkpoint
+
+	log.Printf("MockDynamoDB: Saved processing checkpoint for session %s", checkpoint.SessionID)
+	return nil
+}
+
+// GetEmbeddingCache retrieves cacheKey's cached embedding, returning (nil,
+// nil) rather than an error on a cache miss - that's the normal state for
+// text not seen before, not a failure.
+func (m *MockDynamoDBClient) GetEmbeddingCache(ctx context.Context, cacheKey string) (*types.EmbeddingCacheItem, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	item, exists := m.embeddingCache[cacheKey]
+	if !exists {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
+// SaveEmbeddingCache persists item, overwriting any previous entry under
+// the same CacheKey.
+func (m *MockDynamoDBClient) SaveEmbeddingCache(ctx context.Context, item *types.EmbeddingCacheItem) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(30 * 24 * time.Hour).Unix()
+	}
+	m.embeddingCache[item.CacheKey] = *item
+
+	return nil
+}
+
+// GetPromptStarterCache retrieves cacheKey's cached prompt starters,
+// returning (nil, nil) rather than an error on a cache miss (matching
+// GetEmbeddingCache).
+func (m *MockDynamoDBClient) GetPromptStarterCache(ctx context.Context, cacheKey string) (*types.PromptStarterCacheItem, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	item, exists := m.starterCache[cacheKey]
+	if !exists {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
+// SavePromptStarterCache persists item, overwriting any previous entry
+// under the same CacheKey.
+func (m *MockDynamoDBClient) SavePromptStarterCache(ctx context.Context, item *types.PromptStarterCacheItem) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if item.TTL == 0 {
+		item.TTL = time.Now().Add(15 * time.Minute).Unix()
+	}
+	m.starterCache[item.CacheKey] = *item
+
+	return nil
+}
+
+// HealthCheck always reports healthy - there's no real connectivity to
+// verify for an in-memory mock.
+func (m *MockDynamoDBClient) HealthCheck(ctx context.Context) error {
+	log.Printf("MockDynamoDB: health check ok")
+	return nil
+}
+
 // Helper function to create conversation ID based on session and date
 func CreateConversationID(sessionID string) string {
 	return fmt.Sprintf("%s_%s", sessionID, time.Now().Format("2006-01-02"))
@@ -0,0 +1,82 @@
+package flowtest
+
+import "strings"
+
+// Extraction is a ranked reading of a message: Intents and Entities are
+// ordered most-likely-first, so Recall@K (see report.go) can ask "was the
+// expected value among the top K guesses" instead of only "was it guess #1".
+type Extraction struct {
+	Intents  []string
+	Entities []string
+}
+
+// Extractor derives an Extraction from a user message. This package has no
+// access to a real intent/entity classifier - InnerWorld doesn't have one
+// yet - so Extractor is a seam: scenarios assert against whatever
+// implementation the Runner is given, and a future NLU component can plug
+// in here without changing Scenario, Runner, or the report format.
+type Extractor interface {
+	Extract(text string) Extraction
+}
+
+// KeywordExtractor is the default Extractor: a small curated keyword table
+// in the same spirit as safety.KeywordModerator, good enough to make
+// match_intent/match_entities assertions meaningful in fixtures without
+// claiming any real NLU capability.
+type KeywordExtractor struct{}
+
+// intentKeywords maps an intent label to the phrases that imply it. Checked
+// in order, so more specific intents should be listed before general ones.
+var intentKeywords = []struct {
+	intent   string
+	keywords []string
+}{
+	{"crisis", []string{"kill myself", "end it all", "hurt myself", "suicide"}},
+	{"anxiety", []string{"anxious", "anxiety", "worried", "nervous", "panic"}},
+	{"coping_request", []string{"breathing exercise", "coping", "calm down", "help me with"}},
+	{"gratitude", []string{"thank you", "thanks", "that helped", "appreciate"}},
+	{"greeting", []string{"hi", "hello", "hey"}},
+}
+
+// entityKeywords maps an entity label to the phrases that imply its
+// presence, checked in order so the first few hits rank highest.
+var entityKeywords = []struct {
+	entity   string
+	keywords []string
+}{
+	{"school", []string{"school", "class", "exam", "homework"}},
+	{"family", []string{"mom", "dad", "parent", "sister", "brother"}},
+	{"friend", []string{"friend", "classmate"}},
+	{"sleep", []string{"sleep", "tired", "insomnia"}},
+}
+
+// Extract implements Extractor by scanning text (case-insensitively) for
+// intentKeywords and entityKeywords, in list order.
+func (KeywordExtractor) Extract(text string) Extraction {
+	lower := strings.ToLower(text)
+
+	var extraction Extraction
+	for _, candidate := range intentKeywords {
+		if containsAny(lower, candidate.keywords) {
+			extraction.Intents = append(extraction.Intents, candidate.intent)
+		}
+	}
+	for _, candidate := range entityKeywords {
+		if containsAny(lower, candidate.keywords) {
+			extraction.Entities = append(extraction.Entities, candidate.entity)
+		}
+	}
+	if len(extraction.Intents) == 0 {
+		extraction.Intents = []string{"unknown"}
+	}
+	return extraction
+}
+
+func containsAny(haystack string, needles []string) bool {
+	for _, needle := range needles {
+		if strings.Contains(haystack, needle) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package flowtest
+
+import "testing"
+
+func TestKeywordExtractorDetectsAnxietyAndEntity(t *testing.T) {
+	extraction := KeywordExtractor{}.Extract("I'm anxious about my exam at school tomorrow")
+
+	if !containsString(extraction.Intents, "anxiety") {
+		t.Errorf("Expected anxiety among intents, got %v", extraction.Intents)
+	}
+	if !containsString(extraction.Entities, "school") {
+		t.Errorf("Expected school among entities, got %v", extraction.Entities)
+	}
+}
+
+func TestKeywordExtractorUnknownIntent(t *testing.T) {
+	extraction := KeywordExtractor{}.Extract("the weather is nice today")
+
+	if len(extraction.Intents) != 1 || extraction.Intents[0] != "unknown" {
+		t.Errorf("Expected a single unknown intent, got %v", extraction.Intents)
+	}
+	if len(extraction.Entities) != 0 {
+		t.Errorf("Expected no entities, got %v", extraction.Entities)
+	}
+}
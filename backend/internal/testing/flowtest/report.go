@@ -0,0 +1,131 @@
+package flowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// RecallAtK reports, across a suite, what fraction of turns with a
+// non-empty match_intent/match_entities expectation found that value
+// within the top K of the Extractor's ranked guess - not just its top
+// pick. It's computed separately for intents and entities since an
+// Extraction ranks each independently.
+type RecallAtK struct {
+	K             int
+	IntentRecall  float64
+	EntityRecall  float64
+	IntentSamples int
+	EntitySamples int
+}
+
+// ComputeRecallAtK computes one RecallAtK per value in ks, across every
+// turn in results.
+func ComputeRecallAtK(results []*ScenarioResult, ks []int) []RecallAtK {
+	reports := make([]RecallAtK, len(ks))
+	for i, k := range ks {
+		reports[i] = recallAtK(results, k)
+	}
+	return reports
+}
+
+func recallAtK(results []*ScenarioResult, k int) RecallAtK {
+	report := RecallAtK{K: k}
+
+	var intentHits, entityHits, entityExpected int
+	for _, scenario := range results {
+		for _, turn := range scenario.Turns {
+			topIntents := truncate(turn.Extraction.Intents, k)
+			if turn.Turn.MatchIntent != "" {
+				report.IntentSamples++
+				if containsString(topIntents, turn.Turn.MatchIntent) {
+					intentHits++
+				}
+			}
+
+			topEntities := truncate(turn.Extraction.Entities, k)
+			for _, entity := range turn.Turn.MatchEntities {
+				entityExpected++
+				if containsString(topEntities, entity) {
+					entityHits++
+				}
+			}
+		}
+	}
+
+	if report.IntentSamples > 0 {
+		report.IntentRecall = float64(intentHits) / float64(report.IntentSamples)
+	}
+	report.EntitySamples = entityExpected
+	if entityExpected > 0 {
+		report.EntityRecall = float64(entityHits) / float64(entityExpected)
+	}
+	return report
+}
+
+func truncate(values []string, k int) []string {
+	if k >= len(values) {
+		return values
+	}
+	return values[:k]
+}
+
+// JSONReport marshals results (and, if ks is non-empty, their Recall@K
+// metrics) as an indented JSON document suitable for CI artifact upload.
+func JSONReport(results []*ScenarioResult, ks []int) ([]byte, error) {
+	report := struct {
+		Scenarios []*ScenarioResult `json:"scenarios"`
+		Recall    []RecallAtK       `json:"recall,omitempty"`
+	}{
+		Scenarios: results,
+	}
+	if len(ks) > 0 {
+		report.Recall = ComputeRecallAtK(results, ks)
+	}
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// HTMLReport renders results (and, if ks is non-empty, their Recall@K
+// metrics) as a single self-contained HTML page: one row per scenario, with
+// failed turns' diffs listed underneath.
+func HTMLReport(results []*ScenarioResult, ks []int) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>flowtest report</title></head><body>\n")
+	b.WriteString("<h1>Conversation flow scenarios</h1>\n<table border=\"1\" cellpadding=\"4\">\n")
+	b.WriteString("<tr><th>Scenario</th><th>Status</th><th>Turns</th></tr>\n")
+
+	for _, scenario := range results {
+		status := "PASS"
+		if !scenario.Passed() {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%d</td></tr>\n",
+			html.EscapeString(scenario.Scenario), status, len(scenario.Turns))
+
+		for i, turn := range scenario.Turns {
+			if turn.Passed() {
+				continue
+			}
+			fmt.Fprintf(&b, "<tr><td colspan=\"3\"><b>turn %d</b> (%s):<ul>\n", i+1, html.EscapeString(turn.Turn.UserInput))
+			for _, diff := range turn.Diffs {
+				fmt.Fprintf(&b, "<li>%s</li>\n", html.EscapeString(diff))
+			}
+			b.WriteString("</ul></td></tr>\n")
+		}
+	}
+	b.WriteString("</table>\n")
+
+	if len(ks) > 0 {
+		b.WriteString("<h2>Recall@K</h2>\n<table border=\"1\" cellpadding=\"4\">\n")
+		b.WriteString("<tr><th>K</th><th>Intent recall</th><th>Entity recall</th></tr>\n")
+		for _, recall := range ComputeRecallAtK(results, ks) {
+			fmt.Fprintf(&b, "<tr><td>%d</td><td>%.2f (n=%d)</td><td>%.2f (n=%d)</td></tr>\n",
+				recall.K, recall.IntentRecall, recall.IntentSamples, recall.EntityRecall, recall.EntitySamples)
+		}
+		b.WriteString("</table>\n")
+	}
+
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
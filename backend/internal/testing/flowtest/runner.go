@@ -0,0 +1,227 @@
+package flowtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"innerworld-backend/internal/storage"
+	"innerworld-backend/internal/workflow"
+)
+
+// TurnResult is one Turn's outcome: what the pipeline actually produced,
+// alongside Diffs describing any assertion that didn't hold. A Turn with no
+// expectations set always has Diffs == nil.
+type TurnResult struct {
+	Turn       Turn
+	Reply      string
+	Extraction Extraction
+	Context    map[string]interface{}
+	Diffs      []string
+}
+
+// Passed reports whether every assertion on this turn held.
+func (r TurnResult) Passed() bool {
+	return len(r.Diffs) == 0
+}
+
+// ScenarioResult is a Scenario's outcome: one TurnResult per Turn, in order.
+type ScenarioResult struct {
+	Scenario string
+	Turns    []TurnResult
+}
+
+// Passed reports whether every turn in the scenario passed.
+func (r ScenarioResult) Passed() bool {
+	for _, turn := range r.Turns {
+		if !turn.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Runner replays Scenarios against a workflow.ConversationChain. The zero
+// value is not usable; construct one with NewRunner.
+type Runner struct {
+	chain     *workflow.ConversationChain
+	storage   storage.DynamoDBClient
+	extractor Extractor
+	golden    bool
+}
+
+// NewRunner builds a Runner around chain, reading back context mutations
+// from store (typically the same storage.DynamoDBClient chain was
+// constructed with, e.g. storage.NewMockDynamoDBClient()). It defaults to
+// KeywordExtractor; call SetExtractor to plug in something else.
+func NewRunner(chain *workflow.ConversationChain, store storage.DynamoDBClient) *Runner {
+	return &Runner{chain: chain, storage: store, extractor: KeywordExtractor{}}
+}
+
+// SetExtractor replaces the default KeywordExtractor.
+func (r *Runner) SetExtractor(extractor Extractor) {
+	r.extractor = extractor
+}
+
+// SetGolden puts the Runner into golden-capture mode: a turn with no
+// expectations set has its MatchIntent, MatchEntities, ExpectSubstrings,
+// and ExpectContext filled in from the pipeline's actual output instead of
+// being checked, so a caller can re-save the Scenario (SaveScenario) and
+// commit the captured expectations for future runs to assert against.
+func (r *Runner) SetGolden(golden bool) {
+	r.golden = golden
+}
+
+// RunScenario replays every turn in scenario in order against a single
+// session, so later turns see context earlier turns wrote to storage. It
+// mutates scenario's turns in place when the Runner is in golden mode.
+func (r *Runner) RunScenario(ctx context.Context, scenario *Scenario) (*ScenarioResult, error) {
+	userID := scenario.UserID
+	if userID == "" {
+		userID = "flowtest-" + slugify(scenario.Name)
+	}
+	sessionID := "flowtest-session-" + slugify(scenario.Name)
+
+	result := &ScenarioResult{Scenario: scenario.Name}
+	for i := range scenario.Turns {
+		turn := &scenario.Turns[i]
+
+		persona := turn.Persona
+		if persona == "" {
+			persona = scenario.Persona
+		}
+		if persona == "" {
+			persona = "default"
+		}
+
+		input := &workflow.ConversationInput{
+			UserMessage:  turn.UserInput,
+			Persona:      persona,
+			SessionID:    sessionID,
+			UserID:       userID,
+			SessionStart: time.Now(),
+		}
+
+		output, err := r.chain.ProcessConversation(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("flowtest: scenario %q turn %d (%q): %w", scenario.Name, i+1, turn.UserInput, err)
+		}
+
+		turnResult := TurnResult{
+			Turn:       *turn,
+			Reply:      output.LLMResponse,
+			Extraction: r.extractor.Extract(turn.UserInput),
+			Context:    r.loadContext(ctx, userID),
+		}
+
+		if r.golden && isEmpty(*turn) {
+			captureGolden(turn, turnResult)
+		} else {
+			turnResult.Diffs = assertTurn(*turn, turnResult)
+		}
+
+		result.Turns = append(result.Turns, turnResult)
+	}
+	return result, nil
+}
+
+// RunSuite replays every scenario and returns one ScenarioResult per
+// Scenario, in the same order.
+func (r *Runner) RunSuite(ctx context.Context, scenarios []*Scenario) ([]*ScenarioResult, error) {
+	results := make([]*ScenarioResult, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		result, err := r.RunScenario(ctx, scenario)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (r *Runner) loadContext(ctx context.Context, userID string) map[string]interface{} {
+	if r.storage == nil {
+		return nil
+	}
+	cached, err := r.storage.GetUserContext(ctx, userID)
+	if err != nil || cached == nil {
+		return nil
+	}
+	return cached.ContextData
+}
+
+// isEmpty reports whether turn has no expectations set, i.e. it's a
+// candidate for golden capture rather than assertion.
+func isEmpty(turn Turn) bool {
+	return turn.MatchIntent == "" && len(turn.MatchEntities) == 0 &&
+		len(turn.ExpectSubstrings) == 0 && len(turn.ExpectContext) == 0
+}
+
+// captureGolden fills turn's expectations in from result, for golden mode's
+// first-run capture.
+func captureGolden(turn *Turn, result TurnResult) {
+	if len(result.Extraction.Intents) > 0 {
+		turn.MatchIntent = result.Extraction.Intents[0]
+	}
+	turn.MatchEntities = result.Extraction.Entities
+	if result.Reply != "" {
+		turn.ExpectSubstrings = []string{result.Reply}
+	}
+	turn.ExpectContext = result.Context
+}
+
+// assertTurn checks result against expected's fields, returning one
+// human-readable diff per failed assertion.
+func assertTurn(expected Turn, result TurnResult) []string {
+	var diffs []string
+
+	if expected.MatchIntent != "" && !containsString(result.Extraction.Intents, expected.MatchIntent) {
+		diffs = append(diffs, fmt.Sprintf("intent: expected %q among %v", expected.MatchIntent, result.Extraction.Intents))
+	}
+	for _, entity := range expected.MatchEntities {
+		if !containsString(result.Extraction.Entities, entity) {
+			diffs = append(diffs, fmt.Sprintf("entity: expected %q among %v", entity, result.Extraction.Entities))
+		}
+	}
+	for _, substr := range expected.ExpectSubstrings {
+		if !strings.Contains(result.Reply, substr) {
+			diffs = append(diffs, fmt.Sprintf("reply: expected substring %q, got %q", substr, result.Reply))
+		}
+	}
+	for key, want := range expected.ExpectContext {
+		got, ok := result.Context[key]
+		if !ok || !reflect.DeepEqual(got, want) {
+			diffs = append(diffs, fmt.Sprintf("context[%s]: expected %v, got %v", key, want, got))
+		}
+	}
+	return diffs
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// slugify lowercases name and replaces runs of non-alphanumeric characters
+// with a single hyphen, for deriving deterministic user/session IDs from a
+// scenario name.
+func slugify(name string) string {
+	var b strings.Builder
+	lastHyphen := true
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			lastHyphen = false
+		} else if !lastHyphen {
+			b.WriteRune('-')
+			lastHyphen = true
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
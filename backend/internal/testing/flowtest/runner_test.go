@@ -0,0 +1,100 @@
+package flowtest
+
+import (
+	"context"
+	"testing"
+
+	"innerworld-backend/internal/personas"
+	"innerworld-backend/internal/storage"
+	"innerworld-backend/internal/workflow"
+)
+
+func newTestRunner() *Runner {
+	dynamoDB := storage.NewMockDynamoDBClient()
+	chain := workflow.NewConversationChain(personas.NewPersonaLoader(), nil, dynamoDB)
+	return NewRunner(chain, dynamoDB)
+}
+
+func TestRunScenarioPassesOnMatchingExpectations(t *testing.T) {
+	scenario := &Scenario{
+		Name: "test scenario",
+		Turns: []Turn{
+			{UserInput: "I'm anxious about school", MatchIntent: "anxiety", MatchEntities: []string{"school"}},
+		},
+	}
+
+	result, err := newTestRunner().RunScenario(context.Background(), scenario)
+	if err != nil {
+		t.Fatalf("RunScenario returned an error: %v", err)
+	}
+	if !result.Passed() {
+		t.Errorf("Expected scenario to pass, got diffs: %v", result.Turns[0].Diffs)
+	}
+}
+
+func TestRunScenarioFailsOnMismatchedIntent(t *testing.T) {
+	scenario := &Scenario{
+		Name: "test scenario",
+		Turns: []Turn{
+			{UserInput: "I'm anxious about school", MatchIntent: "gratitude"},
+		},
+	}
+
+	result, err := newTestRunner().RunScenario(context.Background(), scenario)
+	if err != nil {
+		t.Fatalf("RunScenario returned an error: %v", err)
+	}
+	if result.Passed() {
+		t.Error("Expected scenario to fail on mismatched intent")
+	}
+}
+
+func TestRunScenarioGoldenCapturesEmptyTurns(t *testing.T) {
+	scenario := &Scenario{
+		Name:  "golden scenario",
+		Turns: []Turn{{UserInput: "I'm anxious about school"}},
+	}
+
+	runner := newTestRunner()
+	runner.SetGolden(true)
+
+	result, err := runner.RunScenario(context.Background(), scenario)
+	if err != nil {
+		t.Fatalf("RunScenario returned an error: %v", err)
+	}
+	if !result.Passed() {
+		t.Errorf("Golden capture should never fail an empty turn, got diffs: %v", result.Turns[0].Diffs)
+	}
+	if scenario.Turns[0].MatchIntent != "anxiety" {
+		t.Errorf("Expected golden capture to set match_intent to anxiety, got %q", scenario.Turns[0].MatchIntent)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Basic Conversation!":  "basic-conversation",
+		"  leading/trailing  ": "leading-trailing",
+		"already-slug":         "already-slug",
+	}
+	for input, want := range cases {
+		if got := slugify(input); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestComputeRecallAtK(t *testing.T) {
+	results := []*ScenarioResult{
+		{Scenario: "s1", Turns: []TurnResult{
+			{Turn: Turn{MatchIntent: "anxiety"}, Extraction: Extraction{Intents: []string{"greeting", "anxiety"}}},
+		}},
+	}
+
+	reports := ComputeRecallAtK(results, []int{1, 2})
+	if reports[0].IntentRecall != 0 {
+		t.Errorf("Expected recall@1 to miss, got %f", reports[0].IntentRecall)
+	}
+	if reports[1].IntentRecall != 1 {
+		t.Errorf("Expected recall@2 to hit, got %f", reports[1].IntentRecall)
+	}
+}
@@ -0,0 +1,97 @@
+// Package flowtest is a declarative regression harness for
+// workflow.ConversationChain: scenarios describe a user's turns and what's
+// expected back, the Runner replays them against mock clients, and a Report
+// summarizes which scenarios passed. It exists so PRs that touch personas,
+// safety rules, or the LLM prompt can be gated on a checked-in scenario
+// suite instead of the ad-hoc prints cmd/test-e2e-conversation used to do.
+package flowtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Turn is one user message in a Scenario and the assertions to run against
+// the ConversationOutput it produces. Expected* fields are all optional -
+// an empty one is simply not checked - so a scenario can assert on only the
+// fields a particular PR cares about.
+type Turn struct {
+	UserInput string `yaml:"user_input" json:"user_input"`
+	// Persona overrides Scenario.Persona for this turn only.
+	Persona string `yaml:"persona,omitempty" json:"persona,omitempty"`
+
+	// MatchIntent and MatchEntities are checked against the configured
+	// Extractor's reading of UserInput, not against ConversationOutput -
+	// this repo has no real intent/entity classifier yet, so these
+	// assertions exercise the heuristic Extractor described in extractor.go
+	// rather than the production pipeline. Since only UserInput is read,
+	// persona or prompt changes do not affect them.
+	MatchIntent   string   `yaml:"match_intent,omitempty" json:"match_intent,omitempty"`
+	MatchEntities []string `yaml:"match_entities,omitempty" json:"match_entities,omitempty"`
+
+	// ExpectSubstrings are required substrings of ConversationOutput.LLMResponse.
+	ExpectSubstrings []string `yaml:"expect_substrings,omitempty" json:"expect_substrings,omitempty"`
+
+	// ExpectContext are key/value pairs the UserContextCacheItem.ContextData
+	// must contain (by deep equality) after the turn is processed.
+	ExpectContext map[string]interface{} `yaml:"expect_context,omitempty" json:"expect_context,omitempty"`
+}
+
+// Scenario is a checked-in conversation fixture: a sequence of Turns run in
+// order against the same session, so later turns see earlier ones' stored
+// context.
+type Scenario struct {
+	Name string `yaml:"name" json:"name"`
+	// Persona is the default for turns that don't set their own.
+	Persona string `yaml:"persona,omitempty" json:"persona,omitempty"`
+	// UserID seeds the session's UserContextCacheItem; defaults to a name
+	// derived from Name so golden runs are reproducible without a random ID.
+	UserID string `yaml:"user_id,omitempty" json:"user_id,omitempty"`
+	Turns  []Turn `yaml:"turns" json:"turns"`
+}
+
+// LoadScenario reads a Scenario from path, dispatching on its extension:
+// ".json" decodes as JSON, anything else (".yaml", ".yml") as YAML.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("flowtest: read scenario %s: %w", path, err)
+	}
+
+	var scenario Scenario
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(data, &scenario); err != nil {
+			return nil, fmt.Errorf("flowtest: parse scenario %s: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &scenario); err != nil {
+			return nil, fmt.Errorf("flowtest: parse scenario %s: %w", path, err)
+		}
+	}
+	return &scenario, nil
+}
+
+// SaveScenario writes scenario back to path in the same format LoadScenario
+// would read, for golden-capture mode to persist newly-recorded
+// expectations.
+func SaveScenario(path string, scenario *Scenario) error {
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".json") {
+		data, err = json.MarshalIndent(scenario, "", "  ")
+	} else {
+		data, err = yaml.Marshal(scenario)
+	}
+	if err != nil {
+		return fmt.Errorf("flowtest: encode scenario %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("flowtest: write scenario %s: %w", path, err)
+	}
+	return nil
+}
@@ -15,6 +15,44 @@ type ConversationResponse struct {
 	MessageID string    `json:"messageId"`
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
+	Timings   Timings   `json:"timings"`
+	LLMUsage  LLMUsage  `json:"llmUsage"`
+}
+
+// Timings mirrors workflow.ConversationOutput.Timings for the wire
+// response, broken down by pipeline stage, so the mobile client can show
+// per-stage "thinking..." progress instead of one opaque spinner while
+// waiting on the full response.
+type Timings struct {
+	InputSafetyMs  int64 `json:"inputSafetyMs,omitempty"`
+	ContextLoadMs  int64 `json:"contextLoadMs,omitempty"`
+	LLMMs          int64 `json:"llmMs,omitempty"`
+	OutputSafetyMs int64 `json:"outputSafetyMs,omitempty"`
+	StorageMs      int64 `json:"storageMs,omitempty"`
+	TotalMs        int64 `json:"totalMs,omitempty"`
+}
+
+// LLMUsage mirrors workflow.ConversationOutput.LLMUsage for the wire
+// response: the token counts and estimated spend for the completion that
+// produced Content, so operators can attribute spend per persona from
+// client-reported telemetry as well as from the metrics.Sink.
+type LLMUsage struct {
+	PromptTokens     int     `json:"promptTokens,omitempty"`
+	CompletionTokens int     `json:"completionTokens,omitempty"`
+	EstimatedCostUSD float64 `json:"estimatedCostUsd,omitempty"`
+}
+
+// ConversationChunk is one incremental delta of a streamed
+// ConversationResponse, sent as the LLM generates its reply instead of
+// waiting for the full completion. Done is true on the final chunk for a
+// message; its Delta is empty after a normal completion, and holds the whole
+// fallback message when a safety check or a generation error replaced the
+// reply.
+type ConversationChunk struct {
+	MessageID string    `json:"messageId"`
+	Delta     string    `json:"delta"`
+	Done      bool      `json:"done"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // Phase 2 Types for WebSocket and Session Management
@@ -36,6 +74,8 @@ type WebSocketResponse struct {
 	Timestamp   time.Time `json:"timestamp"`
 	SessionID   string    `json:"sessionId"`
 	MessageType string    `json:"messageType"` // "assistant"
+	Timings     Timings   `json:"timings"`
+	LLMUsage    LLMUsage  `json:"llmUsage"`
 }
 
 // LiveConversationItem represents a DynamoDB item in LiveConversations table
@@ -51,15 +91,29 @@ type LiveConversationItem struct {
 	TTL             int64     `json:"ttl" dynamodbav:"ttl"`               // 24-hour auto-cleanup timestamp
 	SessionID       string    `json:"session_id" dynamodbav:"session_id"` // GSI for session queries
 	MessageSequence int       `json:"message_sequence" dynamodbav:"message_sequence"`
+	Version         int64     `json:"version" dynamodbav:"version"` // optimistic-concurrency token, see storage.ErrVersionMismatch
 }
 
 // UserContextCacheItem represents cached S3 context in DynamoDB
 type UserContextCacheItem struct {
-	UserID         string                 `json:"user_id" dynamodbav:"user_id"`           // PK
-	ContextData    map[string]interface{} `json:"context_data" dynamodbav:"context_data"` // S3 GraphRAG context
-	LastUpdated    time.Time              `json:"last_updated" dynamodbav:"last_updated"`
-	LoginSessionID string                 `json:"login_session_id" dynamodbav:"login_session_id"`
-	TTL            int64                  `json:"ttl" dynamodbav:"ttl"` // 1-hour TTL, refreshed on use
+	UserID          string                 `json:"user_id" dynamodbav:"user_id"`           // PK
+	ContextData     map[string]interface{} `json:"context_data" dynamodbav:"context_data"` // S3 GraphRAG context
+	LastUpdated     time.Time              `json:"last_updated" dynamodbav:"last_updated"`
+	LoginSessionID  string                 `json:"login_session_id" dynamodbav:"login_session_id"`
+	TTL             int64                  `json:"ttl" dynamodbav:"ttl"`                           // 1-hour TTL, refreshed on use
+	ResourceVersion int64                  `json:"resource_version" dynamodbav:"resource_version"` // optimistic-concurrency token, see storage.ErrConflict/storage.GuaranteedUpdate
+}
+
+// WebSocketConnectionItem represents a DynamoDB item tracking a live
+// WebSocket connection, replacing the in-memory connectionID->userID map
+// that only one Lambda container could see.
+type WebSocketConnectionItem struct {
+	ConnectionID string    `json:"connection_id" dynamodbav:"connection_id"` // PK
+	UserID       string    `json:"user_id" dynamodbav:"user_id"`
+	SessionID    string    `json:"session_id" dynamodbav:"session_id"`
+	Persona      string    `json:"persona" dynamodbav:"persona"`
+	ConnectedAt  time.Time `json:"connected_at" dynamodbav:"connected_at"`
+	TTL          int64     `json:"ttl" dynamodbav:"ttl"` // 24-hour auto-cleanup timestamp
 }
 
 // SessionEndRequest represents session processing request
@@ -77,11 +131,32 @@ type LoginContextRequest struct {
 
 // ConversationElement represents extracted elements from conversation
 type ConversationElement struct {
-	Type       string                 `json:"type"`       // "Event", "Feeling", "Value", "Goal", "Habit"
-	Content    string                 `json:"content"`    // Description of the element
-	Metadata   map[string]interface{} `json:"metadata"`   // Additional context
-	Timestamp  time.Time              `json:"timestamp"`  // When it occurred in conversation
-	Confidence float64                `json:"confidence"` // Extraction confidence (0-1)
+	Type       string                 `json:"type" dynamodbav:"type"`             // "Event", "Feeling", "Value", "Goal", "Habit"
+	Content    string                 `json:"content" dynamodbav:"content"`       // Description of the element
+	Metadata   map[string]interface{} `json:"metadata" dynamodbav:"metadata"`     // Additional context
+	Timestamp  time.Time              `json:"timestamp" dynamodbav:"timestamp"`   // When it occurred in conversation
+	Confidence float64                `json:"confidence" dynamodbav:"confidence"` // Extraction confidence (0-1)
+}
+
+// ProcessingCheckpoint tracks handleSessionEndProcessing's progress through
+// its steps for a single session, so a Lambda retry or SQS dead-letter
+// re-drive resumes from the last durably committed step instead of
+// re-extracting elements or re-writing the graph.
+type ProcessingCheckpoint struct {
+	SessionID string `json:"session_id" dynamodbav:"session_id"` // PK
+	UserID    string `json:"user_id" dynamodbav:"user_id"`
+
+	ElementsExtracted bool                  `json:"elements_extracted" dynamodbav:"elements_extracted"`
+	Elements          []ConversationElement `json:"elements" dynamodbav:"elements"`
+
+	GraphCommitted    bool `json:"graph_committed" dynamodbav:"graph_committed"`
+	GraphNodesCreated int  `json:"graph_nodes_created" dynamodbav:"graph_nodes_created"`
+	GraphEdgesCreated int  `json:"graph_edges_created" dynamodbav:"graph_edges_created"`
+
+	MessagesCleanedUp bool `json:"messages_cleaned_up" dynamodbav:"messages_cleaned_up"`
+
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+	TTL       int64     `json:"ttl" dynamodbav:"ttl"` // 24-hour auto-cleanup, set on the checkpoint's first save
 }
 
 // SessionProcessingResult represents the outcome of session processing
@@ -2,6 +2,8 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -112,16 +114,19 @@ func TestConversationResponseJSON(t *testing.T) {
 }
 
 func TestConversationRequestValidation(t *testing.T) {
+	const validUserID = "1f9c7a2e-3b4d-4e5f-8a6b-7c8d9e0f1a2b"
+
 	testCases := []struct {
-		name    string
-		request ConversationRequest
-		valid   bool
+		name        string
+		request     ConversationRequest
+		valid       bool
+		expectField string
 	}{
 		{
 			name: "Valid request",
 			request: ConversationRequest{
 				Message: "Hello",
-				UserID:  "user-123",
+				UserID:  validUserID,
 			},
 			valid: true,
 		},
@@ -129,9 +134,10 @@ func TestConversationRequestValidation(t *testing.T) {
 			name: "Empty message",
 			request: ConversationRequest{
 				Message: "",
-				UserID:  "user-123",
+				UserID:  validUserID,
 			},
-			valid: false,
+			valid:       false,
+			expectField: "message",
 		},
 		{
 			name: "Empty UserID",
@@ -139,7 +145,26 @@ func TestConversationRequestValidation(t *testing.T) {
 				Message: "Hello",
 				UserID:  "",
 			},
-			valid: false,
+			valid:       false,
+			expectField: "userId",
+		},
+		{
+			name: "UserID not a UUID",
+			request: ConversationRequest{
+				Message: "Hello",
+				UserID:  "user-123",
+			},
+			valid:       false,
+			expectField: "userId",
+		},
+		{
+			name: "Message too long",
+			request: ConversationRequest{
+				Message: strings.Repeat("a", maxMessageLength+1),
+				UserID:  validUserID,
+			},
+			valid:       false,
+			expectField: "message",
 		},
 		{
 			name: "Both empty",
@@ -147,15 +172,27 @@ func TestConversationRequestValidation(t *testing.T) {
 				Message: "",
 				UserID:  "",
 			},
-			valid: false,
+			valid:       false,
+			expectField: "message",
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			isValid := tc.request.Message != "" && tc.request.UserID != ""
-			if isValid != tc.valid {
-				t.Errorf("Expected validation result %v, got %v for %s", tc.valid, isValid, tc.name)
+			err := tc.request.Validate()
+			if tc.valid {
+				if err != nil {
+					t.Errorf("Expected no validation error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if validationErr.Field != tc.expectField {
+				t.Errorf("Expected error on field %q, got %q", tc.expectField, validationErr.Field)
 			}
 		})
 	}
@@ -204,12 +241,67 @@ func TestConversationResponseValidation(t *testing.T) {
 			valid: false,
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			isValid := tc.response.MessageID != "" && tc.response.Content != "" && !tc.response.Timestamp.IsZero()
-			if isValid != tc.valid {
-				t.Errorf("Expected validation result %v, got %v for %s", tc.valid, isValid, tc.name)
+			err := tc.response.Validate()
+			if tc.valid != (err == nil) {
+				t.Errorf("Expected validation result %v, got error %v for %s", tc.valid, err, tc.name)
+			}
+		})
+	}
+}
+
+func TestLoginContextRequestValidation(t *testing.T) {
+	const validUserID = "1f9c7a2e-3b4d-4e5f-8a6b-7c8d9e0f1a2b"
+
+	testCases := []struct {
+		name        string
+		request     LoginContextRequest
+		valid       bool
+		expectField string
+	}{
+		{
+			name:    "Valid request",
+			request: LoginContextRequest{UserID: validUserID, LoginSessionID: "session-abc"},
+			valid:   true,
+		},
+		{
+			name:        "Empty UserID",
+			request:     LoginContextRequest{UserID: "", LoginSessionID: "session-abc"},
+			valid:       false,
+			expectField: "userId",
+		},
+		{
+			name:        "UserID not a UUID",
+			request:     LoginContextRequest{UserID: "not-a-uuid", LoginSessionID: "session-abc"},
+			valid:       false,
+			expectField: "userId",
+		},
+		{
+			name:        "Empty LoginSessionID",
+			request:     LoginContextRequest{UserID: validUserID, LoginSessionID: ""},
+			valid:       false,
+			expectField: "loginSessionId",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.request.Validate()
+			if tc.valid {
+				if err != nil {
+					t.Errorf("Expected no validation error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if validationErr.Field != tc.expectField {
+				t.Errorf("Expected error on field %q, got %q", tc.expectField, validationErr.Field)
 			}
 		})
 	}
@@ -0,0 +1,13 @@
+package types
+
+// EmbeddingCacheItem is a DynamoDB-persisted embedding, keyed on a hash of
+// the model and text that produced it (see
+// embeddings.CachingEmbeddingsClient), so a restarted Lambda container
+// doesn't lose a warm in-memory cache and pay to re-embed the same text.
+type EmbeddingCacheItem struct {
+	CacheKey  string    `json:"cache_key" dynamodbav:"cache_key"` // PK, sha256(model||text)
+	Model     string    `json:"model" dynamodbav:"model"`
+	Embedding []float32 `json:"embedding" dynamodbav:"embedding"`
+	Tokens    int       `json:"tokens" dynamodbav:"tokens"`
+	TTL       int64     `json:"ttl" dynamodbav:"ttl"`
+}
@@ -0,0 +1,14 @@
+package types
+
+// PromptStarterCacheItem caches a batch of generated prompt starters for a
+// (UserID, Persona, context) combination, so GET /prompt-starters can
+// re-render the same suggestions cheaply when a user reopens the chat
+// screen instead of calling the LLM every time. TTL keeps entries
+// short-lived since they go stale as soon as the user's context changes.
+type PromptStarterCacheItem struct {
+	CacheKey string   `json:"cache_key" dynamodbav:"cache_key"` // PK, sha256(userID||persona||json(userContext))
+	UserID   string   `json:"user_id" dynamodbav:"user_id"`
+	Persona  string   `json:"persona" dynamodbav:"persona"`
+	Starters []string `json:"starters" dynamodbav:"starters"`
+	TTL      int64    `json:"ttl" dynamodbav:"ttl"`
+}
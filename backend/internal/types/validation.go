@@ -0,0 +1,81 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// maxMessageLength bounds ConversationRequest.Message so a single
+// pathological payload can't blow up prompt size or downstream storage.
+const maxMessageLength = 4000
+
+// ValidationError reports the single field that failed validation, so
+// handlers can return a structured {code, field, message} envelope to API
+// clients instead of a bare 400 with no explanation.
+type ValidationError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+func fieldRequired(field string) *ValidationError {
+	return &ValidationError{Code: "required", Field: field, Message: field + " must not be empty"}
+}
+
+// Validate checks Message and UserID, returning the first failing field as
+// a *ValidationError. UserID must be a Cognito sub, which is always a UUID.
+func (r ConversationRequest) Validate() error {
+	if r.Message == "" {
+		return fieldRequired("message")
+	}
+	if len(r.Message) > maxMessageLength {
+		return &ValidationError{
+			Code:    "too_long",
+			Field:   "message",
+			Message: fmt.Sprintf("message must be at most %d characters", maxMessageLength),
+		}
+	}
+	if r.UserID == "" {
+		return fieldRequired("userId")
+	}
+	if _, err := uuid.Parse(r.UserID); err != nil {
+		return &ValidationError{Code: "invalid_format", Field: "userId", Message: "userId must be a Cognito sub UUID"}
+	}
+	return nil
+}
+
+// Validate checks that MessageID and Content are populated and Timestamp
+// was actually set, catching a handler that marshals a zero-value response
+// before the real one is ready.
+func (r ConversationResponse) Validate() error {
+	if r.MessageID == "" {
+		return fieldRequired("messageId")
+	}
+	if r.Content == "" {
+		return fieldRequired("content")
+	}
+	if r.Timestamp.IsZero() {
+		return &ValidationError{Code: "required", Field: "timestamp", Message: "timestamp must not be zero"}
+	}
+	return nil
+}
+
+// Validate checks UserID and LoginSessionID, the two fields
+// login-context-handler needs to cache GraphRAG context under.
+func (r LoginContextRequest) Validate() error {
+	if r.UserID == "" {
+		return fieldRequired("userId")
+	}
+	if _, err := uuid.Parse(r.UserID); err != nil {
+		return &ValidationError{Code: "invalid_format", Field: "userId", Message: "userId must be a Cognito sub UUID"}
+	}
+	if r.LoginSessionID == "" {
+		return fieldRequired("loginSessionId")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"innerworld-backend/internal/types"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi"
+	apigwtypes "github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi/types"
+)
+
+// ErrConnectionGone is returned by Publisher.Send when API Gateway reports
+// the connection no longer exists (client disconnected without a clean
+// $disconnect, or the connection outlived its own idle timeout). Callers
+// should evict the connection from the ConnectionStore when they see it.
+var ErrConnectionGone = errors.New("websocket connection is gone")
+
+// Publisher delivers a WebSocketResponse to a specific, already-connected
+// client.
+type Publisher interface {
+	Send(ctx context.Context, connectionID string, response types.WebSocketResponse) error
+}
+
+// APIGatewayPublisher delivers messages through the API Gateway Management
+// API for a single WebSocket API deployment (domainName/stage).
+type APIGatewayPublisher struct {
+	client *apigatewaymanagementapi.Client
+}
+
+// NewAPIGatewayPublisher builds a publisher targeting the management API
+// endpoint for the WebSocket API that invoked the handler, derived from
+// request.RequestContext.DomainName/Stage.
+func NewAPIGatewayPublisher(ctx context.Context, domainName, stage string) (*APIGatewayPublisher, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://%s/%s", domainName, stage)
+	client := apigatewaymanagementapi.NewFromConfig(cfg, func(o *apigatewaymanagementapi.Options) {
+		o.BaseEndpoint = &endpoint
+	})
+
+	return &APIGatewayPublisher{client: client}, nil
+}
+
+// Send delivers response to connectionID. If the connection is gone, it
+// returns ErrConnectionGone instead of the raw smithy error.
+func (p *APIGatewayPublisher) Send(ctx context.Context, connectionID string, response types.WebSocketResponse) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal websocket response: %w", err)
+	}
+
+	_, err = p.client.PostToConnection(ctx, &apigatewaymanagementapi.PostToConnectionInput{
+		ConnectionId: &connectionID,
+		Data:         body,
+	})
+	if err != nil {
+		var goneErr *apigwtypes.GoneException
+		if errors.As(err, &goneErr) {
+			return ErrConnectionGone
+		}
+		return fmt.Errorf("failed to post to connection %s: %w", connectionID, err)
+	}
+
+	return nil
+}
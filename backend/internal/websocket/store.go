@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"innerworld-backend/internal/types"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// connectionTTL mirrors the 24-hour auto-cleanup window used elsewhere in
+// the DynamoDB schema (see types.LiveConversationItem).
+const connectionTTL = 24 * time.Hour
+
+// Connection is a single WebSocket connection's persisted state.
+type Connection struct {
+	ConnectionID string
+	UserID       string
+	SessionID    string
+	Persona      string
+	ConnectedAt  time.Time
+}
+
+// ConnectionStore persists WebSocket connection state in a DynamoDB table
+// keyed by connectionID, so every Lambda container (and a cold-started one)
+// can see which user and session a connection belongs to, not just the
+// container that handled $connect.
+type ConnectionStore interface {
+	SaveConnection(ctx context.Context, conn *Connection) error
+	GetConnection(ctx context.Context, connectionID string) (*Connection, error)
+	UpdateSession(ctx context.Context, connectionID, sessionID, persona string) error
+	DeleteConnection(ctx context.Context, connectionID string) error
+}
+
+// DynamoDBConnectionStore is the ConnectionStore backed by a real DynamoDB
+// table with connectionID as the partition key.
+type DynamoDBConnectionStore struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+// NewDynamoDBConnectionStore creates a store against tableName using client.
+func NewDynamoDBConnectionStore(client *dynamodb.Client, tableName string) *DynamoDBConnectionStore {
+	return &DynamoDBConnectionStore{client: client, tableName: tableName}
+}
+
+// SaveConnection writes conn, setting a fresh TTL from conn.ConnectedAt.
+func (s *DynamoDBConnectionStore) SaveConnection(ctx context.Context, conn *Connection) error {
+	item := types.WebSocketConnectionItem{
+		ConnectionID: conn.ConnectionID,
+		UserID:       conn.UserID,
+		SessionID:    conn.SessionID,
+		Persona:      conn.Persona,
+		ConnectedAt:  conn.ConnectedAt,
+		TTL:          conn.ConnectedAt.Add(connectionTTL).Unix(),
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal connection item: %w", err)
+	}
+
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      av,
+	}); err != nil {
+		return fmt.Errorf("failed to save connection %s: %w", conn.ConnectionID, err)
+	}
+
+	return nil
+}
+
+// GetConnection returns the connection for connectionID, or (nil, nil) if
+// no row exists (the caller evicted it, or $connect never wrote one).
+func (s *DynamoDBConnectionStore) GetConnection(ctx context.Context, connectionID string) (*Connection, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       connectionKey(connectionID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection %s: %w", connectionID, err)
+	}
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var item types.WebSocketConnectionItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal connection item: %w", err)
+	}
+
+	return &Connection{
+		ConnectionID: item.ConnectionID,
+		UserID:       item.UserID,
+		SessionID:    item.SessionID,
+		Persona:      item.Persona,
+		ConnectedAt:  item.ConnectedAt,
+	}, nil
+}
+
+// UpdateSession records the session/persona the connection is currently
+// chatting with, so a later $disconnect knows which session to tear down.
+func (s *DynamoDBConnectionStore) UpdateSession(ctx context.Context, connectionID, sessionID, persona string) error {
+	if _, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(s.tableName),
+		Key:              connectionKey(connectionID),
+		UpdateExpression: aws.String("SET session_id = :sessionID, persona = :persona"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":sessionID": &dynamodbtypes.AttributeValueMemberS{Value: sessionID},
+			":persona":   &dynamodbtypes.AttributeValueMemberS{Value: persona},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to update session for connection %s: %w", connectionID, err)
+	}
+
+	return nil
+}
+
+// DeleteConnection removes connectionID's row, called on $disconnect and
+// whenever Publisher.Send reports the connection is gone.
+func (s *DynamoDBConnectionStore) DeleteConnection(ctx context.Context, connectionID string) error {
+	if _, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       connectionKey(connectionID),
+	}); err != nil {
+		return fmt.Errorf("failed to delete connection %s: %w", connectionID, err)
+	}
+
+	return nil
+}
+
+func connectionKey(connectionID string) map[string]dynamodbtypes.AttributeValue {
+	return map[string]dynamodbtypes.AttributeValue{
+		"connection_id": &dynamodbtypes.AttributeValueMemberS{Value: connectionID},
+	}
+}
@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/graph"
+)
+
+// defaultContextRetrieverTopK bounds how many candidate nodes
+// ContextRetriever asks Neptune for before applying the token budget.
+const defaultContextRetrieverTopK = 5
+
+// defaultContextTokenBudget is the default maximum size of the retrieved
+// context block, in estimated tokens. Kept small since it's injected
+// alongside the persona's own system prompt and boundaries.
+const defaultContextTokenBudget = 500
+
+// ContextRetriever builds the GraphRAG context block for a persona prompt:
+// it embeds the incoming user message, asks Neptune for the most
+// semantically relevant prior reflections/themes for that user, and trims
+// the result to a token budget so a chatty history doesn't crowd out the
+// persona's own instructions.
+type ContextRetriever struct {
+	neptuneClient graph.NeptuneClient
+	embeddings    embeddings.Provider
+	topK          int
+	tokenBudget   int
+}
+
+// NewContextRetriever creates a retriever with the default top-k and token
+// budget; use SetTopK/SetTokenBudget to override them. embeddingsClient may
+// be any embeddings.Provider, whichever config.EmbeddingsConfig selects.
+func NewContextRetriever(neptuneClient graph.NeptuneClient, embeddingsClient embeddings.Provider) *ContextRetriever {
+	return &ContextRetriever{
+		neptuneClient: neptuneClient,
+		embeddings:    embeddingsClient,
+		topK:          defaultContextRetrieverTopK,
+		tokenBudget:   defaultContextTokenBudget,
+	}
+}
+
+// SetTopK overrides how many candidate nodes are requested from Neptune.
+func (r *ContextRetriever) SetTopK(topK int) {
+	r.topK = topK
+}
+
+// SetTokenBudget overrides the maximum estimated size, in tokens, of the
+// returned context snippets combined.
+func (r *ContextRetriever) SetTokenBudget(tokenBudget int) {
+	r.tokenBudget = tokenBudget
+}
+
+// Retrieve embeds message, fetches the top-k semantically similar nodes for
+// userID (already ranked by Neptune with age-decay applied), and returns
+// their content as snippets, most-relevant-first, trimmed to the configured
+// token budget.
+func (r *ContextRetriever) Retrieve(ctx context.Context, userID, message string) ([]string, error) {
+	query, err := r.embeddings.GenerateEmbedding(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed message for context retrieval: %w", err)
+	}
+
+	nodes, err := r.neptuneClient.QuerySimilarNodes(ctx, userID, query.Embedding, r.topK, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query similar nodes: %w", err)
+	}
+
+	snippets := make([]string, 0, len(nodes))
+	remaining := r.tokenBudget
+	for _, node := range nodes {
+		cost := estimateTokens(node.Content)
+		if cost > remaining {
+			log.Printf("Context retrieval budget exhausted after %d snippets", len(snippets))
+			break
+		}
+		snippets = append(snippets, node.Content)
+		remaining -= cost
+	}
+
+	return snippets, nil
+}
+
+// estimateTokens approximates token count as one token per four characters,
+// the same rule of thumb OpenAI's own docs use, without pulling in a real
+// tokenizer for a budget check this coarse.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
@@ -0,0 +1,148 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"innerworld-backend/internal/embeddings"
+	"innerworld-backend/internal/graph"
+)
+
+var errEmbeddingUnavailable = errors.New("embeddings backend unavailable")
+
+// fakeRetrieverEmbedder is a minimal embeddings.Provider returning a fixed
+// vector per known text, so ContextRetriever's ranking is deterministic
+// instead of depending on a real embeddings backend.
+type fakeRetrieverEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (f *fakeRetrieverEmbedder) GenerateEmbedding(ctx context.Context, text string) (*embeddings.EmbeddingResult, error) {
+	vec, ok := f.vectors[text]
+	if !ok {
+		vec = []float32{0, 0, 1}
+	}
+	return &embeddings.EmbeddingResult{Text: text, Embedding: vec}, nil
+}
+
+func (f *fakeRetrieverEmbedder) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*embeddings.EmbeddingResult, error) {
+	results := make([]*embeddings.EmbeddingResult, len(texts))
+	for i, text := range texts {
+		result, err := f.GenerateEmbedding(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+func (f *fakeRetrieverEmbedder) Dimension() int { return 3 }
+func (f *fakeRetrieverEmbedder) Model() string  { return "fake-retriever-embedder" }
+
+func TestContextRetrieverReturnsMostSimilarFirst(t *testing.T) {
+	query := "I've been feeling anxious about school"
+	embedder := &fakeRetrieverEmbedder{vectors: map[string][]float32{
+		query:                  {1, 0, 0},
+		"close match":          {0.99, 0.1, 0},
+		"unrelated reflection": {0, 1, 0},
+	}}
+	neptune := graph.NewMockNeptuneClientWithEmbedder(embedder)
+	ctx := context.Background()
+
+	if err := neptune.CreateNode(ctx, "user-1", "reflection", "unrelated reflection"); err != nil {
+		t.Fatalf("unexpected error seeding node: %v", err)
+	}
+	if err := neptune.CreateNode(ctx, "user-1", "reflection", "close match"); err != nil {
+		t.Fatalf("unexpected error seeding node: %v", err)
+	}
+
+	retriever := NewContextRetriever(neptune, embedder)
+	snippets, err := retriever.Retrieve(ctx, "user-1", query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("expected both seeded nodes to be returned, got %d: %v", len(snippets), snippets)
+	}
+	if snippets[0] != "close match" {
+		t.Errorf("expected the closer match to rank first, got %q", snippets[0])
+	}
+}
+
+func TestContextRetrieverRespectsTokenBudget(t *testing.T) {
+	query := "anxious about school"
+	longSnippet := strings.Repeat("a", 4000)
+	embedder := &fakeRetrieverEmbedder{vectors: map[string][]float32{
+		query:       {1, 0, 0},
+		longSnippet: {1, 0, 0},
+	}}
+	neptune := graph.NewMockNeptuneClientWithEmbedder(embedder)
+	ctx := context.Background()
+
+	if err := neptune.CreateNode(ctx, "user-1", "reflection", longSnippet); err != nil {
+		t.Fatalf("unexpected error seeding node: %v", err)
+	}
+
+	retriever := NewContextRetriever(neptune, embedder)
+	retriever.SetTokenBudget(10)
+
+	snippets, err := retriever.Retrieve(ctx, "user-1", query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snippets) != 0 {
+		t.Fatalf("expected a snippet far over budget to be dropped, got %d", len(snippets))
+	}
+}
+
+func TestContextRetrieverSetTopKLimitsCandidates(t *testing.T) {
+	query := "anxious about school"
+	embedder := &fakeRetrieverEmbedder{vectors: map[string][]float32{query: {1, 0, 0}}}
+	neptune := graph.NewMockNeptuneClientWithEmbedder(embedder)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if err := neptune.CreateNode(ctx, "user-1", "reflection", "reflection content"); err != nil {
+			t.Fatalf("unexpected error seeding node: %v", err)
+		}
+	}
+
+	retriever := NewContextRetriever(neptune, embedder)
+	retriever.SetTopK(2)
+
+	snippets, err := retriever.Retrieve(ctx, "user-1", query)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("expected SetTopK to cap the candidates Neptune returns, got %d", len(snippets))
+	}
+}
+
+func TestContextRetrieverPropagatesEmbeddingError(t *testing.T) {
+	embedder := &erroringEmbedder{}
+	neptune := graph.NewMockNeptuneClient()
+	retriever := NewContextRetriever(neptune, embedder)
+
+	if _, err := retriever.Retrieve(context.Background(), "user-1", "anything"); err == nil {
+		t.Fatal("expected an embedding failure to surface as an error")
+	}
+}
+
+// erroringEmbedder always fails, for testing ContextRetriever's error path
+// without a real embeddings backend.
+type erroringEmbedder struct{}
+
+func (e *erroringEmbedder) GenerateEmbedding(ctx context.Context, text string) (*embeddings.EmbeddingResult, error) {
+	return nil, errEmbeddingUnavailable
+}
+
+func (e *erroringEmbedder) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([]*embeddings.EmbeddingResult, error) {
+	return nil, errEmbeddingUnavailable
+}
+
+func (e *erroringEmbedder) Dimension() int { return 3 }
+func (e *erroringEmbedder) Model() string  { return "erroring-embedder" }
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"innerworld-backend/internal/llm"
+	"innerworld-backend/internal/metrics"
 	"innerworld-backend/internal/personas"
+	"innerworld-backend/internal/safety"
 	"innerworld-backend/internal/storage"
 	"innerworld-backend/internal/types"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,8 +23,20 @@ import (
 // ConversationChain represents a LangChain-Go based conversation processor
 type ConversationChain struct {
 	personaLoader *personas.PersonaLoader
-	llmClient     *llm.OpenRouterClient
+	backendRouter *llm.BackendRouter
 	storage       storage.DynamoDBClient
+	moderator     safety.SafetyModerator
+	metricsSink   metrics.Sink
+	// contextRetriever is nil unless SetContextRetriever is called, in which
+	// case generatePersonaResponse injects retrieved GraphRAG context instead
+	// of the basic comma-joined theme summary.
+	contextRetriever *ContextRetriever
+	// safetyFilter is nil unless SetSafetyFilter is called, in which case
+	// generatePersonaResponse enforces its spend budget and redacts PII out
+	// of the user's message before it reaches backendRouter, rehydrating the
+	// original text back into the response. Without one, a call here sends
+	// raw conversation text straight to a third-party LLM provider.
+	safetyFilter *llm.SafetyFilter
 	// Note: LangChain chains will be fully implemented in Phase 3+
 }
 
@@ -35,6 +48,13 @@ type ConversationInput struct {
 	UserID       string                 `json:"user_id"`
 	UserContext  map[string]interface{} `json:"user_context"`
 	SessionStart time.Time              `json:"session_start"`
+	Region       string                 `json:"region,omitempty"`
+
+	// RetrievedContext, if non-empty, is used as the GraphRAG context block
+	// instead of calling contextRetriever, for callers that already have
+	// their own retrieval.Strategy wired up (e.g. cmd/conversation-handler's
+	// HybridRetriever) rather than a workflow.ContextRetriever.
+	RetrievedContext []string `json:"-"`
 }
 
 // ConversationOutput holds the result of conversation processing
@@ -44,61 +64,178 @@ type ConversationOutput struct {
 	ProcessedAt  time.Time `json:"processed_at"`
 	SafetyPassed bool      `json:"safety_passed"`
 	Error        string    `json:"error,omitempty"`
+	ModelID      string    `json:"model_id,omitempty"`
+	PersonaID    string    `json:"persona_id,omitempty"`
+
+	// Timings and LLMUsage are populated by ProcessConversation and handed
+	// to the configured metrics.Sink, so the mobile client can surface
+	// "thinking..." progress per stage and operators can budget token spend
+	// per persona. Fields are zero on paths that never reach that stage (e.g.
+	// a blocked input never reaches the LLM or storage step).
+	Timings  Timings  `json:"timings"`
+	LLMUsage LLMUsage `json:"llm_usage"`
+}
+
+// Timings breaks ConversationOutput.Timings.TotalMs down by pipeline stage.
+type Timings struct {
+	InputSafetyMs  int64 `json:"input_safety_ms,omitempty"`
+	ContextLoadMs  int64 `json:"context_load_ms,omitempty"`
+	LLMMs          int64 `json:"llm_ms,omitempty"`
+	OutputSafetyMs int64 `json:"output_safety_ms,omitempty"`
+	StorageMs      int64 `json:"storage_ms,omitempty"`
+	TotalMs        int64 `json:"total_ms,omitempty"`
 }
 
-// NewConversationChain creates a new LangChain-based conversation processor
+// LLMUsage carries the token counts and estimated spend for the completion
+// that produced ConversationOutput.LLMResponse.
+type LLMUsage struct {
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens int     `json:"completion_tokens,omitempty"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// ToWire converts t to the types.Timings a client-facing response carries,
+// so callers building a ConversationResponse/WebSocketResponse don't each
+// re-list every field.
+func (t Timings) ToWire() types.Timings {
+	return types.Timings{
+		InputSafetyMs:  t.InputSafetyMs,
+		ContextLoadMs:  t.ContextLoadMs,
+		LLMMs:          t.LLMMs,
+		OutputSafetyMs: t.OutputSafetyMs,
+		StorageMs:      t.StorageMs,
+		TotalMs:        t.TotalMs,
+	}
+}
+
+// ToWire converts u to the types.LLMUsage a client-facing response carries.
+func (u LLMUsage) ToWire() types.LLMUsage {
+	return types.LLMUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		EstimatedCostUSD: u.EstimatedCostUSD,
+	}
+}
+
+// NewConversationChain creates a new LangChain-based conversation processor.
+// llmClient becomes the sole backend in a single-entry BackendRouter; use
+// SetBackendRouter afterwards to wire up fallback providers (direct OpenAI,
+// Anthropic, a self-hosted gRPC backend) or per-persona backend pinning.
 func NewConversationChain(personaLoader *personas.PersonaLoader, llmClient *llm.OpenRouterClient, storage storage.DynamoDBClient) *ConversationChain {
+	var router *llm.BackendRouter
+	if llmClient != nil {
+		if personaLoader != nil {
+			personaLoader.SetLLMClient(llmClient)
+		}
+		router = llm.NewBackendRouter(llm.NewOpenRouterBackend(llmClient))
+	}
+
 	return &ConversationChain{
 		personaLoader: personaLoader,
-		llmClient:     llmClient,
+		backendRouter: router,
 		storage:       storage,
+		moderator:     safety.NewChainedModerator(safety.NewKeywordModerator()),
+		metricsSink:   metrics.NoopSink{},
 		// Note: LangChain sequential chains will be implemented in Phase 3+
 	}
 }
 
+// SetBackendRouter replaces the default single-backend router, e.g. with one
+// chaining OpenRouter, direct OpenAI, Anthropic, and a self-hosted gRPC
+// backend with persona pinning via BackendRouter.PinPersona.
+func (c *ConversationChain) SetBackendRouter(router *llm.BackendRouter) {
+	c.backendRouter = router
+}
+
+// SetSafetyModerator replaces the default keyword-only moderator, e.g. with a
+// ChainedModerator that also runs the OpenAI moderation API or embeddings
+// similarity layers.
+func (c *ConversationChain) SetSafetyModerator(moderator safety.SafetyModerator) {
+	c.moderator = moderator
+}
+
+// SetMetricsSink replaces the default no-op metrics sink, e.g. with a
+// metrics.CloudWatchEMFSink or metrics.PrometheusSink.
+func (c *ConversationChain) SetMetricsSink(sink metrics.Sink) {
+	c.metricsSink = sink
+}
+
+// SetContextRetriever configures GraphRAG context retrieval. Without one,
+// generatePersonaResponse falls back to FormatPersonaPrompt's basic
+// comma-joined theme summary.
+func (c *ConversationChain) SetContextRetriever(retriever *ContextRetriever) {
+	c.contextRetriever = retriever
+}
+
+// SetSafetyFilter enables per-user spend enforcement and PII redaction on
+// every backendRouter call generatePersonaResponse makes, the same
+// protection llm.SafetyFilter.Middleware gives a Router-based caller.
+func (c *ConversationChain) SetSafetyFilter(filter *llm.SafetyFilter) {
+	c.safetyFilter = filter
+}
+
 // ProcessConversation executes the LangChain workflow
 func (c *ConversationChain) ProcessConversation(ctx context.Context, input *ConversationInput) (*ConversationOutput, error) {
 	log.Printf("Starting LangChain conversation processing for session %s", input.SessionID)
 
 	messageID := "msg_" + uuid.New().String()[:8]
+	start := time.Now()
 
 	result := &ConversationOutput{
 		MessageID:   messageID,
-		ProcessedAt: time.Now(),
+		ProcessedAt: start,
+		PersonaID:   input.Persona,
 	}
+	defer func() {
+		result.Timings.TotalMs = time.Since(start).Milliseconds()
+		c.metricsSink.Record(ctx, conversationMetrics(result, input.SessionID))
+		metrics.ConversationRequestsTotal.Inc(result.PersonaID, requestStatus(result))
+	}()
 
 	// Step 1: Safety Check
-	safetyResult, err := c.performSafetyCheck(ctx, input.UserMessage)
+	safetyStart := time.Now()
+	inputVerdict, err := c.evaluateSafety(ctx, input.UserMessage, safety.DirectionInbound)
+	result.Timings.InputSafetyMs = time.Since(safetyStart).Milliseconds()
 	if err != nil {
 		result.Error = fmt.Sprintf("safety check failed: %v", err)
 		return result, err
 	}
 
-	if !safetyResult {
+	if !inputVerdict.Allowed {
+		metrics.SafetyCheckFailuresTotal.Inc("input")
 		result.SafetyPassed = false
-		result.LLMResponse = "I understand you might be going through a difficult time. Please consider talking to a trusted adult or calling 988 (Suicide & Crisis Lifeline) if you need immediate support."
+		result.LLMResponse = c.fallbackMessage(inputVerdict, input.Region)
 		return result, nil
 	}
 
 	result.SafetyPassed = true
 
 	// Step 2: Generate response using LangChain with persona
-	response, err := c.generatePersonaResponse(ctx, input)
+	response, stats, err := c.generatePersonaResponse(ctx, input)
+	result.Timings.ContextLoadMs = stats.ContextLoadMs
+	result.Timings.LLMMs = stats.LLMMs
+	result.ModelID = stats.ModelID
+	result.LLMUsage.PromptTokens = stats.PromptTokens
+	result.LLMUsage.CompletionTokens = stats.CompletionTokens
+	result.LLMUsage.EstimatedCostUSD = llm.EstimateCostUSD(stats.ModelID, stats.PromptTokens, stats.CompletionTokens)
 	if err != nil {
 		result.Error = fmt.Sprintf("response generation failed: %v", err)
 		// Fallback response
 		result.LLMResponse = "I'm here to listen and support you. Could you tell me a bit more about what's on your mind?"
 	} else {
 		// Step 2a: Safety check on AI response (outgoing message safety)
-		aiSafetyResult, err := c.performSafetyCheck(ctx, response)
+		outputSafetyStart := time.Now()
+		outputVerdict, err := c.evaluateSafety(ctx, response, safety.DirectionOutbound)
+		result.Timings.OutputSafetyMs = time.Since(outputSafetyStart).Milliseconds()
 		if err != nil {
 			log.Printf("AI response safety check failed: %v", err)
 			// Use safe fallback response
 			result.LLMResponse = "I'm here to support you. What would you like to talk about today?"
-		} else if !aiSafetyResult {
+		} else if !outputVerdict.Allowed {
 			log.Printf("AI response failed safety check - using safe fallback")
+			metrics.SafetyCheckFailuresTotal.Inc("output")
 			// AI generated unsafe content - use safe fallback instead of potentially harmful response
-			result.LLMResponse = "I want to support you in a safe and helpful way. Let's focus on something positive."
+			result.LLMResponse = c.fallbackMessage(outputVerdict, input.Region)
 		} else {
 			// AI response passed safety check
 			result.LLMResponse = response
@@ -106,74 +243,164 @@ func (c *ConversationChain) ProcessConversation(ctx context.Context, input *Conv
 	}
 
 	// Step 3: Store conversation in DynamoDB
+	storageStart := time.Now()
 	if err := c.storeConversation(ctx, input, result); err != nil {
 		log.Printf("Failed to store conversation: %v", err)
 		// Don't fail the entire operation if storage fails
 	}
+	result.Timings.StorageMs = time.Since(storageStart).Milliseconds()
 
 	log.Printf("Completed LangChain conversation processing for session %s", input.SessionID)
 	return result, nil
 }
 
-// performSafetyCheck implements basic safety moderation for both incoming and outgoing messages
-func (c *ConversationChain) performSafetyCheck(ctx context.Context, message string) (bool, error) {
-	log.Printf("Performing safety check on message")
+// requestStatus classifies result for ConversationRequestsTotal's "status"
+// label: "error" if the step that ran returned one, "blocked" if the input
+// never reached the LLM because a safety check rejected it, "ok" otherwise.
+func requestStatus(result *ConversationOutput) string {
+	if result.Error != "" {
+		return "error"
+	}
+	if !result.SafetyPassed {
+		return "blocked"
+	}
+	return "ok"
+}
 
-	// Basic keyword-based safety check (Phase 2 implementation)
-	// Used for both user inputs and AI responses
-	message = strings.ToLower(message)
+// conversationMetrics builds the metrics.ConversationMetrics snapshot
+// recorded for every ProcessConversation call, success or failure.
+func conversationMetrics(result *ConversationOutput, sessionID string) metrics.ConversationMetrics {
+	return metrics.ConversationMetrics{
+		SessionID:        sessionID,
+		PersonaID:        result.PersonaID,
+		ModelID:          result.ModelID,
+		SafetyCheckMs:    result.Timings.InputSafetyMs,
+		ContextLoadMs:    result.Timings.ContextLoadMs,
+		LLMLatencyMs:     result.Timings.LLMMs,
+		OutputSafetyMs:   result.Timings.OutputSafetyMs,
+		StorageLatencyMs: result.Timings.StorageMs,
+		TotalMs:          result.Timings.TotalMs,
+		PromptTokens:     result.LLMUsage.PromptTokens,
+		CompletionTokens: result.LLMUsage.CompletionTokens,
+		EstimatedCostUSD: result.LLMUsage.EstimatedCostUSD,
+		SafetyPassed:     result.SafetyPassed,
+	}
+}
 
-	harmfulKeywords := []string{
-		"kill myself", "end it all", "hurt myself", "self harm",
-		"suicide", "die", "cutting", "overdose",
+// evaluateSafety runs the configured SafetyModerator over message and
+// returns its full verdict, so callers that need the recommended action or
+// detected category (to pick a fallback message) don't have to re-derive it.
+func (c *ConversationChain) evaluateSafety(ctx context.Context, message string, direction safety.Direction) (safety.SafetyVerdict, error) {
+	log.Printf("Performing safety check on message")
+
+	verdict, err := c.moderator.Evaluate(ctx, message, direction)
+	if err != nil {
+		return safety.SafetyVerdict{}, err
 	}
 
-	for _, keyword := range harmfulKeywords {
-		if strings.Contains(message, keyword) {
-			log.Printf("Safety concern detected: keyword '%s' found", keyword)
-			return false, nil
-		}
+	if !verdict.Allowed {
+		log.Printf("Safety concern detected: %s (%s)", verdict.Category, verdict.Reason)
 	}
 
-	if len(strings.TrimSpace(message)) == 0 {
-		return false, fmt.Errorf("empty message")
+	return verdict, nil
+}
+
+// performSafetyCheck is a convenience wrapper around evaluateSafety for
+// callers that only care whether the message is allowed.
+func (c *ConversationChain) performSafetyCheck(ctx context.Context, message string, direction safety.Direction) (bool, error) {
+	verdict, err := c.evaluateSafety(ctx, message, direction)
+	if err != nil {
+		return false, err
 	}
+	return verdict.Allowed, nil
+}
 
-	if len(message) > 2000 {
-		return false, fmt.Errorf("message too long")
+// fallbackMessage picks the supportive response shown in place of a blocked
+// message, naming a region-appropriate crisis resource when the verdict
+// recommends one.
+func (c *ConversationChain) fallbackMessage(verdict safety.SafetyVerdict, region string) string {
+	if verdict.Action == safety.ActionCrisisResource {
+		return "I understand you might be going through a difficult time. " + safety.CrisisResourceMessage(region)
 	}
+	return "I want to support you in a safe and helpful way. Let's focus on something positive."
+}
 
-	return true, nil
+// generationStats carries the usage/cost data generatePersonaResponse reads
+// off the LLM response, plus how long context retrieval and the LLM call
+// themselves took, so callers that need it for telemetry don't have to
+// re-parse a *llm.ChatResponse or re-time these steps themselves.
+type generationStats struct {
+	ModelID          string
+	PromptTokens     int
+	CompletionTokens int
+	ContextLoadMs    int64
+	LLMMs            int64
 }
 
 // generatePersonaResponse creates a response using LangChain with persona context
-func (c *ConversationChain) generatePersonaResponse(ctx context.Context, input *ConversationInput) (string, error) {
+func (c *ConversationChain) generatePersonaResponse(ctx context.Context, input *ConversationInput) (string, generationStats, error) {
 	log.Printf("Generating response using LangChain for persona: %s", input.Persona)
 
-	// Get persona configuration (for Phase 3+ LangChain prompt templates)
-	_, err := c.personaLoader.FormatPersonaPrompt(input.Persona, input.UserContext)
+	var stats generationStats
+
+	retrievedContext := input.RetrievedContext
+	if len(retrievedContext) == 0 && c.contextRetriever != nil {
+		contextLoadStart := time.Now()
+		var err error
+		retrievedContext, err = c.contextRetriever.Retrieve(ctx, input.UserID, input.UserMessage)
+		stats.ContextLoadMs = time.Since(contextLoadStart).Milliseconds()
+		if err != nil {
+			log.Printf("GraphRAG context retrieval failed, falling back to basic context: %v", err)
+		}
+	}
+
+	systemPrompt, err := c.personaLoader.FormatPersonaPromptWithContext(input.Persona, input.UserContext, retrievedContext)
 	if err != nil {
-		return "", fmt.Errorf("failed to load persona: %w", err)
+		return "", stats, fmt.Errorf("failed to load persona: %w", err)
 	}
 
-	// If OpenRouter client is not available, use mock response
-	if c.llmClient == nil {
-		return fmt.Sprintf("I hear you. (Mock LangChain response from %s persona - OpenRouter not configured)", input.Persona), nil
+	// If no backend is configured, use mock response
+	if c.backendRouter == nil {
+		mock := fmt.Sprintf("I hear you. (Mock LangChain response from %s persona - no LLM backend configured)", input.Persona)
+		stats.ModelID = "mock"
+		return mock, stats, nil
 	}
 
 	// Note: LangChain prompt templates will be implemented in Phase 3+
 
-	// Use existing OpenRouter client through LangChain-compatible interface
-	response, err := c.llmClient.GenerateResponse(ctx, input.UserMessage)
+	userMessage := input.UserMessage
+	var redactionMap llm.RedactionMap
+	if c.safetyFilter != nil {
+		if err := c.safetyFilter.CheckBudget(input.UserID); err != nil {
+			return "", stats, fmt.Errorf("safety filter blocked request: %w", err)
+		}
+		userMessage, redactionMap = c.safetyFilter.RedactUserMessage(userMessage)
+	}
+
+	llmStart := time.Now()
+	response, _, err := c.backendRouter.Generate(ctx, input.Persona, systemPrompt, userMessage)
+	stats.LLMMs = time.Since(llmStart).Milliseconds()
 	if err != nil {
-		return "", fmt.Errorf("LLM generation failed: %w", err)
+		return "", stats, fmt.Errorf("LLM generation failed: %w", err)
+	}
+
+	stats.ModelID = response.Model
+	stats.PromptTokens = response.Usage.PromptTokens
+	stats.CompletionTokens = response.Usage.CompletionTokens
+
+	if c.safetyFilter != nil {
+		c.safetyFilter.RecordUsage(input.UserID, response.Usage)
 	}
 
 	if len(response.Choices) > 0 {
-		return response.Choices[0].Message.Content, nil
+		content := response.Choices[0].Message.Content
+		if c.safetyFilter != nil {
+			content = llm.Rehydrate(content, redactionMap)
+		}
+		return content, stats, nil
 	}
 
-	return "I'm here for you. What would you like to talk about?", nil
+	return "I'm here for you. What would you like to talk about?", stats, nil
 }
 
 // storeConversation stores both user message and AI response in DynamoDB
@@ -195,7 +422,7 @@ func (c *ConversationChain) storeConversation(ctx context.Context, input *Conver
 		SessionID:      input.SessionID,
 	}
 
-	if err := c.storage.StoreMessage(ctx, userMessage); err != nil {
+	if err := c.storage.StoreMessage(ctx, userMessage, 0); err != nil {
 		return fmt.Errorf("failed to store user message: %w", err)
 	}
 
@@ -212,7 +439,7 @@ func (c *ConversationChain) storeConversation(ctx context.Context, input *Conver
 		SessionID:      input.SessionID,
 	}
 
-	if err := c.storage.StoreMessage(ctx, aiMessage); err != nil {
+	if err := c.storage.StoreMessage(ctx, aiMessage, 0); err != nil {
 		return fmt.Errorf("failed to store AI message: %w", err)
 	}
 
@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"innerworld-backend/internal/safety"
+	"innerworld-backend/internal/types"
+)
+
+// defaultPromptStarterLimit mirrors PersonaLoader's own default so callers
+// that don't set an explicit limit still get a reasonable chip row.
+const defaultPromptStarterLimit = 5
+
+// GetPromptStarters returns deduplicated, safety-checked opener prompts for
+// persona, so the mobile app can show a "what should I say?" chip row when a
+// teen opens a new session. It generates candidates via PersonaLoader, then
+// drops duplicates and anything that fails the same outbound safety check
+// used for regular AI responses. Results are cached in storage keyed by
+// (userID, persona, a hash of userContext) for the 15-minute TTL that
+// SavePromptStarterCache gives an entry, so reopening the chat screen with
+// an unchanged context doesn't re-run
+// generation and safety checks.
+func (c *ConversationChain) GetPromptStarters(ctx context.Context, userID, personaName string, userContext map[string]interface{}, limit int) ([]string, error) {
+	if limit <= 0 {
+		limit = defaultPromptStarterLimit
+	}
+
+	cacheKey := promptStarterCacheKey(userID, personaName, userContext)
+	if c.storage != nil {
+		if cached, err := c.storage.GetPromptStarterCache(ctx, cacheKey); err != nil {
+			log.Printf("Prompt starter cache lookup failed, generating fresh: %v", err)
+		} else if cached != nil && len(cached.Starters) >= limit {
+			return cached.Starters[:limit], nil
+		}
+	}
+
+	candidates, err := c.personaLoader.GetPromptStarters(ctx, personaName, userContext, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate prompt starters: %w", err)
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	starters := make([]string, 0, limit)
+
+	for _, starter := range candidates {
+		key := strings.ToLower(starter)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		safe, err := c.performSafetyCheck(ctx, starter, safety.DirectionOutbound)
+		if err != nil || !safe {
+			log.Printf("Dropping unsafe prompt starter candidate: %q", starter)
+			continue
+		}
+
+		starters = append(starters, starter)
+		if len(starters) >= limit {
+			break
+		}
+	}
+
+	if c.storage != nil {
+		cacheItem := &types.PromptStarterCacheItem{
+			CacheKey: cacheKey,
+			UserID:   userID,
+			Persona:  personaName,
+			Starters: starters,
+		}
+		if err := c.storage.SavePromptStarterCache(ctx, cacheItem); err != nil {
+			log.Printf("Failed to cache prompt starters for user %s: %v", userID, err)
+		}
+	}
+
+	return starters, nil
+}
+
+// promptStarterCacheKey hashes userID, persona, and userContext into the
+// cache key GetPromptStarters looks its cache up by, mirroring
+// embeddings.CacheKey's fixed-length SHA-256 approach. userContext is
+// marshaled with json.Marshal, whose deterministic key ordering for Go maps
+// (sorted alphabetically) means the same context always hashes the same way.
+func promptStarterCacheKey(userID, persona string, userContext map[string]interface{}) string {
+	contextJSON, err := json.Marshal(userContext)
+	if err != nil {
+		contextJSON = []byte(fmt.Sprintf("%v", userContext))
+	}
+	sum := sha256.Sum256([]byte(userID + "||" + persona + "||" + string(contextJSON)))
+	return hex.EncodeToString(sum[:])
+}
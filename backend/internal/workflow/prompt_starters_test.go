@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"innerworld-backend/internal/types"
+)
+
+// TestGetPromptStartersReturnsCachedWithoutGenerating covers the caching
+// fast path: a cached entry with at least `limit` starters is returned as-is
+// without calling into PersonaLoader.GetPromptStarters at all, which would
+// otherwise require an LLM client to be configured.
+func TestGetPromptStartersReturnsCachedWithoutGenerating(t *testing.T) {
+	chain, mockStorage := newTestChain(nil, nil)
+	ctx := context.Background()
+
+	cacheKey := promptStarterCacheKey("user-1", "default", nil)
+	cached := &types.PromptStarterCacheItem{
+		CacheKey: cacheKey,
+		UserID:   "user-1",
+		Persona:  "default",
+		Starters: []string{"What's on your mind today?", "How has school been lately?"},
+	}
+	if err := mockStorage.SavePromptStarterCache(ctx, cached); err != nil {
+		t.Fatalf("unexpected error seeding cache: %v", err)
+	}
+
+	starters, err := chain.GetPromptStarters(ctx, "user-1", "default", nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(starters) != 2 || starters[0] != cached.Starters[0] {
+		t.Fatalf("expected the cached starters to be returned unchanged, got %v", starters)
+	}
+}
+
+// TestGetPromptStartersSurfacesGenerationError covers the miss path: without
+// a cache hit, generation falls through to PersonaLoader.GetPromptStarters,
+// which requires an LLM client - without one configured it must return an
+// error rather than silently producing an empty chip row.
+func TestGetPromptStartersSurfacesGenerationError(t *testing.T) {
+	chain, _ := newTestChain(nil, nil)
+
+	if _, err := chain.GetPromptStarters(context.Background(), "user-2", "default", nil, 3); err == nil {
+		t.Fatal("expected an error when no LLM client is configured for prompt starter generation")
+	}
+}
+
+// TestPromptStarterCacheKeyIsDeterministic asserts the same inputs always
+// hash to the same key (so a repeat request for an unchanged context is a
+// cache hit) and that a different userID changes the key (so two users'
+// starters never collide).
+func TestPromptStarterCacheKeyIsDeterministic(t *testing.T) {
+	ctx1 := map[string]interface{}{"recent_themes": []interface{}{"school", "friends"}}
+	ctx2 := map[string]interface{}{"recent_themes": []interface{}{"school", "friends"}}
+
+	keyA := promptStarterCacheKey("user-1", "default", ctx1)
+	keyB := promptStarterCacheKey("user-1", "default", ctx2)
+	if keyA != keyB {
+		t.Errorf("expected identical inputs to produce the same cache key, got %q and %q", keyA, keyB)
+	}
+
+	keyC := promptStarterCacheKey("user-2", "default", ctx1)
+	if keyA == keyC {
+		t.Error("expected a different userID to change the cache key")
+	}
+}
@@ -0,0 +1,280 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"innerworld-backend/internal/safety"
+)
+
+// ConversationChunk is a single piece of a streamed conversation response,
+// emitted incrementally by ProcessConversationStream as tokens arrive.
+type ConversationChunk struct {
+	MessageID     string `json:"message_id"`
+	Delta         string `json:"delta"`
+	Done          bool   `json:"done"`
+	SafetyBlocked bool   `json:"safety_blocked,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// streamHeartbeatInterval controls how often a comment-only SSE heartbeat is
+// sent while waiting on the LLM, so proxies don't time out an idle connection.
+const streamHeartbeatInterval = 15 * time.Second
+
+// Token is a single increment of a streamed assistant response, in the
+// vocabulary the WebSocket handler's WebSocketResponse frames use
+// ("assistant_delta" per Content, a terminal "assistant_done" carrying
+// MessageID). It carries a ConversationChunk's Delta, Done and MessageID;
+// StreamConversation adapts one to the other, dropping SafetyBlocked and
+// Error, so WebSocket callers don't have to know about
+// ConversationChunk/SafetyBlocked at all.
+type Token struct {
+	Content   string
+	Done      bool
+	MessageID string
+}
+
+// StreamConversation is ProcessConversationStream adapted to a chan<- Token,
+// for the WebSocket handler: it fans deltas to tokens as they arrive and
+// closes tokens once the final, Done-true frame has been sent.
+func (c *ConversationChain) StreamConversation(ctx context.Context, input *ConversationInput, tokens chan<- Token) error {
+	defer close(tokens)
+
+	chunks, err := c.ProcessConversationStream(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	for chunk := range chunks {
+		select {
+		case tokens <- Token{Content: chunk.Delta, Done: chunk.Done, MessageID: chunk.MessageID}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if chunk.Done {
+			break
+		}
+	}
+
+	return nil
+}
+
+// ProcessConversationStream is a streaming variant of ProcessConversation
+// that emits the AI response as it's generated instead of waiting for the
+// full completion, cutting the latency a teen watching a blank screen sees.
+//
+// Safety scanning runs incrementally against the buffer accumulated so far;
+// if it trips mid-stream, ProcessConversationStream stops emitting deltas
+// and sends a single safety-blocked chunk with a fallback message instead.
+// The assembled conversation is stored in DynamoDB once, when the stream
+// closes, mirroring ProcessConversation's single write at the end. A stream
+// cut short by ctx cancellation is not stored.
+func (c *ConversationChain) ProcessConversationStream(ctx context.Context, input *ConversationInput) (<-chan ConversationChunk, error) {
+	log.Printf("Starting streaming conversation processing for session %s", input.SessionID)
+
+	messageID := "msg_" + uuid.New().String()[:8]
+
+	inputVerdict, err := c.evaluateSafety(ctx, input.UserMessage, safety.DirectionInbound)
+	if err != nil {
+		return nil, fmt.Errorf("safety check failed: %w", err)
+	}
+
+	chunks := make(chan ConversationChunk)
+
+	if !inputVerdict.Allowed {
+		go func() {
+			defer close(chunks)
+			fallback := c.fallbackMessage(inputVerdict, input.Region)
+			chunks <- ConversationChunk{MessageID: messageID, Delta: fallback, SafetyBlocked: true, Done: true}
+			c.storeStreamedConversation(context.Background(), input, messageID, fallback)
+		}()
+		return chunks, nil
+	}
+
+	go c.streamPersonaResponse(ctx, input, messageID, chunks)
+
+	return chunks, nil
+}
+
+// streamPersonaResponse formats the persona system prompt, then forwards the
+// backend's content deltas to chunks as they arrive from
+// BackendRouter.GenerateStream (a real OpenRouter SSE stream, when
+// OpenRouterBackend is in play), running the outbound safety check on the
+// accumulated buffer after every delta. Without a configured backend, it
+// falls back to streaming the same mock response generatePersonaResponse
+// would have returned whole, word by word.
+func (c *ConversationChain) streamPersonaResponse(ctx context.Context, input *ConversationInput, messageID string, chunks chan<- ConversationChunk) {
+	defer close(chunks)
+
+	deltas, err := c.openDeltaStream(ctx, input)
+	if err != nil {
+		log.Printf("Streaming response generation failed: %v", err)
+		fallback := "I'm here to listen and support you. Could you tell me a bit more about what's on your mind?"
+		chunks <- ConversationChunk{MessageID: messageID, Delta: fallback, Done: true}
+		c.storeStreamedConversation(context.Background(), input, messageID, fallback)
+		return
+	}
+
+	var buffer strings.Builder
+
+	for delta := range deltas {
+		buffer.WriteString(delta)
+
+		outputVerdict, err := c.evaluateSafety(ctx, buffer.String(), safety.DirectionOutbound)
+		if err != nil || !outputVerdict.Allowed {
+			log.Printf("Outbound safety check tripped mid-stream for session %s", input.SessionID)
+			fallback := c.fallbackMessage(outputVerdict, input.Region)
+			chunks <- ConversationChunk{MessageID: messageID, Delta: fallback, SafetyBlocked: true, Done: true}
+			c.storeStreamedConversation(context.Background(), input, messageID, fallback)
+			return
+		}
+
+		select {
+		case chunks <- ConversationChunk{MessageID: messageID, Delta: delta}:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	chunks <- ConversationChunk{MessageID: messageID, Done: true}
+	c.storeStreamedConversation(context.Background(), input, messageID, buffer.String())
+}
+
+// openDeltaStream formats the persona system prompt and opens the backend's
+// content-delta stream, or a word-by-word replay of the mock response when
+// no backend is configured.
+func (c *ConversationChain) openDeltaStream(ctx context.Context, input *ConversationInput) (<-chan string, error) {
+	retrievedContext := input.RetrievedContext
+	if len(retrievedContext) == 0 && c.contextRetriever != nil {
+		var err error
+		retrievedContext, err = c.contextRetriever.Retrieve(ctx, input.UserID, input.UserMessage)
+		if err != nil {
+			log.Printf("GraphRAG context retrieval failed, falling back to basic context: %v", err)
+		}
+	}
+
+	systemPrompt, err := c.personaLoader.FormatPersonaPromptWithContext(input.Persona, input.UserContext, retrievedContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load persona: %w", err)
+	}
+
+	if c.backendRouter == nil {
+		mock := fmt.Sprintf("I hear you. (Mock LangChain response from %s persona - no LLM backend configured)", input.Persona)
+		deltas := make(chan string)
+		go func() {
+			defer close(deltas)
+			for _, word := range strings.Fields(mock) {
+				select {
+				case deltas <- word + " ":
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return deltas, nil
+	}
+
+	userMessage := input.UserMessage
+	if c.safetyFilter != nil {
+		if err := c.safetyFilter.CheckBudget(input.UserID); err != nil {
+			return nil, fmt.Errorf("safety filter blocked request: %w", err)
+		}
+		// Redact PII out of the outbound message same as generatePersonaResponse,
+		// but deltas aren't rehydrated on the way back: a streamed response
+		// arrives token-by-token, so a placeholder the model echoed could
+		// straddle two deltas and there's no single ChatResponse to rehydrate
+		// in one pass.
+		userMessage, _ = c.safetyFilter.RedactUserMessage(userMessage)
+	}
+
+	deltas, _, err := c.backendRouter.GenerateStream(ctx, input.Persona, systemPrompt, userMessage)
+	if err != nil {
+		return nil, fmt.Errorf("LLM streaming generation failed: %w", err)
+	}
+
+	return deltas, nil
+}
+
+// storeStreamedConversation persists the assembled conversation once the
+// stream has closed, reusing storeConversation's best-effort semantics -
+// a storage failure is logged but doesn't affect what the client already saw.
+func (c *ConversationChain) storeStreamedConversation(ctx context.Context, input *ConversationInput, messageID, content string) {
+	result := &ConversationOutput{
+		MessageID:    messageID,
+		LLMResponse:  content,
+		ProcessedAt:  time.Now(),
+		SafetyPassed: true,
+	}
+	if err := c.storeConversation(ctx, input, result); err != nil {
+		log.Printf("Failed to store streamed conversation: %v", err)
+	}
+}
+
+// ServeConversationStream adapts ProcessConversationStream to an HTTP
+// handler that frames each chunk as a server-sent event (`data: {...}\n\n`),
+// with periodic heartbeat comments so proxies don't close an idle
+// connection, and an `event: safety_block` line when the outbound safety
+// check forces a fallback.
+func (c *ConversationChain) ServeConversationStream(w http.ResponseWriter, r *http.Request, input *ConversationInput) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	chunks, err := c.ProcessConversationStream(r.Context(), input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return
+			}
+			writeSSEChunk(w, chunk)
+			flusher.Flush()
+			if chunk.Done {
+				return
+			}
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSEChunk writes a single ConversationChunk in SSE wire format.
+func writeSSEChunk(w http.ResponseWriter, chunk ConversationChunk) {
+	payload, err := json.Marshal(chunk)
+	if err != nil {
+		log.Printf("Failed to marshal SSE chunk: %v", err)
+		return
+	}
+
+	if chunk.SafetyBlocked {
+		fmt.Fprint(w, "event: safety_block\n")
+	}
+	fmt.Fprintf(w, "data: %s\n\n", payload)
+}
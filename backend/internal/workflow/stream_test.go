@@ -0,0 +1,143 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"innerworld-backend/internal/safety"
+)
+
+// drainChunks collects every ConversationChunk a stream emits, failing the
+// test if it doesn't close within a generous timeout.
+func drainChunks(t *testing.T, chunks <-chan ConversationChunk) []ConversationChunk {
+	t.Helper()
+	var collected []ConversationChunk
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, chunk)
+		case <-timeout:
+			t.Fatal("timed out waiting for stream to close")
+		}
+	}
+}
+
+// TestProcessConversationStreamBlockedInputNeverReachesBackend is the
+// streaming counterpart to the non-streaming safety-gating test: a blocked
+// inbound message must short-circuit before the backend's delta stream ever
+// opens, and the single emitted chunk must be the fallback, not anything
+// backend-sourced.
+func TestProcessConversationStreamBlockedInputNeverReachesBackend(t *testing.T) {
+	blocked := safety.SafetyVerdict{Allowed: false, Category: safety.CategoryCrisis, Action: safety.ActionCrisisResource, Reason: "keyword match"}
+	backend := &fakeBackend{response: fakeChatResponse("should never stream")}
+	chain, _ := newTestChain(&stubModerator{verdict: blocked}, backend)
+
+	chunks, err := chain.ProcessConversationStream(context.Background(), testInput("concerning message"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collected := drainChunks(t, chunks)
+	if len(collected) != 1 {
+		t.Fatalf("expected exactly one chunk for a blocked input, got %d", len(collected))
+	}
+	if !collected[0].SafetyBlocked || !collected[0].Done {
+		t.Errorf("expected a single Done, SafetyBlocked chunk, got %+v", collected[0])
+	}
+	if backend.calls != 0 {
+		t.Fatalf("expected a blocked input to never open the backend's delta stream, got %d calls", backend.calls)
+	}
+}
+
+// TestProcessConversationStreamOutboundSafetyTripsMidStream verifies that
+// once the accumulated buffer trips the outbound check, streaming stops and
+// a single safety-blocked fallback chunk is emitted instead of any further
+// backend-sourced deltas.
+func TestProcessConversationStreamOutboundSafetyTripsMidStream(t *testing.T) {
+	backend := &fakeBackend{response: fakeChatResponse("a response that fails the outbound check")}
+	mod := &directionAwareModerator{
+		inbound:  safety.SafetyVerdict{Allowed: true, Action: safety.ActionAllow},
+		outbound: safety.SafetyVerdict{Allowed: false, Category: safety.CategorySelfHarm, Action: safety.ActionCrisisResource, Reason: "output flagged"},
+	}
+	chain, _ := newTestChain(mod, backend)
+
+	chunks, err := chain.ProcessConversationStream(context.Background(), testInput("a perfectly benign message"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collected := drainChunks(t, chunks)
+	if len(collected) != 1 {
+		t.Fatalf("expected streaming to stop after the first safety-blocked chunk, got %d chunks", len(collected))
+	}
+	if !collected[0].SafetyBlocked {
+		t.Error("expected the chunk to be marked SafetyBlocked")
+	}
+	if collected[0].Delta == "a response that fails the outbound check" {
+		t.Fatal("expected the fallback message, not the backend's unsafe delta")
+	}
+}
+
+// TestProcessConversationStreamAllowedEmitsDeltasThenDone covers the
+// non-blocked path: every delta the backend emits passes through, followed
+// by a terminal Done chunk.
+func TestProcessConversationStreamAllowedEmitsDeltasThenDone(t *testing.T) {
+	backend := &fakeBackend{response: fakeChatResponse("all good here")}
+	chain, _ := newTestChain(nil, backend)
+
+	chunks, err := chain.ProcessConversationStream(context.Background(), testInput("I had a good day today"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collected := drainChunks(t, chunks)
+	if len(collected) < 2 {
+		t.Fatalf("expected at least one content delta plus a terminal Done chunk, got %d", len(collected))
+	}
+	last := collected[len(collected)-1]
+	if !last.Done || last.SafetyBlocked {
+		t.Errorf("expected the final chunk to be Done and not SafetyBlocked, got %+v", last)
+	}
+
+	var assembled string
+	for _, c := range collected[:len(collected)-1] {
+		assembled += c.Delta
+	}
+	if assembled != "all good here" {
+		t.Errorf("expected the deltas to reassemble the backend's response, got %q", assembled)
+	}
+}
+
+// TestStreamConversationAdaptsChunksToTokens exercises StreamConversation's
+// ConversationChunk -> Token adaptation used by the WebSocket handler,
+// including that the tokens channel is closed once the Done chunk is seen.
+func TestStreamConversationAdaptsChunksToTokens(t *testing.T) {
+	backend := &fakeBackend{response: fakeChatResponse("hi there")}
+	chain, _ := newTestChain(nil, backend)
+
+	tokens := make(chan Token)
+	done := make(chan error, 1)
+	go func() {
+		done <- chain.StreamConversation(context.Background(), testInput("hello"), tokens)
+	}()
+
+	var collected []Token
+	for tok := range tokens {
+		collected = append(collected, tok)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(collected) == 0 {
+		t.Fatal("expected at least one token")
+	}
+	if !collected[len(collected)-1].Done {
+		t.Error("expected the last token to be marked Done")
+	}
+}